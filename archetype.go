@@ -7,14 +7,87 @@ import (
 func (world *World) createArchetype(componentsIds ...ComponentId) *archetype {
 	archetypeKey := archetypeId(len(world.archetypes))
 	archetype := archetype{
-		Id:   archetypeKey,
-		Type: componentsIds,
+		Id:        archetypeKey,
+		Type:      componentsIds,
+		signature: newSignature(componentsIds),
 	}
 	world.archetypes = append(world.archetypes, archetype)
+	world.indexArchetype(archetype)
+	world.linkSharedGroups(&world.archetypes[archetypeKey])
 
 	return &world.archetypes[archetypeKey]
 }
 
+// indexArchetype references the archetype in the inverted index of each of its components.
+func (world *World) indexArchetype(archetype archetype) {
+	for _, componentId := range archetype.Type {
+		world.archetypesByComponent[componentId] = append(world.archetypesByComponent[componentId], archetype.Id)
+	}
+}
+
+// rarestComponentArchetypes returns the archetypes of the component, among componentsIds,
+// contained in the fewest archetypes. Any archetype containing all componentsIds belongs to it.
+func (world *World) rarestComponentArchetypes(componentsIds []ComponentId) []archetypeId {
+	rarest := world.archetypesByComponent[componentsIds[0]]
+	for _, componentId := range componentsIds[1:] {
+		if archetypes := world.archetypesByComponent[componentId]; len(archetypes) < len(rarest) {
+			rarest = archetypes
+		}
+	}
+
+	return rarest
+}
+
+// archetypeContains reports whether the archetype contains all componentsIds.
+func archetypeContains(archetype *archetype, componentsIds []ComponentId) bool {
+	for _, componentId := range componentsIds {
+		if !archetype.signature.has(componentId) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// signature is the set of ComponentId and TagId of an archetype.
+//
+// The components, bounded by TAGS_INDICES, are stored in a bitset, so that
+// checking one of them is a single bit test whatever the size of the archetype.
+// The tags are unbounded and usually few: they are kept in a sorted slice.
+type signature struct {
+	components [TAGS_INDICES / 64]uint64
+	tags       []TagId
+}
+
+func newSignature(componentsIds []ComponentId) signature {
+	var signature signature
+	for _, componentId := range componentsIds {
+		if componentId < TAGS_INDICES {
+			signature.components[componentId/64] |= 1 << (componentId % 64)
+		} else if index, found := slices.BinarySearch(signature.tags, componentId); !found {
+			signature.tags = slices.Insert(signature.tags, index, componentId)
+		}
+	}
+
+	return signature
+}
+
+// has reports whether the signature contains the ComponentId or TagId.
+func (signature *signature) has(componentId ComponentId) bool {
+	if componentId < TAGS_INDICES {
+		return signature.components[componentId/64]&(1<<(componentId%64)) != 0
+	}
+
+	_, found := slices.BinarySearch(signature.tags, componentId)
+
+	return found
+}
+
+// equals reports whether both signatures contain exactly the same ids.
+func (signature *signature) equals(other *signature) bool {
+	return signature.components == other.components && slices.Equal(signature.tags, other.tags)
+}
+
 func (world *World) getArchetype(entityRecord entityRecord) *archetype {
 	archetypeId := entityRecord.archetypeId
 
@@ -26,30 +99,33 @@ func (world *World) getArchetype(entityRecord entityRecord) *archetype {
 }
 
 func (world *World) setArchetype(entityRecord entityRecord, archetype *archetype) {
+	world.guardArchetype(entityRecord.Id, archetype.Id)
+
+	// A negative key marks an entity not yet added to an archetype: it becomes live.
+	if entityRecord.key < 0 {
+		world.entitiesCount++
+	}
+
 	archetype.entities = append(archetype.entities, entityRecord.Id)
 
 	entityRecord.key = len(archetype.entities) - 1
 	entityRecord.archetypeId = archetype.Id
-	world.entities[entityRecord.Id] = entityRecord
+	world.entities.set(entityRecord)
+	world.insertRow(archetype)
 }
 
 func (world *World) getArchetypeForComponentsIds(componentsIds ...ComponentId) *archetype {
-	for i, archetype := range world.archetypes {
-		if len(archetype.Type) != len(componentsIds) {
-			continue
-		}
-
-		count := 0
-		for _, componentId := range componentsIds {
-			if slices.Contains(archetype.Type, componentId) {
-				count++
-			} else {
-				break
-			}
-		}
+	// The archetype without component is always the first one.
+	if len(componentsIds) == 0 {
+		return &world.archetypes[0]
+	}
 
-		if count == len(archetype.Type) {
-			return &world.archetypes[i]
+	// Only the archetypes of the rarest component are candidates, found through the inverted index.
+	signature := newSignature(componentsIds)
+	for _, archetypeId := range world.rarestComponentArchetypes(componentsIds) {
+		archetype := &world.archetypes[archetypeId]
+		if archetype.signature.equals(&signature) {
+			return archetype
 		}
 	}
 
@@ -57,6 +133,14 @@ func (world *World) getArchetypeForComponentsIds(componentsIds ...ComponentId) *
 }
 
 func (world *World) getNextArchetype(entityRecord entityRecord, componentsIds ...ComponentId) *archetype {
+	// Sparse components do not belong to the archetypes: the entity keeps its archetype for them.
+	if len(world.sparseComponentsIds) > 0 {
+		componentsIds = world.tableComponentsIds(componentsIds)
+		if len(componentsIds) == 0 {
+			return world.getArchetype(entityRecord)
+		}
+	}
+
 	// Fast path: a single-component transition (AddComponent, AddTag, ...) is
 	// resolved through the archetype graph, avoiding both the linear scan over
 	// all archetypes and the slice rebuild done below.
@@ -134,24 +218,66 @@ func (world *World) linkArchetypes(fromId, destId archetypeId, componentId Compo
 }
 
 // matchArchetypes appends, into buf, the id of every archetype whose Type
-// contains all of componentsIds (the query's required components + tags). The
-// caller passes a reused buffer (buf[:0]) to avoid per-call allocations.
+// contains all of componentsIds (the query's required components + tags), in
+// ascending order. Only the archetypes of the rarest component are tested,
+// through the inverted index. The caller passes a reused buffer (buf[:0]) to
+// avoid per-call allocations.
 func (world *World) matchArchetypes(buf []archetypeId, componentsIds []ComponentId) []archetypeId {
-	for i := range world.archetypes {
-		archetype := &world.archetypes[i]
-
-		matched := true
-		for _, componentId := range componentsIds {
-			if !slices.Contains(archetype.Type, componentId) {
-				matched = false
-				break
-			}
+	if len(componentsIds) == 0 {
+		return world.matchNewArchetypes(buf, componentsIds, 0)
+	}
+
+	for _, archetypeId := range world.rarestComponentArchetypes(componentsIds) {
+		if archetypeContains(&world.archetypes[archetypeId], componentsIds) {
+			buf = append(buf, archetypeId)
 		}
+	}
+
+	return buf
+}
 
-		if matched {
+// matchNewArchetypes appends, into buf, the id of every archetype created from
+// the id from whose Type contains all of componentsIds. Queries use it to update
+// their cache incrementally, testing only the archetypes created since.
+func (world *World) matchNewArchetypes(buf []archetypeId, componentsIds []ComponentId, from int) []archetypeId {
+	for i := from; i < len(world.archetypes); i++ {
+		if archetypeContains(&world.archetypes[i], componentsIds) {
 			buf = append(buf, archetypeId(i))
 		}
 	}
 
 	return buf
 }
+
+// isSparse reports whether componentId is stored in a sparse set, see SPARSE_STORAGE.
+func (world *World) isSparse(componentId ComponentId) bool {
+	return componentId < TAGS_INDICES && world.storage[componentId] != nil && world.storage[componentId].isSparse()
+}
+
+// tableComponentsIds returns componentsIds without the sparse components.
+// The slice is only copied if it contains a sparse component.
+func (world *World) tableComponentsIds(componentsIds []ComponentId) []ComponentId {
+	if !slices.ContainsFunc(componentsIds, world.isSparse) {
+		return componentsIds
+	}
+
+	tableIds := make([]ComponentId, 0, len(componentsIds))
+	for _, componentId := range componentsIds {
+		if !world.isSparse(componentId) {
+			tableIds = append(tableIds, componentId)
+		}
+	}
+
+	return tableIds
+}
+
+// hasSparseComponents reports whether entityId owns all the sparse componentsIds.
+func (world *World) hasSparseComponents(entityId EntityId, componentsIds []ComponentId) bool {
+	for _, componentId := range componentsIds {
+		if !world.storage[componentId].hasEntity(entityId) {
+			return false
+		}
+	}
+
+	return true
+}
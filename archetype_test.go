@@ -1,6 +1,10 @@
 package volt
 
-import "testing"
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
 
 // TestArchetypeGraph_ReallocationSafety drives the number of archetypes
 // well past the 1024 preallocated capacity, so world.archetypes reallocates
@@ -71,14 +75,14 @@ func TestArchetypeGraph_EdgesAreReused(t *testing.T) {
 	if err := AddComponent(world, e, testComponent2{}); err != nil {
 		t.Fatalf("%s", err.Error())
 	}
-	base := world.entities[e].archetypeId
+	base := world.entities.get(e).archetypeId
 	var withC1 archetypeId
 
 	for i := 0; i < 5; i++ {
 		if err := AddComponent(world, e, testComponent1{}); err != nil {
 			t.Fatalf("add iteration %d: %s", i, err.Error())
 		}
-		with := world.entities[e].archetypeId
+		with := world.entities.get(e).archetypeId
 		if i == 0 {
 			withC1 = with
 		} else if with != withC1 {
@@ -88,8 +92,187 @@ func TestArchetypeGraph_EdgesAreReused(t *testing.T) {
 		if err := RemoveComponent[testComponent1](world, e); err != nil {
 			t.Fatalf("remove iteration %d: %s", i, err.Error())
 		}
-		if back := world.entities[e].archetypeId; back != base {
+		if back := world.entities.get(e).archetypeId; back != base {
 			t.Fatalf("iteration %d: entity did not return to base archetype (%d != %d)", i, back, base)
 		}
 	}
 }
+
+// TestArchetypeIndex checks that the inverted index returns the same archetypes
+// as a full scan, and that a query cache updated incrementally matches a fresh one.
+func TestArchetypeIndex(t *testing.T) {
+	world := CreateWorld(64)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testComponent2](world, &ComponentConfig[testComponent2]{})
+	RegisterComponent[testComponent3](world, &ComponentConfig[testComponent3]{})
+
+	query := CreateQuery2[testComponent1, testComponent2](world, QueryConfiguration{Tags: []TagId{TAGS_INDICES}})
+	query.Count()
+
+	for i := range 64 {
+		entityId := world.CreateEntity()
+		if i&1 != 0 {
+			AddComponent(world, entityId, testComponent1{})
+		}
+		if i&2 != 0 {
+			AddComponent(world, entityId, testComponent2{})
+		}
+		if i&4 != 0 {
+			AddComponent(world, entityId, testComponent3{})
+		}
+		world.AddTag(TAGS_INDICES+TagId(i%8), entityId)
+
+		// The cache is updated after each new archetype.
+		fresh := CreateQuery2[testComponent1, testComponent2](world, QueryConfiguration{Tags: []TagId{TAGS_INDICES}})
+		if !slices.Equal(query.filter(), fresh.filter()) {
+			t.Fatalf("incremental cache %v differs from %v", query.filter(), fresh.filter())
+		}
+	}
+
+	for _, componentsIds := range [][]ComponentId{
+		{testComponent1Id},
+		{testComponent2Id, testComponent1Id},
+		{testComponent3Id, TAGS_INDICES + 5},
+		{TAGS_INDICES + 7, testComponent1Id, testComponent2Id, testComponent3Id},
+	} {
+		var expected []archetypeId
+		for _, archetype := range world.archetypes {
+			if archetypeContains(&archetype, componentsIds) {
+				expected = append(expected, archetype.Id)
+			}
+		}
+		if matched := world.matchArchetypes(nil, componentsIds); !slices.Equal(matched, expected) {
+			t.Errorf("matchArchetypes(%v) returned %v instead of %v", componentsIds, matched, expected)
+		}
+
+		exists := slices.ContainsFunc(expected, func(archetypeId archetypeId) bool {
+			return len(world.archetypes[archetypeId].Type) == len(componentsIds)
+		})
+		archetypesCount := len(world.archetypes)
+		archetype := world.getArchetypeForComponentsIds(componentsIds...)
+		if exists && len(world.archetypes) != archetypesCount {
+			t.Errorf("getArchetypeForComponentsIds(%v) created a duplicated archetype", componentsIds)
+		}
+		if len(archetype.Type) != len(componentsIds) || !archetypeContains(archetype, componentsIds) {
+			t.Errorf("getArchetypeForComponentsIds(%v) returned the archetype %v", componentsIds, archetype.Type)
+		}
+	}
+
+	if archetype := world.getArchetypeForComponentsIds(); archetype.Id != 0 || len(archetype.Type) != 0 {
+		t.Errorf("getArchetypeForComponentsIds() should return the empty archetype")
+	}
+}
+
+func TestSignature(t *testing.T) {
+	signature := newSignature([]ComponentId{testComponent1Id, 63, 64, TAGS_INDICES - 1, TAGS_INDICES + 10, TAGS_INDICES, TAGS_INDICES + 10})
+
+	for _, componentId := range []ComponentId{testComponent1Id, 63, 64, TAGS_INDICES - 1, TAGS_INDICES, TAGS_INDICES + 10} {
+		if !signature.has(componentId) {
+			t.Errorf("the signature should contain %d", componentId)
+		}
+	}
+	for _, componentId := range []ComponentId{testComponent2Id, 65, TAGS_INDICES + 1, AUTO_COMPONENT_ID} {
+		if signature.has(componentId) {
+			t.Errorf("the signature should not contain %d", componentId)
+		}
+	}
+
+	other := newSignature([]ComponentId{TAGS_INDICES, TAGS_INDICES + 10, TAGS_INDICES - 1, 64, 63, testComponent1Id})
+	if !signature.equals(&other) {
+		t.Errorf("the signatures should be equal whatever the order of the ids")
+	}
+	other = newSignature([]ComponentId{TAGS_INDICES, TAGS_INDICES - 1, 64, 63, testComponent1Id})
+	if signature.equals(&other) {
+		t.Errorf("the signatures should differ by a tag")
+	}
+}
+
+// benchmarkManyComponentsWorld returns a World with entitiesCount entities owning 64 components,
+// and a distinct tag each so that they are spread over as many archetypes.
+func benchmarkManyComponentsWorld(entitiesCount int) (*World, []EntityId) {
+	world := CreateWorld(entitiesCount)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testComponent2](world, &ComponentConfig[testComponent2]{})
+	RegisterComponent[testComponent3](world, &ComponentConfig[testComponent3]{})
+	RegisterComponent[testComponent4](world, &ComponentConfig[testComponent4]{})
+	RegisterComponent[testComponent5](world, &ComponentConfig[testComponent5]{})
+	RegisterComponent[testComponent6](world, &ComponentConfig[testComponent6]{})
+	RegisterComponent[testComponent7](world, &ComponentConfig[testComponent7]{})
+	RegisterComponent[testComponent8](world, &ComponentConfig[testComponent8]{})
+
+	dynamicIds := make([]ComponentId, 56)
+	for i := range dynamicIds {
+		dynamicIds[i], _ = RegisterDynamicComponent(world, strconv.Itoa(i), DynamicLayout{Size: 1})
+	}
+
+	entities := make([]EntityId, entitiesCount)
+	for i := range entities {
+		entities[i], _ = CreateEntityWithComponents8(world, testComponent1{}, testComponent2{}, testComponent3{}, testComponent4{}, testComponent5{}, testComponent6{}, testComponent7{}, testComponent8{})
+		for _, componentId := range dynamicIds {
+			world.AddDynamicComponent(entities[i], componentId, nil)
+		}
+		world.AddTag(TAGS_INDICES+TagId(i), entities[i])
+	}
+
+	return world, entities
+}
+
+// BenchmarkHasComponents compares the signature lookup with a linear search
+// through the archetype Type, on an entity with many components.
+func BenchmarkHasComponents(b *testing.B) {
+	world, entities := benchmarkManyComponentsWorld(1)
+	entityId := entities[0]
+	archetype := &world.archetypes[world.entities.get(entityId).archetypeId]
+	componentsIds := []ComponentId{testComponent1Id, archetype.Type[40], archetype.Type[63], TAGS_INDICES}
+
+	b.Run("signature", func(b *testing.B) {
+		for b.Loop() {
+			archetype := &world.archetypes[world.entities.get(entityId).archetypeId]
+			for _, componentId := range componentsIds {
+				if !archetype.signature.has(componentId) {
+					b.Fatal("the entity should own the components")
+				}
+			}
+		}
+	})
+
+	b.Run("slice", func(b *testing.B) {
+		for b.Loop() {
+			archetype := &world.archetypes[world.entities.get(entityId).archetypeId]
+			for _, componentId := range componentsIds {
+				if !slices.Contains(archetype.Type, componentId) {
+					b.Fatal("the entity should own the components")
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkMatchArchetypes compares the query matching through the index and the signatures
+// with a linear scan of the archetypes, over many archetypes with many components.
+func BenchmarkMatchArchetypes(b *testing.B) {
+	world, entities := benchmarkManyComponentsWorld(512)
+	archetype := &world.archetypes[world.entities.get(entities[0]).archetypeId]
+	componentsIds := []ComponentId{testComponent1Id, archetype.Type[40], archetype.Type[63]}
+
+	var buf []archetypeId
+	b.Run("signature", func(b *testing.B) {
+		for b.Loop() {
+			buf = world.matchArchetypes(buf[:0], componentsIds)
+		}
+	})
+
+	b.Run("slice", func(b *testing.B) {
+		for b.Loop() {
+			buf = buf[:0]
+			for i := range world.archetypes {
+				archetype := &world.archetypes[i]
+				if !slices.ContainsFunc(componentsIds, func(componentId ComponentId) bool {
+					return !slices.Contains(archetype.Type, componentId)
+				}) {
+					buf = append(buf, archetype.Id)
+				}
+			}
+		}
+	})
+}
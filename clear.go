@@ -0,0 +1,81 @@
+package volt
+
+import "slices"
+
+// Clear removes all the entities of the World, e.g. between two matches, without reallocating it.
+//
+// The registry of the components, the archetypes and the capacity of their columns are kept for reuse,
+// as well as the queries and the ranges reserved with ReserveEntityRange, whose ids are all freed.
+// The archetypes holding a group of a shared component are removed instead, so that the ids
+// of the groups are freed, see SetSharedComponent: the snapshots taken beforehand cannot be restored then.
+// The EntityId are handed out again from 0, as in a new World, so that the pages of the entities
+// and of the sparse components are reused: the EntityId held beforehand must be discarded.
+//
+// If publish is true, the callback setted in SetEntityRemovedFn is called for each entity beforehand,
+// in ascending order: it still has access to the data, but must not modify the World.
+// As with RemoveEntity, the callback of SetComponentRemovedFn is not called: the components are removed with their entity.
+// Clear must not be called while a query is iterated.
+func (world *World) Clear(publish bool) {
+	world.guardWorldChange()
+
+	if publish {
+		for entityId := range world.Entities() {
+			world.entityRemovedFn(entityId)
+		}
+	}
+
+	world.entities.reset()
+	world.entitiesCount = 0
+
+	world.pool.ids = world.pool.ids[:0]
+	world.pool.next = 0
+	for _, entityRange := range world.pool.ranges {
+		entityRange.ids = entityRange.ids[:0]
+		entityRange.used = 0
+	}
+
+	for i := range world.archetypes {
+		world.archetypes[i].entities = world.archetypes[i].entities[:0]
+	}
+
+	for _, s := range world.storage {
+		if s != nil {
+			s.reset()
+		}
+	}
+	if len(world.sharedGroups) > 0 {
+		world.removeArchetypes(func(archetype *archetype) bool {
+			return slices.ContainsFunc(archetype.Type, func(componentId ComponentId) bool {
+				return componentId >= SHARED_INDICES
+			})
+		}, nil)
+	}
+	world.rebuildIndexes()
+}
+
+// resetColumns empties the columns, keeping their capacity.
+// The components are zeroed, so that the memory they refer to can be released.
+func resetColumns[T any](columns [][]T) {
+	for i, column := range columns {
+		clear(column)
+		// column[:0] keeps a nil column nil, see ArchetypesComponentsEntities.
+		columns[i] = column[:0]
+	}
+}
+
+func (c *ComponentsStorage[T]) reset() {
+	resetColumns(c.archetypesComponentsEntities)
+
+	if c.sparse != nil {
+		clear(c.sparse.dense)
+		for _, page := range c.sparse.indices {
+			clear(page)
+		}
+		c.sparse.dense = c.sparse.dense[:0]
+		c.sparse.entities = c.sparse.entities[:0]
+	}
+}
+
+func (c *dynamicStorage) reset() {
+	resetColumns(c.columns)
+}
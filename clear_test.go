@@ -0,0 +1,99 @@
+package volt
+
+import (
+	"testing"
+)
+
+func TestWorld_Clear(t *testing.T) {
+	world := CreateWorld(64)
+	statsId := registerTestComponents(t, world)
+	world.ReserveEntityRange(1000, 1010)
+	for frame := range 20 {
+		simulate(world, statsId, frame)
+	}
+	query := CreateQuery1[testComponent1](world, QueryConfiguration{})
+	archetypesCount := len(world.archetypes)
+	count := world.Count()
+
+	removed := 0
+	world.SetEntityRemovedFn(func(entityId EntityId) {
+		if GetComponent[testComponent1](world, entityId) == nil {
+			t.Errorf("the entity %d should still have its components in the callback", entityId)
+		}
+		removed++
+	})
+	world.Clear(true)
+
+	if removed != count {
+		t.Errorf("the callback should be called for the %d entities, got %d", count, removed)
+	}
+	if world.Count() != 0 || world.Exists(0) || query.Count() != 0 {
+		t.Errorf("the world should be empty, got %d entities", world.Count())
+	}
+	if GetComponent[testComponent1](world, 0) != nil || GetComponent[testSparseComponent](world, 0) != nil {
+		t.Errorf("the components of the entities removed should not be accessible")
+	}
+	// The EntityId are handed out again from 0.
+	if entityId := world.CreateEntity(); entityId != 0 {
+		t.Errorf("the cleared world should hand out the id 0, got %d", entityId)
+	}
+	world.Clear(false)
+	if len(world.archetypes) != archetypesCount {
+		t.Errorf("the archetypes should be kept, expected %d got %d", archetypesCount, len(world.archetypes))
+	}
+	if storage := getStorage[testComponent1](world); cap(storage.archetypesComponentsEntities[1]) == 0 {
+		t.Errorf("the columns should keep their capacity")
+	}
+
+	// The cleared world behaves as a new one.
+	world.SetEntityRemovedFn(func(entityId EntityId) {})
+	fresh := CreateWorld(64)
+	registerTestComponents(t, fresh)
+	fresh.ReserveEntityRange(1000, 1010)
+	for _, w := range []*World{world, fresh} {
+		w.CreateEntityWithId(1005)
+		for frame := range 20 {
+			simulate(w, statsId, frame)
+		}
+	}
+	if got, expected := fingerprint(world, statsId), fingerprint(fresh, statsId); got != expected {
+		t.Errorf("the cleared world should behave as a new one:\n%s\nexpected:\n%s", got, expected)
+	}
+	freshQuery := CreateQuery1[testComponent1](fresh, QueryConfiguration{})
+	if expected := freshQuery.Count(); query.Count() != expected {
+		t.Errorf("the query should still be usable, expected %d got %d", expected, query.Count())
+	}
+
+	world.Clear(false)
+	allocations := testing.AllocsPerRun(100, func() {
+		world.Clear(false)
+	})
+	if allocations != 0 {
+		t.Errorf("Clear should not allocate, got %v allocations", allocations)
+	}
+}
+
+func TestWorld_Clear_Capacity(t *testing.T) {
+	world := CreateWorld(16)
+	registerTestComponents(t, world)
+	sparseId := ComponentIdOf[testSparseComponent](world)
+	sparse := world.storage[sparseId].(*ComponentsStorage[testSparseComponent]).sparse
+
+	// Each match fills the world with the same number of entities: the memory is allocated by the first one only.
+	var pages, indices, dense int
+	for match := range 5 {
+		for i := range 10000 {
+			entityId := world.CreateEntity()
+			AddComponent(world, entityId, testComponent1{testComponent{x: i}})
+			AddComponent(world, entityId, testSparseComponent{testComponent{x: i}})
+		}
+		world.Clear(false)
+
+		if match == 0 {
+			pages, indices, dense = len(world.entities.pages), len(sparse.indices), cap(sparse.dense)
+		} else if len(world.entities.pages) != pages || len(sparse.indices) != indices || cap(sparse.dense) != dense {
+			t.Errorf("the match %d should reuse the memory, got %d pages of entities, %d pages of indices and %d components, expected %d, %d and %d",
+				match, len(world.entities.pages), len(sparse.indices), cap(sparse.dense), pages, indices, dense)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package volt
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns an independent copy of the World: its registry, entities, free EntityId,
+// archetypes (including the archetype graph) and the components of every storage.
+//
+// The components are copied by value: those holding pointers, maps or slices share their data
+// with the original, unless their ComponentConfig sets a CloneFn to copy it deeply.
+// The callbacks and the snapshots are not copied, and the queries and the indexes must be created again on the clone.
+// The ranges reserved stay reserved in the clone, where their ids can be created with CreateEntityWithId.
+func (world *World) Clone() *World {
+	clone := &World{
+		componentsRegistry:    slices.Clone(world.componentsRegistry),
+		registeredTypes:       maps.Clone(world.registeredTypes),
+		componentsByName:      maps.Clone(world.componentsByName),
+		entities:              world.entities.clone(),
+		entitiesCount:         world.entitiesCount,
+		archetypes:            make([]archetype, len(world.archetypes), cap(world.archetypes)),
+		archetypesByComponent: make(map[ComponentId][]archetypeId, len(world.archetypesByComponent)),
+		archetypesGeneration:  world.archetypesGeneration,
+		storage:               make([]storage, len(world.storage)),
+		sparseComponentsIds:   slices.Clone(world.sparseComponentsIds),
+		sharedGroups:          slices.Clone(world.sharedGroups),
+		freeSharedGroups:      slices.Clone(world.freeSharedGroups),
+		deterministic:         world.deterministic,
+		entityAddedFn:         func(entityId EntityId) {},
+		entityRemovedFn:       func(entityId EntityId) {},
+		componentAddedFn:      func(entityId EntityId, componentId ComponentId) {},
+		componentRemovedFn:    func(entityId EntityId, componentId ComponentId) {},
+	}
+
+	clone.pool = pool{
+		ids:  slices.Clone(world.pool.ids),
+		next: world.pool.next,
+	}
+	for _, entityRange := range world.pool.ranges {
+		clone.pool.ranges = append(clone.pool.ranges, &EntityRange{
+			First: entityRange.First,
+			Last:  entityRange.Last,
+			ids:   slices.Clone(entityRange.ids),
+			used:  entityRange.used,
+		})
+	}
+
+	for i, archetype := range world.archetypes {
+		archetype.Type = slices.Clone(archetype.Type)
+		archetype.signature.tags = slices.Clone(archetype.signature.tags)
+		archetype.entities = slices.Clone(archetype.entities)
+		archetype.addEdges = maps.Clone(archetype.addEdges)
+		archetype.removeEdges = maps.Clone(archetype.removeEdges)
+		clone.archetypes[i] = archetype
+	}
+	for componentId, archetypesIds := range world.archetypesByComponent {
+		clone.archetypesByComponent[componentId] = slices.Clone(archetypesIds)
+	}
+
+	for componentId, s := range world.storage {
+		if s != nil {
+			clone.storage[componentId] = s.clone(world.componentsRegistry[componentId])
+		}
+	}
+
+	return clone
+}
+
+// clone returns a copy of the storage, whose components are copied with the CloneFn of config if set.
+func (c *ComponentsStorage[T]) clone(config ComponentConfigInterface) storage {
+	var cloneFn func(component T) T
+	if config, ok := config.(*ComponentConfig[T]); ok {
+		cloneFn = config.CloneFn
+	}
+
+	clone := &ComponentsStorage[T]{
+		componentId:                  c.componentId,
+		archetypesComponentsEntities: make(ArchetypesComponentsEntities[T], len(c.archetypesComponentsEntities)),
+	}
+	for i, column := range c.archetypesComponentsEntities {
+		clone.archetypesComponentsEntities[i] = cloneColumn(column, cloneFn)
+	}
+
+	if c.sparse != nil {
+		clone.sparse = &sparseSet[T]{
+			indices:  copyIndices(nil, c.sparse.indices),
+			dense:    cloneColumn(c.sparse.dense, cloneFn),
+			entities: slices.Clone(c.sparse.entities),
+		}
+	}
+	if c.shared != nil {
+		clone.shared = c.shared.clone(cloneFn)
+	}
+
+	return clone
+}
+
+// cloneColumn returns a copy of column, keeping a nil column nil, and an empty one non-nil.
+func cloneColumn[T any](column []T, cloneFn func(component T) T) []T {
+	if column == nil {
+		return nil
+	}
+
+	clone := make([]T, len(column), cap(column))
+	if cloneFn == nil {
+		copy(clone, column)
+		return clone
+	}
+
+	for i, component := range column {
+		clone[i] = cloneFn(component)
+	}
+
+	return clone
+}
+
+func (c *dynamicStorage) clone(config ComponentConfigInterface) storage {
+	clone := &dynamicStorage{
+		componentId: c.componentId,
+		stride:      c.stride,
+		columns:     make([][]byte, len(c.columns)),
+	}
+	for i, column := range c.columns {
+		clone.columns[i] = cloneColumn(column, nil)
+	}
+
+	return clone
+}
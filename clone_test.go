@@ -0,0 +1,74 @@
+package volt
+
+import (
+	"slices"
+	"testing"
+)
+
+type testInventoryComponent struct {
+	items []string
+}
+
+func (t testInventoryComponent) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+func TestWorld_Clone(t *testing.T) {
+	world := CreateWorld(64)
+	statsId := registerTestComponents(t, world)
+	RegisterComponent[testInventoryComponent](world, &ComponentConfig[testInventoryComponent]{
+		CloneFn: func(component testInventoryComponent) testInventoryComponent {
+			component.items = slices.Clone(component.items)
+			return component
+		},
+	})
+	for frame := range 20 {
+		simulate(world, statsId, frame)
+	}
+
+	entityId := world.CreateEntity()
+	AddComponent(world, entityId, testComponent1{testComponent{x: 1}})
+	AddComponent(world, entityId, testSparseComponent{testComponent{x: 2}})
+	AddComponent(world, entityId, testInventoryComponent{items: []string{"sword"}})
+	world.AddDynamicComponent(entityId, statsId, nil)
+	world.AddTag(TAG_1, entityId)
+	world.ReserveEntityRange(1000, 1010)
+
+	expected := fingerprint(world, statsId)
+	clone := world.Clone()
+	if got := fingerprint(clone, statsId); got != expected {
+		t.Fatalf("the clone differs from the world:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	// Mutate the clone in every way.
+	GetComponent[testComponent1](clone, entityId).x = 10
+	GetComponent[testSparseComponent](clone, entityId).x = 20
+	GetComponent[testInventoryComponent](clone, entityId).items[0] = "shield"
+	SetDynamicField[float32](clone, entityId, statsId, "health", 30)
+	clone.RemoveTag(TAG_1, entityId)
+	RemoveComponent[testComponent2](clone, entityId)
+	AddComponent(clone, entityId, testComponent2{})
+	clone.CreateEntityWithId(1005)
+	for frame := 20; frame < 40; frame++ {
+		simulate(clone, statsId, frame)
+	}
+
+	if got := fingerprint(world, statsId); got != expected {
+		t.Errorf("the mutations of the clone leaked into the world:\n%s\nexpected:\n%s", got, expected)
+	}
+	if items := GetComponent[testInventoryComponent](world, entityId).items; items[0] != "sword" {
+		t.Errorf("the slice of the component should be copied by CloneFn, got %v", items)
+	}
+	if clone.Exists(1005) == world.Exists(1005) {
+		t.Errorf("the entity created in the clone should not exist in the world")
+	}
+
+	// And the other way around.
+	cloneFingerprint := fingerprint(clone, statsId)
+	for frame := 20; frame < 40; frame++ {
+		simulate(world, statsId, frame)
+	}
+	if got := fingerprint(clone, statsId); got != cloneFingerprint {
+		t.Errorf("the mutations of the world leaked into the clone")
+	}
+}
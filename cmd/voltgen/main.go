@@ -0,0 +1,160 @@
+// Command voltgen generates the N-ary API families of volt.
+//
+// Queries, the batched AddComponents, CreateEntityWithComponents and their
+// internal helpers exist for 1 to 8 type parameters. They share the exact same
+// body, so they are written once as templates and unrolled by this tool, which
+// keeps every arity in sync.
+//
+// It is run from the root of the module through go generate:
+//
+//	go generate ./...
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// MAX_ARITY is the highest number of type parameters generated.
+const MAX_ARITY = 8
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+// outputs maps each template to the file it generates, relative to the module root.
+var outputs = map[string]string{
+	"query.go.tmpl":     "query_gen.go",
+	"component.go.tmpl": "component_gen.go",
+	"world.go.tmpl":     "world_gen.go",
+}
+
+// arity describes one member of an N-ary family, e.g. Query3[A, B, C].
+type arity struct {
+	N     int
+	Types []string
+}
+
+func newArity(n int) arity {
+	types := make([]string, n)
+	for i := range n {
+		types[i] = string(rune('A' + i))
+	}
+
+	return arity{N: n, Types: types}
+}
+
+// TypeParams returns the type arguments, e.g. "A, B, C".
+func (a arity) TypeParams() string {
+	return strings.Join(a.Types, ", ")
+}
+
+// Constraint returns the type parameters declaration, e.g. "A, B, C ComponentInterface".
+func (a arity) Constraint() string {
+	return a.TypeParams() + " ComponentInterface"
+}
+
+// Vars returns the lowercase value names, e.g. ["a", "b", "c"].
+func (a arity) Vars() []string {
+	vars := make([]string, len(a.Types))
+	for i, t := range a.Types {
+		vars[i] = strings.ToLower(t)
+	}
+
+	return vars
+}
+
+// Params returns the value parameters, e.g. "a A, b B, c C".
+func (a arity) Params() string {
+	return a.join(func(t string) string { return strings.ToLower(t) + " " + t })
+}
+
+// Args returns the value arguments, e.g. "a, b, c".
+func (a arity) Args() string {
+	return strings.Join(a.Vars(), ", ")
+}
+
+// Ids returns the component identifiers variables, e.g. "componentIdA, componentIdB".
+func (a arity) Ids() string {
+	return a.join(func(t string) string { return "componentId" + t })
+}
+
+// ComponentParams returns the prefixed value parameters, e.g. "componentA A, componentB B".
+func (a arity) ComponentParams() string {
+	return a.join(func(t string) string { return "component" + t + " " + t })
+}
+
+// Plural returns "component" or "components" depending on N.
+func (a arity) Plural() string {
+	if a.N == 1 {
+		return "component"
+	}
+
+	return "components"
+}
+
+func (a arity) join(fn func(t string) string) string {
+	parts := make([]string, len(a.Types))
+	for i, t := range a.Types {
+		parts[i] = fn(t)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// templateData is passed to every template.
+type templateData struct {
+	Arities []arity
+}
+
+func newTemplateData() templateData {
+	data := templateData{}
+	for n := 1; n <= MAX_ARITY; n++ {
+		data.Arities = append(data.Arities, newArity(n))
+	}
+
+	return data
+}
+
+// generate renders the template name and returns the gofmt-ed source.
+func generate(name string) ([]byte, error) {
+	tmpl, err := template.New(name).ParseFS(templatesFS, "templates/"+name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, newTemplateData()); err != nil {
+		return nil, fmt.Errorf("cannot execute template %s: %w", name, err)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("cannot format the output of %s: %w", name, err)
+	}
+
+	return source, nil
+}
+
+func main() {
+	dir := flag.String("dir", ".", "root directory of the volt module")
+	flag.Parse()
+
+	for name, output := range outputs {
+		source, err := generate(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err = os.WriteFile(filepath.Join(*dir, output), source, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
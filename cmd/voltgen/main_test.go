@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGeneratedFilesAreUpToDate(t *testing.T) {
+	for name, output := range outputs {
+		expected, err := generate(name)
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		actual, err := os.ReadFile(filepath.Join("..", "..", output))
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("%s is out of date with %s, run go generate ./...", output, name)
+		}
+	}
+}
+
+func TestNewArity(t *testing.T) {
+	a := newArity(3)
+
+	if a.TypeParams() != "A, B, C" {
+		t.Errorf("unexpected type parameters %q", a.TypeParams())
+	}
+	if a.Params() != "a A, b B, c C" {
+		t.Errorf("unexpected parameters %q", a.Params())
+	}
+	if a.ComponentParams() != "componentA A, componentB B, componentC C" {
+		t.Errorf("unexpected component parameters %q", a.ComponentParams())
+	}
+	if newArity(1).Plural() != "component" || a.Plural() != "components" {
+		t.Errorf("unexpected plural")
+	}
+}
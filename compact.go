@@ -0,0 +1,175 @@
+package volt
+
+import (
+	"unsafe"
+)
+
+// CompactStats reports the memory reclaimed by World.Compact.
+type CompactStats struct {
+	// ArchetypesRemoved is the number of empty archetypes removed.
+	ArchetypesRemoved int
+	// ColumnsShrunk is the number of columns reallocated to fit their content.
+	ColumnsShrunk int
+	// BytesReclaimed is the memory released by the removed archetypes and the shrunk columns.
+	BytesReclaimed uintptr
+}
+
+// Compact removes the archetypes without entity, and shrinks the columns using less than half their capacity.
+//
+// The remaining archetypes are renumbered, and the archetype graph and the queries caches are updated accordingly.
+// The groups of the shared components held by no remaining archetype are freed, see SetSharedComponent.
+// Compact is meant to be called at a quiet moment (e.g. loading a new level), as it walks all the storage:
+// it must not be called while a query is iterated.
+func (world *World) Compact() CompactStats {
+	world.guardWorldChange()
+
+	var stats CompactStats
+	world.removeArchetypes(func(archetype *archetype) bool {
+		return len(archetype.entities) == 0
+	}, &stats)
+
+	return stats
+}
+
+// removeArchetypes removes the archetypes matching removeFn, renumbers the others and frees the groups
+// of the shared components held by no remaining archetype.
+// The columns are shrunk and the memory reclaimed is reported in stats, unless stats is nil (see Clear).
+func (world *World) removeArchetypes(removeFn func(archetype *archetype) bool, stats *CompactStats) {
+	// remap[old] is the new id of the archetype old, or -1 if it is removed.
+	// The archetype 0 (no component) is always kept, as every new entity starts there.
+	remap := make([]int, len(world.archetypes))
+	kept := 0
+	for i := range world.archetypes {
+		if i != 0 && removeFn(&world.archetypes[i]) {
+			remap[i] = -1
+			if stats != nil {
+				stats.ArchetypesRemoved++
+				stats.BytesReclaimed += uintptr(cap(world.archetypes[i].entities)) * unsafe.Sizeof(EntityId(0))
+			}
+			continue
+		}
+
+		remap[i] = kept
+		kept++
+	}
+	if kept == len(world.archetypes) && stats == nil {
+		return
+	}
+
+	for _, s := range world.storage {
+		if s != nil {
+			s.compact(remap, stats)
+		}
+	}
+
+	// Kept archetypes only move toward lower ids, so they are compacted in place.
+	for i := range world.archetypes {
+		if remap[i] < 0 {
+			continue
+		}
+
+		archetype := world.archetypes[i]
+		archetype.Id = archetypeId(remap[i])
+		archetype.entities = shrinkColumn(archetype.entities, stats)
+		archetype.addEdges = remapEdges(archetype.addEdges, remap)
+		archetype.removeEdges = remapEdges(archetype.removeEdges, remap)
+		world.archetypes[remap[i]] = archetype
+	}
+	clear(world.archetypes[kept:])
+	world.archetypes = world.archetypes[:kept]
+
+	clear(world.archetypesByComponent)
+	for _, archetype := range world.archetypes {
+		world.indexArchetype(archetype)
+	}
+
+	for entityRecord := range world.entities.all() {
+		if remap[entityRecord.archetypeId] < 0 {
+			// Only the removed entities can still refer to an empty archetype.
+			entityRecord.archetypeId = 0
+		} else {
+			entityRecord.archetypeId = archetypeId(remap[entityRecord.archetypeId])
+		}
+	}
+
+	if kept < len(remap) {
+		world.archetypesGeneration++
+		world.releaseSharedGroups()
+	}
+}
+
+// remapEdges returns the archetype graph edges toward the archetypes kept by Compact, with their new ids.
+func remapEdges(edges map[ComponentId]archetypeId, remap []int) map[ComponentId]archetypeId {
+	for componentId, destId := range edges {
+		if remap[destId] < 0 {
+			delete(edges, componentId)
+		} else {
+			edges[componentId] = archetypeId(remap[destId])
+		}
+	}
+
+	return edges
+}
+
+// shrinkColumn reallocates column to its length if it uses less than half its capacity.
+// The column is kept as is if stats is nil.
+func shrinkColumn[T any](column []T, stats *CompactStats) []T {
+	if stats == nil || cap(column) <= 2*len(column) {
+		return column
+	}
+
+	var t T
+	stats.ColumnsShrunk++
+	stats.BytesReclaimed += uintptr(cap(column)-len(column)) * unsafe.Sizeof(t)
+
+	// make keeps an empty column non-nil, see ArchetypesComponentsEntities.
+	shrunk := make([]T, len(column))
+	copy(shrunk, column)
+
+	return shrunk
+}
+
+// compactColumns moves the columns of the archetypes kept by Compact to their new ids, and shrinks them.
+func compactColumns[T any](columns [][]T, remap []int, stats *CompactStats) [][]T {
+	var t T
+	length := 0
+	for old, column := range columns {
+		if remap[old] < 0 {
+			if stats != nil {
+				stats.BytesReclaimed += uintptr(cap(column)) * unsafe.Sizeof(t)
+			}
+			continue
+		}
+
+		columns[remap[old]] = column
+		if column != nil {
+			columns[remap[old]] = shrinkColumn(column, stats)
+		}
+		length = remap[old] + 1
+	}
+	clear(columns[length:])
+
+	return columns[:length]
+}
+
+func (c *ComponentsStorage[T]) compact(remap []int, stats *CompactStats) {
+	c.archetypesComponentsEntities = compactColumns(c.archetypesComponentsEntities, remap, stats)
+
+	if c.sparse != nil {
+		c.sparse.dense = shrinkColumn(c.sparse.dense, stats)
+		c.sparse.entities = shrinkColumn(c.sparse.entities, stats)
+	}
+	if c.shared != nil {
+		groups := c.shared.groups
+		c.shared.groups = nil
+		for old, value := range groups {
+			if remap[old] >= 0 && value != 0 {
+				c.shared.link(archetypeId(remap[old]), value-1)
+			}
+		}
+	}
+}
+
+func (c *dynamicStorage) compact(remap []int, stats *CompactStats) {
+	c.columns = compactColumns(c.columns, remap, stats)
+}
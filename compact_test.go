@@ -0,0 +1,108 @@
+package volt
+
+import (
+	"testing"
+)
+
+func TestWorld_Compact(t *testing.T) {
+	world := CreateWorld(1024)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testComponent2](world, &ComponentConfig[testComponent2]{})
+	RegisterComponent[testComponent3](world, &ComponentConfig[testComponent3]{})
+	RegisterComponent[testSparseComponent](world, &ComponentConfig[testSparseComponent]{Storage: SPARSE_STORAGE})
+	statsId, _ := RegisterDynamicComponent(world, "stats", testDynamicLayout)
+
+	query := CreateQuery2[testComponent1, testComponent2](world, QueryConfiguration{OptionalComponents: []OptionalComponent{testComponent2Id}})
+
+	// Fill archetypes {1,3}, {1,2,3} and {1,stats}, then empty them.
+	var removed []EntityId
+	for i := range 100 {
+		entityId := world.CreateEntity()
+		AddComponent(world, entityId, testComponent1{testComponent{x: i}})
+		AddComponent(world, entityId, testComponent3{})
+		if i%2 == 0 {
+			AddComponent(world, entityId, testComponent2{testComponent{x: i}})
+		}
+		removed = append(removed, entityId)
+	}
+	entityId := world.CreateEntity()
+	AddComponent(world, entityId, testComponent1{})
+	world.AddDynamicComponent(entityId, statsId, nil)
+	removed = append(removed, entityId)
+	for _, entityId := range removed {
+		world.RemoveEntity(entityId)
+	}
+
+	// Archetypes {1} and {1,2} stay populated.
+	var entities []EntityId
+	for i := range 1000 {
+		entityId := world.CreateEntity()
+		AddComponent(world, entityId, testComponent1{testComponent{x: i}})
+		if i%2 == 0 {
+			AddComponent(world, entityId, testComponent2{testComponent{x: i}})
+		}
+		AddComponent(world, entityId, testSparseComponent{testComponent{x: i}})
+		entities = append(entities, entityId)
+	}
+	for _, entityId := range entities[100:] {
+		RemoveComponent[testSparseComponent](world, entityId)
+	}
+	for _, entityId := range entities[10:] {
+		world.RemoveEntity(entityId)
+	}
+	entities = entities[:10]
+
+	if query.Count() != 10 {
+		t.Fatalf("query should return 10 entities before Compact, got %d", query.Count())
+	}
+	archetypesCount := len(world.archetypes)
+
+	stats := world.Compact()
+	if stats.ArchetypesRemoved == 0 || stats.ColumnsShrunk == 0 || stats.BytesReclaimed == 0 {
+		t.Errorf("Compact did not reclaim memory: %+v", stats)
+	}
+	if len(world.archetypes) != archetypesCount-stats.ArchetypesRemoved || len(world.archetypes) != 3 {
+		t.Errorf("expected 3 archetypes after Compact, got %d", len(world.archetypes))
+	}
+	for i, archetype := range world.archetypes {
+		if archetype.Id != archetypeId(i) {
+			t.Errorf("the archetype %d has the id %d", i, archetype.Id)
+		}
+	}
+
+	if query.Count() != 10 {
+		t.Errorf("query should return 10 entities after Compact, got %d", query.Count())
+	}
+	for result := range query.Foreach(nil) {
+		if (result.A.x%2 == 0) != (result.B != nil) || (result.B != nil && result.A.x != result.B.x) {
+			t.Errorf("unexpected result %v after Compact", result)
+		}
+	}
+	for i, entityId := range entities {
+		if component := GetComponent[testComponent1](world, entityId); component == nil || component.x != i {
+			t.Errorf("the entity %d has lost its component", entityId)
+		}
+		if component := GetComponent[testSparseComponent](world, entityId); component == nil || component.x != i {
+			t.Errorf("the entity %d has lost its sparse component", entityId)
+		}
+	}
+
+	// The archetypes removed are created again, with the graph still consistent.
+	entityId = world.CreateEntity()
+	AddComponent(world, entityId, testComponent1{testComponent{x: 42}})
+	AddComponent(world, entityId, testComponent3{})
+	world.AddDynamicComponent(entityId, statsId, nil)
+	if !world.HasComponents(entityId, testComponent1Id, testComponent3Id, statsId) || GetComponent[testComponent1](world, entityId).x != 42 {
+		t.Errorf("the entity %d has not the expected components after Compact", entityId)
+	}
+	if err := RemoveComponent[testComponent2](world, entities[0]); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if query.Count() != 11 {
+		t.Errorf("query should return 11 entities, got %d", query.Count())
+	}
+
+	if stats = world.Compact(); stats.ArchetypesRemoved != 1 {
+		t.Errorf("Compact should remove the empty archetype {1,3}, got %+v", stats)
+	}
+}
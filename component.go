@@ -2,7 +2,6 @@ package volt
 
 import (
 	"fmt"
-	"slices"
 )
 
 // ComponentInterface is the interface for all the Components.
@@ -17,22 +16,12 @@ type ComponentIdConf struct {
 	conf any
 }
 
-func (world *World) getComponentsIds(components ...ComponentInterface) []ComponentId {
-	componentsIds := make([]ComponentId, len(components))
-
-	for i, component := range components {
-		componentsIds[i] = component.GetComponentId()
-	}
-
-	return componentsIds
-}
-
 // ConfigureComponent configures a Component of type T using the build function related to it.
 //
 // The parameter conf contains all the data required for the configuration.
 func ConfigureComponent[T ComponentInterface](world *World, conf any) T {
 	var t T
-	componentRegistry := world.componentsRegistry[t.GetComponentId()]
+	componentRegistry := world.componentsRegistry[ComponentIdOf[T](world)]
 
 	componentRegistry.builderFn(&t, conf)
 
@@ -44,304 +33,33 @@ func ConfigureComponent[T ComponentInterface](world *World, conf any) T {
 // It returns an error if:
 //   - the entity does not exist
 //   - the entity has the component
+//   - the component is shared, it is set with SetSharedComponent
 //   - an internal error occurs
 func AddComponent[T ComponentInterface](world *World, entityId EntityId, component T) error {
 	if !world.Exists(entityId) {
 		return fmt.Errorf("entity %v does not exist", entityId)
 	}
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 
-	componentId := component.GetComponentId()
+	componentId := ComponentIdOf[T](world)
 	if world.hasComponents(entityRecord, componentId) {
-		return fmt.Errorf("the entity %d already owns the component %d", entityId, componentId)
-	}
-
-	archetype := world.getNextArchetype(entityRecord, world.getComponentsIds(component)...)
-	err := addComponentsToArchetype1(world, entityRecord, archetype, component)
-	if err != nil {
-		return fmt.Errorf("the component %d cannot be added to entity %d: %w", componentId, entityId, err)
-	}
-
-	world.componentAddedFn(entityId, componentId)
-
-	return nil
-}
-
-// AddComponents2 adds the components A, B to the existing EntityId.
-//
-// It returns an error if:
-//   - the entity does not exist
-//   - the entity has one of the component
-//   - an internal error occurs
-//
-// This solution is faster than an atomic solution.
-func AddComponents2[A, B ComponentInterface](world *World, entityId EntityId, a A, b B) error {
-	if !world.Exists(entityId) {
-		return fmt.Errorf("entity %v does not exist", entityId)
-	}
-	entityRecord := world.entities[entityId]
-
-	return addComponents2(world, entityRecord, a, b)
-}
-
-func addComponents2[A, B ComponentInterface](world *World, entityRecord entityRecord, a A, b B) error {
-	archetype := world.getNextArchetype(entityRecord, a.GetComponentId(), b.GetComponentId())
-
-	entityId := entityRecord.Id
-	if world.hasComponents(entityRecord, a.GetComponentId(), b.GetComponentId()) {
-		return fmt.Errorf("the entity %d already owns the components %v", entityId, []ComponentId{a.GetComponentId(), b.GetComponentId()})
-	}
-
-	err := addComponentsToArchetype2(world, entityRecord, archetype, a, b)
-	if err != nil {
-		return fmt.Errorf("the components %d cannot be added to entity %d: %w", []ComponentId{a.GetComponentId(), b.GetComponentId()}, entityId, err)
-	}
-
-	world.componentAddedFn(entityId, a.GetComponentId())
-	world.componentAddedFn(entityId, b.GetComponentId())
-
-	return nil
-}
-
-// AddComponents3 adds the components A, B, C to the existing EntityId.
-//
-// It returns an error if:
-//   - the entity does not exist
-//   - the entity has one of the component
-//   - an internal error occurs
-//
-// This solution is faster than an atomic solution.
-func AddComponents3[A, B, C ComponentInterface](world *World, entityId EntityId, a A, b B, c C) error {
-	if !world.Exists(entityId) {
-		return fmt.Errorf("entity %v does not exist", entityId)
-	}
-	entityRecord := world.entities[entityId]
-
-	return addComponents3(world, entityRecord, a, b, c)
-}
-
-func addComponents3[A, B, C ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C) error {
-	archetype := world.getNextArchetype(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId())
-
-	entityId := entityRecord.Id
-
-	if world.hasComponents(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId()) {
-		return fmt.Errorf("the entity %d already owns the components %v", entityId, []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId()})
-	}
-
-	err := addComponentsToArchetype3(world, entityRecord, archetype, a, b, c)
-	if err != nil {
-		return fmt.Errorf("the components %d cannot be added to entity %d: %w", []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId()}, entityId, err)
-	}
-
-	world.componentAddedFn(entityId, a.GetComponentId())
-	world.componentAddedFn(entityId, b.GetComponentId())
-	world.componentAddedFn(entityId, c.GetComponentId())
-
-	return nil
-}
-
-// AddComponents4 adds the components A, B, C, D to the existing EntityId.
-//
-// It returns an error if:
-//   - the entity does not exist
-//   - the entity has one of the component
-//   - an internal error occurs
-//
-// This solution is faster than an atomic solution.
-func AddComponents4[A, B, C, D ComponentInterface](world *World, entityId EntityId, a A, b B, c C, d D) error {
-	if !world.Exists(entityId) {
-		return fmt.Errorf("entity %v does not exist", entityId)
-	}
-	entityRecord := world.entities[entityId]
-
-	return addComponents4(world, entityRecord, a, b, c, d)
-}
-
-func addComponents4[A, B, C, D ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C, d D) error {
-	archetype := world.getNextArchetype(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId())
-
-	entityId := entityRecord.Id
-
-	if world.hasComponents(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId()) {
-		return fmt.Errorf("the entity %d already owns the components %v", entityId, []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId()})
-	}
-
-	err := addComponentsToArchetype4(world, entityRecord, archetype, a, b, c, d)
-	if err != nil {
-		return fmt.Errorf("the components %d cannot be added to entity %d: %w", []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId()}, entityId, err)
-	}
-
-	world.componentAddedFn(entityId, a.GetComponentId())
-	world.componentAddedFn(entityId, b.GetComponentId())
-	world.componentAddedFn(entityId, c.GetComponentId())
-	world.componentAddedFn(entityId, d.GetComponentId())
-
-	return nil
-}
-
-// AddComponents5 adds the components A, B, C, D, E to the existing EntityId.
-//
-// It returns an error if:
-//   - the entity does not exist
-//   - the entity has one of the component
-//   - an internal error occurs
-//
-// This solution is faster than an atomic solution.
-func AddComponents5[A, B, C, D, E ComponentInterface](world *World, entityId EntityId, a A, b B, c C, d D, e E) error {
-	if !world.Exists(entityId) {
-		return fmt.Errorf("entity %v does not exist", entityId)
-	}
-	entityRecord := world.entities[entityId]
-
-	return addComponents5(world, entityRecord, a, b, c, d, e)
-}
-
-func addComponents5[A, B, C, D, E ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C, d D, e E) error {
-	archetype := world.getNextArchetype(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId())
-
-	entityId := entityRecord.Id
-
-	if world.hasComponents(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId()) {
-		return fmt.Errorf("the entity %d already owns the components %v", entityId, []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId()})
-	}
-
-	err := addComponentsToArchetype5(world, entityRecord, archetype, a, b, c, d, e)
-	if err != nil {
-		return fmt.Errorf("the components %d cannot be added to entity %d: %w", []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId()}, entityId, err)
-	}
-
-	world.componentAddedFn(entityId, a.GetComponentId())
-	world.componentAddedFn(entityId, b.GetComponentId())
-	world.componentAddedFn(entityId, c.GetComponentId())
-	world.componentAddedFn(entityId, d.GetComponentId())
-	world.componentAddedFn(entityId, e.GetComponentId())
-
-	return nil
-}
-
-// AddComponents6 adds the components A, B, C, D, E, F to the existing EntityId.
-//
-// It returns an error if:
-//   - the entity does not exist
-//   - the entity has one of the component
-//   - an internal error occurs
-//
-// This solution is faster than an atomic solution.
-func AddComponents6[A, B, C, D, E, F ComponentInterface](world *World, entityId EntityId, a A, b B, c C, d D, e E, f F) error {
-	if !world.Exists(entityId) {
-		return fmt.Errorf("entity %v does not exist", entityId)
-	}
-	entityRecord := world.entities[entityId]
-
-	return addComponents6(world, entityRecord, a, b, c, d, e, f)
-}
-
-func addComponents6[A, B, C, D, E, F ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C, d D, e E, f F) error {
-	archetype := world.getNextArchetype(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId())
-
-	entityId := entityRecord.Id
-
-	if world.hasComponents(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId()) {
-		return fmt.Errorf("the entity %d already owns the components %v", entityId, []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId()})
-	}
-
-	err := addComponentsToArchetype6(world, entityRecord, archetype, a, b, c, d, e, f)
-	if err != nil {
-		return fmt.Errorf("the components %d cannot be added to entity %d: %w", []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId()}, entityId, err)
-	}
-
-	world.componentAddedFn(entityId, a.GetComponentId())
-	world.componentAddedFn(entityId, b.GetComponentId())
-	world.componentAddedFn(entityId, c.GetComponentId())
-	world.componentAddedFn(entityId, d.GetComponentId())
-	world.componentAddedFn(entityId, e.GetComponentId())
-	world.componentAddedFn(entityId, f.GetComponentId())
-
-	return nil
-}
-
-// AddComponents7 adds the components A, B, C, D, E, F, G to the existing EntityId.
-//
-// It returns an error if:
-//   - the entity does not exist
-//   - the entity has one of the component
-//   - an internal error occurs
-//
-// This solution is faster than an atomic solution.
-func AddComponents7[A, B, C, D, E, F, G ComponentInterface](world *World, entityId EntityId, a A, b B, c C, d D, e E, f F, g G) error {
-	if !world.Exists(entityId) {
-		return fmt.Errorf("entity %v does not exist", entityId)
-	}
-	entityRecord := world.entities[entityId]
-
-	return addComponents7(world, entityRecord, a, b, c, d, e, f, g)
-}
-
-func addComponents7[A, B, C, D, E, F, G ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C, d D, e E, f F, g G) error {
-	archetype := world.getNextArchetype(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId(), g.GetComponentId())
-
-	entityId := entityRecord.Id
-
-	if world.hasComponents(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId(), g.GetComponentId()) {
-		return fmt.Errorf("the entity %d already owns the components %v", entityId, []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId(), g.GetComponentId()})
+		return fmt.Errorf("the entity %d already owns the component %s", entityId, world.componentName(componentId))
 	}
-
-	err := addComponentsToArchetype7(world, entityRecord, archetype, a, b, c, d, e, f, g)
-	if err != nil {
-		return fmt.Errorf("the components %d cannot be added to entity %d: %w", []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId(), g.GetComponentId()}, entityId, err)
-	}
-
-	world.componentAddedFn(entityId, a.GetComponentId())
-	world.componentAddedFn(entityId, b.GetComponentId())
-	world.componentAddedFn(entityId, c.GetComponentId())
-	world.componentAddedFn(entityId, d.GetComponentId())
-	world.componentAddedFn(entityId, e.GetComponentId())
-	world.componentAddedFn(entityId, f.GetComponentId())
-	world.componentAddedFn(entityId, g.GetComponentId())
-
-	return nil
-}
-
-// AddComponents8 adds the components A, B, C, D, E, F, G, H to the existing EntityId.
-//
-// It returns an error if:
-//   - the entity does not exist
-//   - the entity has one of the component
-//   - an internal error occurs
-//
-// This solution is faster than an atomic solution.
-func AddComponents8[A, B, C, D, E, F, G, H ComponentInterface](world *World, entityId EntityId, a A, b B, c C, d D, e E, f F, g G, h H) error {
-	if !world.Exists(entityId) {
-		return fmt.Errorf("entity %v does not exist", entityId)
+	if s := getStorage[T](world); s != nil && s.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(componentId))
 	}
-	entityRecord := world.entities[entityId]
-
-	return addComponents8(world, entityRecord, a, b, c, d, e, f, g, h)
-}
-
-func addComponents8[A, B, C, D, E, F, G, H ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C, d D, e E, f F, g G, h H) error {
-	archetype := world.getNextArchetype(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId(), g.GetComponentId(), h.GetComponentId())
-
-	entityId := entityRecord.Id
-
-	if world.hasComponents(entityRecord, a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId(), g.GetComponentId(), h.GetComponentId()) {
-		return fmt.Errorf("the entity %d already owns the components %v", entityId, []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId(), g.GetComponentId(), h.GetComponentId()})
+	if err := checkIndexes(world, entityId, componentId, component); err != nil {
+		return err
 	}
 
-	err := addComponentsToArchetype8(world, entityRecord, archetype, a, b, c, d, e, f, g, h)
+	archetype := world.getNextArchetype(entityRecord, componentId)
+	err := addComponentsToArchetype1(world, entityRecord, archetype, component)
 	if err != nil {
-		return fmt.Errorf("the components %d cannot be added to entity %d: %w", []ComponentId{a.GetComponentId(), b.GetComponentId(), c.GetComponentId(), d.GetComponentId(), e.GetComponentId(), f.GetComponentId(), g.GetComponentId(), h.GetComponentId()}, entityId, err)
+		return fmt.Errorf("the component %s cannot be added to entity %d: %w", world.componentName(componentId), entityId, err)
 	}
 
-	world.componentAddedFn(entityId, a.GetComponentId())
-	world.componentAddedFn(entityId, b.GetComponentId())
-	world.componentAddedFn(entityId, c.GetComponentId())
-	world.componentAddedFn(entityId, d.GetComponentId())
-	world.componentAddedFn(entityId, e.GetComponentId())
-	world.componentAddedFn(entityId, f.GetComponentId())
-	world.componentAddedFn(entityId, g.GetComponentId())
-	world.componentAddedFn(entityId, h.GetComponentId())
+	world.updateIndexes(entityId, componentId)
+	world.componentAddedFn(entityId, componentId)
 
 	return nil
 }
@@ -352,15 +70,17 @@ func addComponents8[A, B, C, D, E, F, G, H ComponentInterface](world *World, ent
 // It returns an error if:
 //   - the entity already has the componentId
 //   - the componentId is not registered in the World
+//   - the component is shared, it is set with SetSharedComponent
+//   - the component is dynamic, and conf is a []byte which does not match its size
 //   - an internal error occurs
 func (world *World) AddComponent(entityId EntityId, componentId ComponentId, conf any) error {
 	if !world.Exists(entityId) {
 		return fmt.Errorf("entity %v does not exist", entityId)
 	}
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 
 	if world.hasComponents(entityRecord, componentId) {
-		return fmt.Errorf("the entity %d already owns the component %d", entityId, componentId)
+		return fmt.Errorf("the entity %d already owns the component %s", entityId, world.componentName(componentId))
 	}
 
 	componentRegistry, err := world.getConfigByComponentId(componentId)
@@ -383,12 +103,14 @@ func (world *World) AddComponent(entityId EntityId, componentId ComponentId, con
 // It returns an error if:
 //   - the entity already has the components Ids
 //   - the componentsIds are not registered in the World
+//   - a component is shared, it is set with SetSharedComponent
+//   - a component is dynamic, and its conf is a []byte which does not match its size
 //   - an internal error occurs
 func (world *World) AddComponents(entityId EntityId, componentsIdsConfs ...ComponentIdConf) error {
 	if !world.Exists(entityId) {
 		return fmt.Errorf("entity %v does not exist", entityId)
 	}
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 
 	var componentsIds []ComponentId
 	for _, componentIdConf := range componentsIdsConfs {
@@ -396,7 +118,7 @@ func (world *World) AddComponents(entityId EntityId, componentsIdsConfs ...Compo
 	}
 
 	if world.hasComponents(entityRecord, componentsIds...) {
-		return fmt.Errorf("the entity %d already owns the components %v", entityId, componentsIds)
+		return fmt.Errorf("the entity %d already owns the components %v", entityId, world.componentsNames(componentsIds...))
 	}
 
 	for _, componentIdConf := range componentsIdsConfs {
@@ -416,20 +138,45 @@ func (world *World) AddComponents(entityId EntityId, componentsIdsConfs ...Compo
 	return nil
 }
 
+// SetComponent replaces the value of the component T owned by the entity, and updates the indexes of T.
+// A shared component is not replaced: the entity moves to the group of the new value, see SetSharedComponent.
+//
+// It returns an error if:
+//   - the entity does not have the component
+//   - the new value has the key of another entity in a unique Index
+func SetComponent[T ComponentInterface](world *World, entityId EntityId, component T) error {
+	componentId := ComponentIdOf[T](world)
+	if s := getStorage[T](world); s != nil && s.shared != nil && world.HasComponents(entityId, componentId) {
+		return setSharedComponent(world, entityId, componentId, component)
+	}
+
+	current := GetComponent[T](world, entityId)
+	if current == nil {
+		return fmt.Errorf("the entity %d doesn't own the component %s", entityId, world.componentName(componentId))
+	}
+	if err := checkIndexes(world, entityId, componentId, component); err != nil {
+		return err
+	}
+
+	*current = component
+	world.updateIndexes(entityId, componentId)
+
+	return nil
+}
+
 // RemoveComponent removes the component to EntityId.
 //
 // It returns an error if the EntityId does not have the component.
 func RemoveComponent[T ComponentInterface](world *World, entityId EntityId) error {
-	var t T
-	componentId := t.GetComponentId()
+	componentId := ComponentIdOf[T](world)
 
 	if !world.Exists(entityId) {
 		return fmt.Errorf("entity %v does not exist", entityId)
 	}
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 
 	if !world.hasComponents(entityRecord, componentId) {
-		return fmt.Errorf("the entity %d doesn't own the component %d", entityId, componentId)
+		return fmt.Errorf("the entity %d doesn't own the component %s", entityId, world.componentName(componentId))
 	}
 
 	// Remove from the previous archetype
@@ -449,10 +196,10 @@ func (world *World) RemoveComponent(entityId EntityId, componentId ComponentId)
 	if !world.Exists(entityId) {
 		return fmt.Errorf("entity %v does not exist", entityId)
 	}
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 
 	if !world.hasComponents(entityRecord, componentId) {
-		return fmt.Errorf("the entity %d doesn't own the component %d", entityId, componentId)
+		return fmt.Errorf("the entity %d doesn't own the component %s", entityId, world.componentName(componentId))
 	}
 
 	// Remove from the previous archetype
@@ -467,8 +214,25 @@ func (world *World) RemoveComponent(entityId EntityId, componentId ComponentId)
 }
 
 func removeComponent(world *World, s storage, entityRecord entityRecord, componentId ComponentId) {
+	if s.isSparse() {
+		world.guardSparse(entityRecord.Id, s)
+	} else {
+		world.guardArchetype(entityRecord.Id, entityRecord.archetypeId)
+	}
 	world.componentRemovedFn(entityRecord.Id, componentId)
+	world.unindexComponent(entityRecord.Id, componentId)
 
+	// A sparse component is not part of the archetype, the entity does not move.
+	if s.isSparse() {
+		s.removeEntity(entityRecord.Id)
+		return
+	}
+	if s.isShared() {
+		world.removeSharedComponent(entityRecord, componentId)
+		return
+	}
+
+	entityRecord = world.detachRow(entityRecord)
 	oldArchetypeId := entityRecord.archetypeId
 	s.moveLastToKey(oldArchetypeId, entityRecord.key)
 
@@ -489,15 +253,18 @@ func (world *World) HasComponents(entityId EntityId, componentsIds ...ComponentI
 	if !world.Exists(entityId) {
 		return false
 	}
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 
 	return world.hasComponents(entityRecord, componentsIds...)
 }
 
 func (world *World) hasComponents(entityRecord entityRecord, componentsIds ...ComponentId) bool {
-	archetype := world.archetypes[entityRecord.archetypeId]
+	archetype := &world.archetypes[entityRecord.archetypeId]
 	for _, componentId := range componentsIds {
-		if !slices.Contains(archetype.Type, componentId) {
+		if archetype.signature.has(componentId) {
+			continue
+		}
+		if !world.isSparse(componentId) || !world.storage[componentId].hasEntity(entityRecord.Id) {
 			return false
 		}
 	}
@@ -517,7 +284,15 @@ func GetComponent[T ComponentInterface](world *World, entityId EntityId) *T {
 	if s == nil {
 		return nil
 	}
-	entityRecord := world.entities[entityId]
+	world.guardWrite(entityId, s.componentId)
+	if s.sparse != nil {
+		return s.sparse.get(entityId)
+	}
+
+	entityRecord := world.entities.get(entityId)
+	if s.shared != nil {
+		return s.shared.get(entityRecord.archetypeId)
+	}
 
 	if !s.hasArchetype(entityRecord.archetypeId) {
 		return nil
@@ -537,275 +312,80 @@ func (world *World) GetComponent(entityId EntityId, componentId ComponentId) (an
 	if !world.Exists(entityId) {
 		return nil, fmt.Errorf("entity %v does not exist", entityId)
 	}
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 	s, err := world.getStorageForComponentId(componentId)
 	if err != nil {
 		return nil, err
 	}
+	world.guardWrite(entityId, componentId)
 
-	if !s.hasArchetype(entityRecord.archetypeId) {
-		return nil, fmt.Errorf("the entity %d doesn't own the component %d", entityId, componentId)
-	}
-
-	return s.get(entityRecord.archetypeId, entityRecord.key), nil
-}
-
-func addComponentsToArchetype1[A ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, component A) error {
-	storageA := getStorage[A](world)
-
-	if storageA == nil {
-		componentId := component.GetComponentId()
-		return fmt.Errorf("no storage found for component %d", componentId)
-	}
-
-	// If the entity has no component, simply add it the archetype
-	if entityRecord.archetypeId == 0 {
-		world.setArchetype(entityRecord, archetype)
-	} else {
-		oldArchetype := world.getArchetype(entityRecord)
-		if archetype.Id != oldArchetype.Id {
-			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
-			world.setArchetype(entityRecord, archetype)
-		}
-	}
-	storageA.addTyped(archetype.Id, component)
-
-	return nil
-}
-
-func addComponentsToArchetype2[A, B ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B) error {
-	storageA := getStorage[A](world)
-	storageB := getStorage[B](world)
-
-	if storageA == nil || storageB == nil {
-		componentsIds := []ComponentId{componentA.GetComponentId(), componentB.GetComponentId()}
-		return fmt.Errorf("no storage found for component %v", componentsIds)
-	}
-
-	// If the entity has no component, simply add it the archetype
-	if entityRecord.archetypeId == 0 {
-		world.setArchetype(entityRecord, archetype)
-	} else {
-		oldArchetype := world.getArchetype(entityRecord)
-		if archetype.Id != oldArchetype.Id {
-			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
-			world.setArchetype(entityRecord, archetype)
-		}
-	}
-
-	storageA.addTyped(archetype.Id, componentA)
-	storageB.addTyped(archetype.Id, componentB)
-
-	return nil
-}
-
-func addComponentsToArchetype3[A, B, C ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C) error {
-	storageA := getStorage[A](world)
-	storageB := getStorage[B](world)
-	storageC := getStorage[C](world)
-
-	if storageA == nil || storageB == nil || storageC == nil {
-		componentsIds := []ComponentId{componentA.GetComponentId(), componentB.GetComponentId(), componentC.GetComponentId()}
-		return fmt.Errorf("no storage found for components %v", componentsIds)
-	}
-
-	// If the entity has no component, simply add it the archetype
-	if entityRecord.archetypeId == 0 {
-		world.setArchetype(entityRecord, archetype)
-	} else {
-		oldArchetype := world.getArchetype(entityRecord)
-		if archetype.Id != oldArchetype.Id {
-			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
-			world.setArchetype(entityRecord, archetype)
+	if s.isSparse() {
+		component := s.getEntity(entityId)
+		if component == nil {
+			return nil, fmt.Errorf("the entity %d doesn't own the component %s", entityId, world.componentName(componentId))
 		}
-	}
-
-	storageA.addTyped(archetype.Id, componentA)
-	storageB.addTyped(archetype.Id, componentB)
-	storageC.addTyped(archetype.Id, componentC)
-
-	return nil
-}
 
-func addComponentsToArchetype4[A, B, C, D ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C, componentD D) error {
-	storageA := getStorage[A](world)
-	storageB := getStorage[B](world)
-	storageC := getStorage[C](world)
-	storageD := getStorage[D](world)
-
-	if storageA == nil || storageB == nil || storageC == nil || storageD == nil {
-		componentsIds := []ComponentId{componentA.GetComponentId(), componentB.GetComponentId(), componentC.GetComponentId(), componentD.GetComponentId()}
-		return fmt.Errorf("no storage found for components %v", componentsIds)
+		return component, nil
 	}
 
-	// If the entity has no component, simply add it the archetype
-	if entityRecord.archetypeId == 0 {
-		world.setArchetype(entityRecord, archetype)
-	} else {
-		oldArchetype := world.getArchetype(entityRecord)
-		if archetype.Id != oldArchetype.Id {
-			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
-			world.setArchetype(entityRecord, archetype)
-		}
-	}
-
-	storageA.addTyped(archetype.Id, componentA)
-	storageB.addTyped(archetype.Id, componentB)
-	storageC.addTyped(archetype.Id, componentC)
-	storageD.addTyped(archetype.Id, componentD)
-
-	return nil
-}
-
-func addComponentsToArchetype5[A, B, C, D, E ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C, componentD D, componentE E) error {
-	storageA := getStorage[A](world)
-	storageB := getStorage[B](world)
-	storageC := getStorage[C](world)
-	storageD := getStorage[D](world)
-	storageE := getStorage[E](world)
-
-	if storageA == nil || storageB == nil || storageC == nil || storageD == nil || storageE == nil {
-		componentsIds := []ComponentId{componentA.GetComponentId(), componentB.GetComponentId(), componentC.GetComponentId(), componentD.GetComponentId(), componentE.GetComponentId()}
-		return fmt.Errorf("no storage found for components %v", componentsIds)
-	}
-
-	// If the entity has no component, simply add it the archetype
-	if entityRecord.archetypeId == 0 {
-		world.setArchetype(entityRecord, archetype)
-	} else {
-		oldArchetype := world.getArchetype(entityRecord)
-		if archetype.Id != oldArchetype.Id {
-			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
-			world.setArchetype(entityRecord, archetype)
-		}
-	}
-
-	storageA.addTyped(archetype.Id, componentA)
-	storageB.addTyped(archetype.Id, componentB)
-	storageC.addTyped(archetype.Id, componentC)
-	storageD.addTyped(archetype.Id, componentD)
-	storageE.addTyped(archetype.Id, componentE)
-
-	return nil
-}
-
-func addComponentsToArchetype6[A, B, C, D, E, F ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C, componentD D, componentE E, componentF F) error {
-	storageA := getStorage[A](world)
-	storageB := getStorage[B](world)
-	storageC := getStorage[C](world)
-	storageD := getStorage[D](world)
-	storageE := getStorage[E](world)
-	storageF := getStorage[F](world)
-
-	if storageA == nil || storageB == nil || storageC == nil || storageD == nil || storageE == nil || storageF == nil {
-		componentsIds := []ComponentId{componentA.GetComponentId(), componentB.GetComponentId(), componentC.GetComponentId(), componentD.GetComponentId(), componentE.GetComponentId(), componentF.GetComponentId()}
-		return fmt.Errorf("no storage found for components %v", componentsIds)
-	}
-
-	// If the entity has no component, simply add it the archetype
-	if entityRecord.archetypeId == 0 {
-		world.setArchetype(entityRecord, archetype)
-	} else {
-		oldArchetype := world.getArchetype(entityRecord)
-		if archetype.Id != oldArchetype.Id {
-			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
-			world.setArchetype(entityRecord, archetype)
-		}
+	if !s.hasArchetype(entityRecord.archetypeId) {
+		return nil, fmt.Errorf("the entity %d doesn't own the component %s", entityId, world.componentName(componentId))
 	}
 
-	storageA.addTyped(archetype.Id, componentA)
-	storageB.addTyped(archetype.Id, componentB)
-	storageC.addTyped(archetype.Id, componentC)
-	storageD.addTyped(archetype.Id, componentD)
-	storageE.addTyped(archetype.Id, componentE)
-	storageF.addTyped(archetype.Id, componentF)
-
-	return nil
+	return s.get(entityRecord.archetypeId, entityRecord.key), nil
 }
 
-func addComponentsToArchetype7[A, B, C, D, E, F, G ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C, componentD D, componentE E, componentF F, componentG G) error {
-	storageA := getStorage[A](world)
-	storageB := getStorage[B](world)
-	storageC := getStorage[C](world)
-	storageD := getStorage[D](world)
-	storageE := getStorage[E](world)
-	storageF := getStorage[F](world)
-	storageG := getStorage[G](world)
-
-	if storageA == nil || storageB == nil || storageC == nil || storageD == nil || storageE == nil || storageF == nil || storageG == nil {
-		componentsIds := []ComponentId{componentA.GetComponentId(), componentB.GetComponentId(), componentC.GetComponentId(), componentD.GetComponentId(), componentE.GetComponentId(), componentF.GetComponentId(), componentG.GetComponentId()}
-		return fmt.Errorf("no storage found for components %v", componentsIds)
+// AppendComponentBinary appends the binary encoding of the component with ComponentId owned by the EntityId to buf.
+//
+// The component is encoded with its AppendBinary or MarshalBinary method if it has one,
+// or else copied from memory in the native byte order, without the padding bytes between its fields.
+// It returns an error if:
+//   - the ComponentId is not registered in the World
+//   - the entity does not have the component
+func (world *World) AppendComponentBinary(buf []byte, entityId EntityId, componentId ComponentId) ([]byte, error) {
+	if !world.Exists(entityId) {
+		return buf, fmt.Errorf("entity %v does not exist", entityId)
 	}
 
-	// If the entity has no component, simply add it the archetype
-	if entityRecord.archetypeId == 0 {
-		world.setArchetype(entityRecord, archetype)
-	} else {
-		oldArchetype := world.getArchetype(entityRecord)
-		if archetype.Id != oldArchetype.Id {
-			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
-			world.setArchetype(entityRecord, archetype)
-		}
+	componentRegistry, err := world.getConfigByComponentId(componentId)
+	if err != nil {
+		return buf, err
 	}
 
-	storageA.addTyped(archetype.Id, componentA)
-	storageB.addTyped(archetype.Id, componentB)
-	storageC.addTyped(archetype.Id, componentC)
-	storageD.addTyped(archetype.Id, componentD)
-	storageE.addTyped(archetype.Id, componentE)
-	storageF.addTyped(archetype.Id, componentF)
-	storageG.addTyped(archetype.Id, componentG)
-
-	return nil
+	return componentRegistry.appendBinary(world, entityId, buf)
 }
 
-func addComponentsToArchetype8[A, B, C, D, E, F, G, H ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C, componentD D, componentE E, componentF F, componentG G, componentH H) error {
-	storageA := getStorage[A](world)
-	storageB := getStorage[B](world)
-	storageC := getStorage[C](world)
-	storageD := getStorage[D](world)
-	storageE := getStorage[E](world)
-	storageF := getStorage[F](world)
-	storageG := getStorage[G](world)
-	storageH := getStorage[H](world)
-
-	if storageA == nil || storageB == nil || storageC == nil || storageD == nil || storageE == nil || storageF == nil || storageG == nil || storageH == nil {
-		componentsIds := []ComponentId{componentA.GetComponentId(), componentB.GetComponentId(), componentC.GetComponentId(), componentD.GetComponentId(), componentE.GetComponentId(), componentF.GetComponentId(), componentG.GetComponentId(), componentH.GetComponentId()}
-		return fmt.Errorf("no storage found for components %v", componentsIds)
+// SetComponentBinary decodes data, encoded by AppendComponentBinary, into the component with ComponentId of the EntityId.
+//
+// The component is added if the entity does not own it yet.
+// It returns an error if:
+//   - the entity does not exist
+//   - the ComponentId is not registered in the World
+//   - data cannot be decoded
+func (world *World) SetComponentBinary(entityId EntityId, componentId ComponentId, data []byte) error {
+	if !world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
 	}
 
-	// If the entity has no component, simply add it the archetype
-	if entityRecord.archetypeId == 0 {
-		world.setArchetype(entityRecord, archetype)
-	} else {
-		oldArchetype := world.getArchetype(entityRecord)
-		if archetype.Id != oldArchetype.Id {
-			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
-			world.setArchetype(entityRecord, archetype)
-		}
+	componentRegistry, err := world.getConfigByComponentId(componentId)
+	if err != nil {
+		return err
 	}
 
-	storageA.addTyped(archetype.Id, componentA)
-	storageB.addTyped(archetype.Id, componentB)
-	storageC.addTyped(archetype.Id, componentC)
-	storageD.addTyped(archetype.Id, componentD)
-	storageE.addTyped(archetype.Id, componentE)
-	storageF.addTyped(archetype.Id, componentF)
-	storageG.addTyped(archetype.Id, componentG)
-	storageH.addTyped(archetype.Id, componentH)
-
-	return nil
+	return componentRegistry.setBinary(world, entityId, data)
 }
 
 func moveComponentsToArchetype(world *World, entityRecord entityRecord, oldArchetype *archetype, archetype *archetype) int {
 	var key, lastEntityKey int
+	world.guardMove(entityRecord, archetype.Id)
+	entityRecord = world.detachRow(entityRecord)
 
 	for _, componentId := range oldArchetype.Type {
 		// tags are not movable
 		if componentId >= TAGS_INDICES {
 			continue
 		}
-		if !slices.Contains(archetype.Type, componentId) {
+		if !archetype.signature.has(componentId) {
 			continue
 		}
 
@@ -820,9 +400,9 @@ func moveComponentsToArchetype(world *World, entityRecord entityRecord, oldArche
 	lastEntityKey = len(oldArchetype.entities) - 1
 
 	lastEntityId := oldArchetype.entities[lastEntityKey]
-	lastEntity := world.entities[lastEntityId]
+	lastEntity := world.entities.get(lastEntityId)
 	lastEntity.key = entityRecord.key
-	world.entities[lastEntityId] = lastEntity
+	world.entities.set(lastEntity)
 
 	oldArchetype.entities[entityRecord.key] = lastEntityId
 	oldArchetype.entities = oldArchetype.entities[:lastEntityKey]
@@ -0,0 +1,878 @@
+// Code generated by voltgen. DO NOT EDIT.
+
+package volt
+
+import (
+	"fmt"
+)
+
+// AddComponents2 adds the components A, B to the existing EntityId.
+//
+// It returns an error if:
+//   - the entity does not exist
+//   - the entity has one of the component
+//   - an internal error occurs
+//
+// This solution is faster than an atomic solution.
+func AddComponents2[A, B ComponentInterface](world *World, entityId EntityId, a A, b B) error {
+	if !world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
+	}
+	entityRecord := world.entities.get(entityId)
+
+	return addComponents2(world, entityRecord, a, b)
+}
+
+func addComponents2[A, B ComponentInterface](world *World, entityRecord entityRecord, a A, b B) error {
+	componentIdA := ComponentIdOf[A](world)
+	componentIdB := ComponentIdOf[B](world)
+
+	entityId := entityRecord.Id
+
+	if world.hasComponents(entityRecord, componentIdA, componentIdB) {
+		return fmt.Errorf("the entity %d already owns the components %v", entityId, world.componentsNames(componentIdA, componentIdB))
+	}
+	if err := checkIndexes(world, entityId, componentIdA, a); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdB, b); err != nil {
+		return err
+	}
+
+	archetype := world.getNextArchetype(entityRecord, componentIdA, componentIdB)
+	err := addComponentsToArchetype2(world, entityRecord, archetype, a, b)
+	if err != nil {
+		return fmt.Errorf("the components %v cannot be added to entity %d: %w", world.componentsNames(componentIdA, componentIdB), entityId, err)
+	}
+
+	world.updateIndexes(entityId, componentIdA)
+	world.updateIndexes(entityId, componentIdB)
+	world.componentAddedFn(entityId, componentIdA)
+	world.componentAddedFn(entityId, componentIdB)
+
+	return nil
+}
+
+// AddComponents3 adds the components A, B, C to the existing EntityId.
+//
+// It returns an error if:
+//   - the entity does not exist
+//   - the entity has one of the component
+//   - an internal error occurs
+//
+// This solution is faster than an atomic solution.
+func AddComponents3[A, B, C ComponentInterface](world *World, entityId EntityId, a A, b B, c C) error {
+	if !world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
+	}
+	entityRecord := world.entities.get(entityId)
+
+	return addComponents3(world, entityRecord, a, b, c)
+}
+
+func addComponents3[A, B, C ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C) error {
+	componentIdA := ComponentIdOf[A](world)
+	componentIdB := ComponentIdOf[B](world)
+	componentIdC := ComponentIdOf[C](world)
+
+	entityId := entityRecord.Id
+
+	if world.hasComponents(entityRecord, componentIdA, componentIdB, componentIdC) {
+		return fmt.Errorf("the entity %d already owns the components %v", entityId, world.componentsNames(componentIdA, componentIdB, componentIdC))
+	}
+	if err := checkIndexes(world, entityId, componentIdA, a); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdB, b); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdC, c); err != nil {
+		return err
+	}
+
+	archetype := world.getNextArchetype(entityRecord, componentIdA, componentIdB, componentIdC)
+	err := addComponentsToArchetype3(world, entityRecord, archetype, a, b, c)
+	if err != nil {
+		return fmt.Errorf("the components %v cannot be added to entity %d: %w", world.componentsNames(componentIdA, componentIdB, componentIdC), entityId, err)
+	}
+
+	world.updateIndexes(entityId, componentIdA)
+	world.updateIndexes(entityId, componentIdB)
+	world.updateIndexes(entityId, componentIdC)
+	world.componentAddedFn(entityId, componentIdA)
+	world.componentAddedFn(entityId, componentIdB)
+	world.componentAddedFn(entityId, componentIdC)
+
+	return nil
+}
+
+// AddComponents4 adds the components A, B, C, D to the existing EntityId.
+//
+// It returns an error if:
+//   - the entity does not exist
+//   - the entity has one of the component
+//   - an internal error occurs
+//
+// This solution is faster than an atomic solution.
+func AddComponents4[A, B, C, D ComponentInterface](world *World, entityId EntityId, a A, b B, c C, d D) error {
+	if !world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
+	}
+	entityRecord := world.entities.get(entityId)
+
+	return addComponents4(world, entityRecord, a, b, c, d)
+}
+
+func addComponents4[A, B, C, D ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C, d D) error {
+	componentIdA := ComponentIdOf[A](world)
+	componentIdB := ComponentIdOf[B](world)
+	componentIdC := ComponentIdOf[C](world)
+	componentIdD := ComponentIdOf[D](world)
+
+	entityId := entityRecord.Id
+
+	if world.hasComponents(entityRecord, componentIdA, componentIdB, componentIdC, componentIdD) {
+		return fmt.Errorf("the entity %d already owns the components %v", entityId, world.componentsNames(componentIdA, componentIdB, componentIdC, componentIdD))
+	}
+	if err := checkIndexes(world, entityId, componentIdA, a); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdB, b); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdC, c); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdD, d); err != nil {
+		return err
+	}
+
+	archetype := world.getNextArchetype(entityRecord, componentIdA, componentIdB, componentIdC, componentIdD)
+	err := addComponentsToArchetype4(world, entityRecord, archetype, a, b, c, d)
+	if err != nil {
+		return fmt.Errorf("the components %v cannot be added to entity %d: %w", world.componentsNames(componentIdA, componentIdB, componentIdC, componentIdD), entityId, err)
+	}
+
+	world.updateIndexes(entityId, componentIdA)
+	world.updateIndexes(entityId, componentIdB)
+	world.updateIndexes(entityId, componentIdC)
+	world.updateIndexes(entityId, componentIdD)
+	world.componentAddedFn(entityId, componentIdA)
+	world.componentAddedFn(entityId, componentIdB)
+	world.componentAddedFn(entityId, componentIdC)
+	world.componentAddedFn(entityId, componentIdD)
+
+	return nil
+}
+
+// AddComponents5 adds the components A, B, C, D, E to the existing EntityId.
+//
+// It returns an error if:
+//   - the entity does not exist
+//   - the entity has one of the component
+//   - an internal error occurs
+//
+// This solution is faster than an atomic solution.
+func AddComponents5[A, B, C, D, E ComponentInterface](world *World, entityId EntityId, a A, b B, c C, d D, e E) error {
+	if !world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
+	}
+	entityRecord := world.entities.get(entityId)
+
+	return addComponents5(world, entityRecord, a, b, c, d, e)
+}
+
+func addComponents5[A, B, C, D, E ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C, d D, e E) error {
+	componentIdA := ComponentIdOf[A](world)
+	componentIdB := ComponentIdOf[B](world)
+	componentIdC := ComponentIdOf[C](world)
+	componentIdD := ComponentIdOf[D](world)
+	componentIdE := ComponentIdOf[E](world)
+
+	entityId := entityRecord.Id
+
+	if world.hasComponents(entityRecord, componentIdA, componentIdB, componentIdC, componentIdD, componentIdE) {
+		return fmt.Errorf("the entity %d already owns the components %v", entityId, world.componentsNames(componentIdA, componentIdB, componentIdC, componentIdD, componentIdE))
+	}
+	if err := checkIndexes(world, entityId, componentIdA, a); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdB, b); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdC, c); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdD, d); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdE, e); err != nil {
+		return err
+	}
+
+	archetype := world.getNextArchetype(entityRecord, componentIdA, componentIdB, componentIdC, componentIdD, componentIdE)
+	err := addComponentsToArchetype5(world, entityRecord, archetype, a, b, c, d, e)
+	if err != nil {
+		return fmt.Errorf("the components %v cannot be added to entity %d: %w", world.componentsNames(componentIdA, componentIdB, componentIdC, componentIdD, componentIdE), entityId, err)
+	}
+
+	world.updateIndexes(entityId, componentIdA)
+	world.updateIndexes(entityId, componentIdB)
+	world.updateIndexes(entityId, componentIdC)
+	world.updateIndexes(entityId, componentIdD)
+	world.updateIndexes(entityId, componentIdE)
+	world.componentAddedFn(entityId, componentIdA)
+	world.componentAddedFn(entityId, componentIdB)
+	world.componentAddedFn(entityId, componentIdC)
+	world.componentAddedFn(entityId, componentIdD)
+	world.componentAddedFn(entityId, componentIdE)
+
+	return nil
+}
+
+// AddComponents6 adds the components A, B, C, D, E, F to the existing EntityId.
+//
+// It returns an error if:
+//   - the entity does not exist
+//   - the entity has one of the component
+//   - an internal error occurs
+//
+// This solution is faster than an atomic solution.
+func AddComponents6[A, B, C, D, E, F ComponentInterface](world *World, entityId EntityId, a A, b B, c C, d D, e E, f F) error {
+	if !world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
+	}
+	entityRecord := world.entities.get(entityId)
+
+	return addComponents6(world, entityRecord, a, b, c, d, e, f)
+}
+
+func addComponents6[A, B, C, D, E, F ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C, d D, e E, f F) error {
+	componentIdA := ComponentIdOf[A](world)
+	componentIdB := ComponentIdOf[B](world)
+	componentIdC := ComponentIdOf[C](world)
+	componentIdD := ComponentIdOf[D](world)
+	componentIdE := ComponentIdOf[E](world)
+	componentIdF := ComponentIdOf[F](world)
+
+	entityId := entityRecord.Id
+
+	if world.hasComponents(entityRecord, componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF) {
+		return fmt.Errorf("the entity %d already owns the components %v", entityId, world.componentsNames(componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF))
+	}
+	if err := checkIndexes(world, entityId, componentIdA, a); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdB, b); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdC, c); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdD, d); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdE, e); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdF, f); err != nil {
+		return err
+	}
+
+	archetype := world.getNextArchetype(entityRecord, componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF)
+	err := addComponentsToArchetype6(world, entityRecord, archetype, a, b, c, d, e, f)
+	if err != nil {
+		return fmt.Errorf("the components %v cannot be added to entity %d: %w", world.componentsNames(componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF), entityId, err)
+	}
+
+	world.updateIndexes(entityId, componentIdA)
+	world.updateIndexes(entityId, componentIdB)
+	world.updateIndexes(entityId, componentIdC)
+	world.updateIndexes(entityId, componentIdD)
+	world.updateIndexes(entityId, componentIdE)
+	world.updateIndexes(entityId, componentIdF)
+	world.componentAddedFn(entityId, componentIdA)
+	world.componentAddedFn(entityId, componentIdB)
+	world.componentAddedFn(entityId, componentIdC)
+	world.componentAddedFn(entityId, componentIdD)
+	world.componentAddedFn(entityId, componentIdE)
+	world.componentAddedFn(entityId, componentIdF)
+
+	return nil
+}
+
+// AddComponents7 adds the components A, B, C, D, E, F, G to the existing EntityId.
+//
+// It returns an error if:
+//   - the entity does not exist
+//   - the entity has one of the component
+//   - an internal error occurs
+//
+// This solution is faster than an atomic solution.
+func AddComponents7[A, B, C, D, E, F, G ComponentInterface](world *World, entityId EntityId, a A, b B, c C, d D, e E, f F, g G) error {
+	if !world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
+	}
+	entityRecord := world.entities.get(entityId)
+
+	return addComponents7(world, entityRecord, a, b, c, d, e, f, g)
+}
+
+func addComponents7[A, B, C, D, E, F, G ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C, d D, e E, f F, g G) error {
+	componentIdA := ComponentIdOf[A](world)
+	componentIdB := ComponentIdOf[B](world)
+	componentIdC := ComponentIdOf[C](world)
+	componentIdD := ComponentIdOf[D](world)
+	componentIdE := ComponentIdOf[E](world)
+	componentIdF := ComponentIdOf[F](world)
+	componentIdG := ComponentIdOf[G](world)
+
+	entityId := entityRecord.Id
+
+	if world.hasComponents(entityRecord, componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF, componentIdG) {
+		return fmt.Errorf("the entity %d already owns the components %v", entityId, world.componentsNames(componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF, componentIdG))
+	}
+	if err := checkIndexes(world, entityId, componentIdA, a); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdB, b); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdC, c); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdD, d); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdE, e); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdF, f); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdG, g); err != nil {
+		return err
+	}
+
+	archetype := world.getNextArchetype(entityRecord, componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF, componentIdG)
+	err := addComponentsToArchetype7(world, entityRecord, archetype, a, b, c, d, e, f, g)
+	if err != nil {
+		return fmt.Errorf("the components %v cannot be added to entity %d: %w", world.componentsNames(componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF, componentIdG), entityId, err)
+	}
+
+	world.updateIndexes(entityId, componentIdA)
+	world.updateIndexes(entityId, componentIdB)
+	world.updateIndexes(entityId, componentIdC)
+	world.updateIndexes(entityId, componentIdD)
+	world.updateIndexes(entityId, componentIdE)
+	world.updateIndexes(entityId, componentIdF)
+	world.updateIndexes(entityId, componentIdG)
+	world.componentAddedFn(entityId, componentIdA)
+	world.componentAddedFn(entityId, componentIdB)
+	world.componentAddedFn(entityId, componentIdC)
+	world.componentAddedFn(entityId, componentIdD)
+	world.componentAddedFn(entityId, componentIdE)
+	world.componentAddedFn(entityId, componentIdF)
+	world.componentAddedFn(entityId, componentIdG)
+
+	return nil
+}
+
+// AddComponents8 adds the components A, B, C, D, E, F, G, H to the existing EntityId.
+//
+// It returns an error if:
+//   - the entity does not exist
+//   - the entity has one of the component
+//   - an internal error occurs
+//
+// This solution is faster than an atomic solution.
+func AddComponents8[A, B, C, D, E, F, G, H ComponentInterface](world *World, entityId EntityId, a A, b B, c C, d D, e E, f F, g G, h H) error {
+	if !world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
+	}
+	entityRecord := world.entities.get(entityId)
+
+	return addComponents8(world, entityRecord, a, b, c, d, e, f, g, h)
+}
+
+func addComponents8[A, B, C, D, E, F, G, H ComponentInterface](world *World, entityRecord entityRecord, a A, b B, c C, d D, e E, f F, g G, h H) error {
+	componentIdA := ComponentIdOf[A](world)
+	componentIdB := ComponentIdOf[B](world)
+	componentIdC := ComponentIdOf[C](world)
+	componentIdD := ComponentIdOf[D](world)
+	componentIdE := ComponentIdOf[E](world)
+	componentIdF := ComponentIdOf[F](world)
+	componentIdG := ComponentIdOf[G](world)
+	componentIdH := ComponentIdOf[H](world)
+
+	entityId := entityRecord.Id
+
+	if world.hasComponents(entityRecord, componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF, componentIdG, componentIdH) {
+		return fmt.Errorf("the entity %d already owns the components %v", entityId, world.componentsNames(componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF, componentIdG, componentIdH))
+	}
+	if err := checkIndexes(world, entityId, componentIdA, a); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdB, b); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdC, c); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdD, d); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdE, e); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdF, f); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdG, g); err != nil {
+		return err
+	}
+	if err := checkIndexes(world, entityId, componentIdH, h); err != nil {
+		return err
+	}
+
+	archetype := world.getNextArchetype(entityRecord, componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF, componentIdG, componentIdH)
+	err := addComponentsToArchetype8(world, entityRecord, archetype, a, b, c, d, e, f, g, h)
+	if err != nil {
+		return fmt.Errorf("the components %v cannot be added to entity %d: %w", world.componentsNames(componentIdA, componentIdB, componentIdC, componentIdD, componentIdE, componentIdF, componentIdG, componentIdH), entityId, err)
+	}
+
+	world.updateIndexes(entityId, componentIdA)
+	world.updateIndexes(entityId, componentIdB)
+	world.updateIndexes(entityId, componentIdC)
+	world.updateIndexes(entityId, componentIdD)
+	world.updateIndexes(entityId, componentIdE)
+	world.updateIndexes(entityId, componentIdF)
+	world.updateIndexes(entityId, componentIdG)
+	world.updateIndexes(entityId, componentIdH)
+	world.componentAddedFn(entityId, componentIdA)
+	world.componentAddedFn(entityId, componentIdB)
+	world.componentAddedFn(entityId, componentIdC)
+	world.componentAddedFn(entityId, componentIdD)
+	world.componentAddedFn(entityId, componentIdE)
+	world.componentAddedFn(entityId, componentIdF)
+	world.componentAddedFn(entityId, componentIdG)
+	world.componentAddedFn(entityId, componentIdH)
+
+	return nil
+}
+
+func addComponentsToArchetype1[A ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A) error {
+	storageA := getStorage[A](world)
+
+	if storageA == nil {
+		componentId := ComponentIdOf[A](world)
+		return fmt.Errorf("no storage found for component %d", componentId)
+	}
+	if storageA.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageA.componentId))
+	}
+
+	world.guardMove(entityRecord, archetype.Id)
+	world.guardSparse(entityRecord.Id, storageA)
+
+	// The components are added before the entity, so that its row is complete once setArchetype places it.
+	storageA.insert(archetype.Id, entityRecord.Id, componentA)
+
+	// If the entity is not yet in an archetype, simply add it to the archetype
+	if entityRecord.key < 0 {
+		world.setArchetype(entityRecord, archetype)
+	} else {
+		oldArchetype := world.getArchetype(entityRecord)
+		if archetype.Id != oldArchetype.Id {
+			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+			world.setArchetype(entityRecord, archetype)
+		}
+	}
+
+	return nil
+}
+
+func addComponentsToArchetype2[A, B ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B) error {
+	storageA := getStorage[A](world)
+	storageB := getStorage[B](world)
+
+	if storageA == nil || storageB == nil {
+		componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world)}
+		return fmt.Errorf("no storage found for components %v", componentsIds)
+	}
+	if storageA.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageA.componentId))
+	}
+	if storageB.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageB.componentId))
+	}
+
+	world.guardMove(entityRecord, archetype.Id)
+	world.guardSparse(entityRecord.Id, storageA)
+	world.guardSparse(entityRecord.Id, storageB)
+
+	// The components are added before the entity, so that its row is complete once setArchetype places it.
+	storageA.insert(archetype.Id, entityRecord.Id, componentA)
+	storageB.insert(archetype.Id, entityRecord.Id, componentB)
+
+	// If the entity is not yet in an archetype, simply add it to the archetype
+	if entityRecord.key < 0 {
+		world.setArchetype(entityRecord, archetype)
+	} else {
+		oldArchetype := world.getArchetype(entityRecord)
+		if archetype.Id != oldArchetype.Id {
+			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+			world.setArchetype(entityRecord, archetype)
+		}
+	}
+
+	return nil
+}
+
+func addComponentsToArchetype3[A, B, C ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C) error {
+	storageA := getStorage[A](world)
+	storageB := getStorage[B](world)
+	storageC := getStorage[C](world)
+
+	if storageA == nil || storageB == nil || storageC == nil {
+		componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world)}
+		return fmt.Errorf("no storage found for components %v", componentsIds)
+	}
+	if storageA.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageA.componentId))
+	}
+	if storageB.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageB.componentId))
+	}
+	if storageC.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageC.componentId))
+	}
+
+	world.guardMove(entityRecord, archetype.Id)
+	world.guardSparse(entityRecord.Id, storageA)
+	world.guardSparse(entityRecord.Id, storageB)
+	world.guardSparse(entityRecord.Id, storageC)
+
+	// The components are added before the entity, so that its row is complete once setArchetype places it.
+	storageA.insert(archetype.Id, entityRecord.Id, componentA)
+	storageB.insert(archetype.Id, entityRecord.Id, componentB)
+	storageC.insert(archetype.Id, entityRecord.Id, componentC)
+
+	// If the entity is not yet in an archetype, simply add it to the archetype
+	if entityRecord.key < 0 {
+		world.setArchetype(entityRecord, archetype)
+	} else {
+		oldArchetype := world.getArchetype(entityRecord)
+		if archetype.Id != oldArchetype.Id {
+			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+			world.setArchetype(entityRecord, archetype)
+		}
+	}
+
+	return nil
+}
+
+func addComponentsToArchetype4[A, B, C, D ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C, componentD D) error {
+	storageA := getStorage[A](world)
+	storageB := getStorage[B](world)
+	storageC := getStorage[C](world)
+	storageD := getStorage[D](world)
+
+	if storageA == nil || storageB == nil || storageC == nil || storageD == nil {
+		componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world), ComponentIdOf[D](world)}
+		return fmt.Errorf("no storage found for components %v", componentsIds)
+	}
+	if storageA.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageA.componentId))
+	}
+	if storageB.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageB.componentId))
+	}
+	if storageC.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageC.componentId))
+	}
+	if storageD.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageD.componentId))
+	}
+
+	world.guardMove(entityRecord, archetype.Id)
+	world.guardSparse(entityRecord.Id, storageA)
+	world.guardSparse(entityRecord.Id, storageB)
+	world.guardSparse(entityRecord.Id, storageC)
+	world.guardSparse(entityRecord.Id, storageD)
+
+	// The components are added before the entity, so that its row is complete once setArchetype places it.
+	storageA.insert(archetype.Id, entityRecord.Id, componentA)
+	storageB.insert(archetype.Id, entityRecord.Id, componentB)
+	storageC.insert(archetype.Id, entityRecord.Id, componentC)
+	storageD.insert(archetype.Id, entityRecord.Id, componentD)
+
+	// If the entity is not yet in an archetype, simply add it to the archetype
+	if entityRecord.key < 0 {
+		world.setArchetype(entityRecord, archetype)
+	} else {
+		oldArchetype := world.getArchetype(entityRecord)
+		if archetype.Id != oldArchetype.Id {
+			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+			world.setArchetype(entityRecord, archetype)
+		}
+	}
+
+	return nil
+}
+
+func addComponentsToArchetype5[A, B, C, D, E ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C, componentD D, componentE E) error {
+	storageA := getStorage[A](world)
+	storageB := getStorage[B](world)
+	storageC := getStorage[C](world)
+	storageD := getStorage[D](world)
+	storageE := getStorage[E](world)
+
+	if storageA == nil || storageB == nil || storageC == nil || storageD == nil || storageE == nil {
+		componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world), ComponentIdOf[D](world), ComponentIdOf[E](world)}
+		return fmt.Errorf("no storage found for components %v", componentsIds)
+	}
+	if storageA.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageA.componentId))
+	}
+	if storageB.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageB.componentId))
+	}
+	if storageC.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageC.componentId))
+	}
+	if storageD.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageD.componentId))
+	}
+	if storageE.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageE.componentId))
+	}
+
+	world.guardMove(entityRecord, archetype.Id)
+	world.guardSparse(entityRecord.Id, storageA)
+	world.guardSparse(entityRecord.Id, storageB)
+	world.guardSparse(entityRecord.Id, storageC)
+	world.guardSparse(entityRecord.Id, storageD)
+	world.guardSparse(entityRecord.Id, storageE)
+
+	// The components are added before the entity, so that its row is complete once setArchetype places it.
+	storageA.insert(archetype.Id, entityRecord.Id, componentA)
+	storageB.insert(archetype.Id, entityRecord.Id, componentB)
+	storageC.insert(archetype.Id, entityRecord.Id, componentC)
+	storageD.insert(archetype.Id, entityRecord.Id, componentD)
+	storageE.insert(archetype.Id, entityRecord.Id, componentE)
+
+	// If the entity is not yet in an archetype, simply add it to the archetype
+	if entityRecord.key < 0 {
+		world.setArchetype(entityRecord, archetype)
+	} else {
+		oldArchetype := world.getArchetype(entityRecord)
+		if archetype.Id != oldArchetype.Id {
+			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+			world.setArchetype(entityRecord, archetype)
+		}
+	}
+
+	return nil
+}
+
+func addComponentsToArchetype6[A, B, C, D, E, F ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C, componentD D, componentE E, componentF F) error {
+	storageA := getStorage[A](world)
+	storageB := getStorage[B](world)
+	storageC := getStorage[C](world)
+	storageD := getStorage[D](world)
+	storageE := getStorage[E](world)
+	storageF := getStorage[F](world)
+
+	if storageA == nil || storageB == nil || storageC == nil || storageD == nil || storageE == nil || storageF == nil {
+		componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world), ComponentIdOf[D](world), ComponentIdOf[E](world), ComponentIdOf[F](world)}
+		return fmt.Errorf("no storage found for components %v", componentsIds)
+	}
+	if storageA.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageA.componentId))
+	}
+	if storageB.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageB.componentId))
+	}
+	if storageC.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageC.componentId))
+	}
+	if storageD.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageD.componentId))
+	}
+	if storageE.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageE.componentId))
+	}
+	if storageF.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageF.componentId))
+	}
+
+	world.guardMove(entityRecord, archetype.Id)
+	world.guardSparse(entityRecord.Id, storageA)
+	world.guardSparse(entityRecord.Id, storageB)
+	world.guardSparse(entityRecord.Id, storageC)
+	world.guardSparse(entityRecord.Id, storageD)
+	world.guardSparse(entityRecord.Id, storageE)
+	world.guardSparse(entityRecord.Id, storageF)
+
+	// The components are added before the entity, so that its row is complete once setArchetype places it.
+	storageA.insert(archetype.Id, entityRecord.Id, componentA)
+	storageB.insert(archetype.Id, entityRecord.Id, componentB)
+	storageC.insert(archetype.Id, entityRecord.Id, componentC)
+	storageD.insert(archetype.Id, entityRecord.Id, componentD)
+	storageE.insert(archetype.Id, entityRecord.Id, componentE)
+	storageF.insert(archetype.Id, entityRecord.Id, componentF)
+
+	// If the entity is not yet in an archetype, simply add it to the archetype
+	if entityRecord.key < 0 {
+		world.setArchetype(entityRecord, archetype)
+	} else {
+		oldArchetype := world.getArchetype(entityRecord)
+		if archetype.Id != oldArchetype.Id {
+			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+			world.setArchetype(entityRecord, archetype)
+		}
+	}
+
+	return nil
+}
+
+func addComponentsToArchetype7[A, B, C, D, E, F, G ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C, componentD D, componentE E, componentF F, componentG G) error {
+	storageA := getStorage[A](world)
+	storageB := getStorage[B](world)
+	storageC := getStorage[C](world)
+	storageD := getStorage[D](world)
+	storageE := getStorage[E](world)
+	storageF := getStorage[F](world)
+	storageG := getStorage[G](world)
+
+	if storageA == nil || storageB == nil || storageC == nil || storageD == nil || storageE == nil || storageF == nil || storageG == nil {
+		componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world), ComponentIdOf[D](world), ComponentIdOf[E](world), ComponentIdOf[F](world), ComponentIdOf[G](world)}
+		return fmt.Errorf("no storage found for components %v", componentsIds)
+	}
+	if storageA.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageA.componentId))
+	}
+	if storageB.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageB.componentId))
+	}
+	if storageC.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageC.componentId))
+	}
+	if storageD.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageD.componentId))
+	}
+	if storageE.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageE.componentId))
+	}
+	if storageF.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageF.componentId))
+	}
+	if storageG.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageG.componentId))
+	}
+
+	world.guardMove(entityRecord, archetype.Id)
+	world.guardSparse(entityRecord.Id, storageA)
+	world.guardSparse(entityRecord.Id, storageB)
+	world.guardSparse(entityRecord.Id, storageC)
+	world.guardSparse(entityRecord.Id, storageD)
+	world.guardSparse(entityRecord.Id, storageE)
+	world.guardSparse(entityRecord.Id, storageF)
+	world.guardSparse(entityRecord.Id, storageG)
+
+	// The components are added before the entity, so that its row is complete once setArchetype places it.
+	storageA.insert(archetype.Id, entityRecord.Id, componentA)
+	storageB.insert(archetype.Id, entityRecord.Id, componentB)
+	storageC.insert(archetype.Id, entityRecord.Id, componentC)
+	storageD.insert(archetype.Id, entityRecord.Id, componentD)
+	storageE.insert(archetype.Id, entityRecord.Id, componentE)
+	storageF.insert(archetype.Id, entityRecord.Id, componentF)
+	storageG.insert(archetype.Id, entityRecord.Id, componentG)
+
+	// If the entity is not yet in an archetype, simply add it to the archetype
+	if entityRecord.key < 0 {
+		world.setArchetype(entityRecord, archetype)
+	} else {
+		oldArchetype := world.getArchetype(entityRecord)
+		if archetype.Id != oldArchetype.Id {
+			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+			world.setArchetype(entityRecord, archetype)
+		}
+	}
+
+	return nil
+}
+
+func addComponentsToArchetype8[A, B, C, D, E, F, G, H ComponentInterface](world *World, entityRecord entityRecord, archetype *archetype, componentA A, componentB B, componentC C, componentD D, componentE E, componentF F, componentG G, componentH H) error {
+	storageA := getStorage[A](world)
+	storageB := getStorage[B](world)
+	storageC := getStorage[C](world)
+	storageD := getStorage[D](world)
+	storageE := getStorage[E](world)
+	storageF := getStorage[F](world)
+	storageG := getStorage[G](world)
+	storageH := getStorage[H](world)
+
+	if storageA == nil || storageB == nil || storageC == nil || storageD == nil || storageE == nil || storageF == nil || storageG == nil || storageH == nil {
+		componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world), ComponentIdOf[D](world), ComponentIdOf[E](world), ComponentIdOf[F](world), ComponentIdOf[G](world), ComponentIdOf[H](world)}
+		return fmt.Errorf("no storage found for components %v", componentsIds)
+	}
+	if storageA.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageA.componentId))
+	}
+	if storageB.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageB.componentId))
+	}
+	if storageC.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageC.componentId))
+	}
+	if storageD.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageD.componentId))
+	}
+	if storageE.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageE.componentId))
+	}
+	if storageF.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageF.componentId))
+	}
+	if storageG.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageG.componentId))
+	}
+	if storageH.shared != nil {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(storageH.componentId))
+	}
+
+	world.guardMove(entityRecord, archetype.Id)
+	world.guardSparse(entityRecord.Id, storageA)
+	world.guardSparse(entityRecord.Id, storageB)
+	world.guardSparse(entityRecord.Id, storageC)
+	world.guardSparse(entityRecord.Id, storageD)
+	world.guardSparse(entityRecord.Id, storageE)
+	world.guardSparse(entityRecord.Id, storageF)
+	world.guardSparse(entityRecord.Id, storageG)
+	world.guardSparse(entityRecord.Id, storageH)
+
+	// The components are added before the entity, so that its row is complete once setArchetype places it.
+	storageA.insert(archetype.Id, entityRecord.Id, componentA)
+	storageB.insert(archetype.Id, entityRecord.Id, componentB)
+	storageC.insert(archetype.Id, entityRecord.Id, componentC)
+	storageD.insert(archetype.Id, entityRecord.Id, componentD)
+	storageE.insert(archetype.Id, entityRecord.Id, componentE)
+	storageF.insert(archetype.Id, entityRecord.Id, componentF)
+	storageG.insert(archetype.Id, entityRecord.Id, componentG)
+	storageH.insert(archetype.Id, entityRecord.Id, componentH)
+
+	// If the entity is not yet in an archetype, simply add it to the archetype
+	if entityRecord.key < 0 {
+		world.setArchetype(entityRecord, archetype)
+	} else {
+		oldArchetype := world.getArchetype(entityRecord)
+		if archetype.Id != oldArchetype.Id {
+			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+			world.setArchetype(entityRecord, archetype)
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package volt
 
 import (
 	"testing"
+	"unsafe"
 )
 
 const (
@@ -81,6 +82,28 @@ func (t testComponent8) GetComponentId() ComponentId {
 	return testComponent8Id
 }
 
+type testSparseComponent struct {
+	testComponent
+}
+
+func (t testSparseComponent) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+// registerTestComponents registers in world testComponent1, testComponent2, the sparse testSparseComponent
+// and the dynamic component "stats", and returns the ComponentId of "stats".
+func registerTestComponents(t *testing.T, world *World) ComponentId {
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testComponent2](world, &ComponentConfig[testComponent2]{})
+	RegisterComponent[testSparseComponent](world, &ComponentConfig[testSparseComponent]{Storage: SPARSE_STORAGE})
+	statsId, err := RegisterDynamicComponent(world, "stats", testDynamicLayout)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	return statsId
+}
+
 func TestAddComponent(t *testing.T) {
 	entities := make([]EntityId, TEST_ENTITY_NUMBER)
 	world := CreateWorld(1024)
@@ -233,3 +256,222 @@ func TestRemoveComponent(t *testing.T) {
 		}
 	}
 }
+
+func TestSparseComponent(t *testing.T) {
+	world := CreateWorld(1024)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testSparseComponent](world, &ComponentConfig[testSparseComponent]{Storage: SPARSE_STORAGE})
+	sparseId := ComponentIdOf[testSparseComponent](world)
+
+	entities := make([]EntityId, TEST_ENTITY_NUMBER)
+	for i := range entities {
+		entities[i] = world.CreateEntity()
+		AddComponent(world, entities[i], testComponent1{testComponent{x: i}})
+	}
+	archetypesCount := len(world.archetypes)
+	archetypeId := world.entities.get(entities[0]).archetypeId
+
+	for i, entityId := range entities {
+		if err := AddComponent(world, entityId, testSparseComponent{testComponent{x: i}}); err != nil {
+			t.Errorf("%s", err.Error())
+		}
+	}
+	if err := AddComponent(world, entities[0], testSparseComponent{}); err == nil {
+		t.Errorf("AddComponent should reject a sparse component already owned")
+	}
+	if len(world.archetypes) != archetypesCount || world.entities.get(entities[0]).archetypeId != archetypeId {
+		t.Errorf("a sparse component should not move the entity across archetypes")
+	}
+
+	for i, entityId := range entities {
+		if !world.HasComponents(entityId, testComponent1Id, sparseId) {
+			t.Errorf("the entity %d should own the sparse component", entityId)
+		}
+		if component := GetComponent[testSparseComponent](world, entityId); component == nil || component.x != i {
+			t.Errorf("the entity %d has an unexpected sparse component %v", entityId, component)
+		}
+	}
+
+	if err := RemoveComponent[testSparseComponent](world, entities[1]); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if err := world.RemoveComponent(entities[1], sparseId); err == nil {
+		t.Errorf("RemoveComponent should reject a sparse component not owned")
+	}
+	if world.HasComponents(entities[1], sparseId) || GetComponent[testSparseComponent](world, entities[1]) != nil {
+		t.Errorf("the sparse component should be removed")
+	}
+	if _, err := world.GetComponent(entities[1], sparseId); err == nil {
+		t.Errorf("GetComponent should reject a sparse component not owned")
+	}
+	if component, err := world.GetComponent(entities[2], sparseId); err != nil || component.(*testSparseComponent).x != 2 {
+		t.Errorf("GetComponent returned %v, %v", component, err)
+	}
+
+	world.RemoveEntity(entities[3])
+	entityId := world.CreateEntity()
+	if world.HasComponents(entityId, sparseId) {
+		t.Errorf("the sparse component should be removed with its entity")
+	}
+
+	if err := world.AddComponent(entityId, sparseId, nil); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if world.entities.get(entityId).archetypeId != 0 || !world.HasComponents(entityId, sparseId) {
+		t.Errorf("the entity without table component should stay in the empty archetype")
+	}
+
+	entityId, err := CreateEntityWithComponents2(world, testComponent1{}, testSparseComponent{testComponent{x: 42}})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if world.entities.get(entityId).archetypeId != archetypeId || GetComponent[testSparseComponent](world, entityId).x != 42 {
+		t.Errorf("CreateEntityWithComponents2 should store the sparse component apart from the archetype")
+	}
+
+	err = RegisterComponent[testSparseComponent](world, &ComponentConfig[testSparseComponent]{})
+	if err == nil {
+		t.Errorf("RegisterComponent should reject a change of StorageStrategy")
+	}
+}
+
+func TestSparseComponent_ReservedRange(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testSparseComponent](world, &ComponentConfig[testSparseComponent]{Storage: SPARSE_STORAGE})
+	entityRange, err := world.ReserveEntityRange(1<<28, 1<<28+10)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	entityId, err := world.CreateEntityInRange(entityRange)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	// Only the page of the entity is allocated, not the indices of all the ids below it.
+	AddComponent(world, entityId, testSparseComponent{testComponent{x: 42}})
+	if component := GetComponent[testSparseComponent](world, entityId); component == nil || component.x != 42 {
+		t.Errorf("the sparse component should be stored on the entity %d, got %v", entityId, component)
+	}
+	sparse := world.storage[ComponentIdOf[testSparseComponent](world)].(*ComponentsStorage[testSparseComponent]).sparse
+	pages := 0
+	for _, page := range sparse.indices {
+		if len(page) > 0 {
+			pages++
+		}
+	}
+	if pages != 1 {
+		t.Errorf("the sparse set should allocate a single page of indices, got %d", pages)
+	}
+
+	RemoveComponent[testSparseComponent](world, entityId)
+	if world.HasComponents(entityId, ComponentIdOf[testSparseComponent](world)) {
+		t.Errorf("the sparse component should be removed")
+	}
+}
+
+func TestWorld_ComponentBinary(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testSparseComponent](world, &ComponentConfig[testSparseComponent]{Storage: SPARSE_STORAGE})
+	statsId, err := RegisterDynamicComponent(world, "stats", DynamicLayout{Size: 4})
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	source := world.CreateEntity()
+	AddComponent(world, source, testComponent1{testComponent{x: 1, y: 2, z: 3}})
+	AddComponent(world, source, testSparseComponent{testComponent{x: 4}})
+	world.AddDynamicComponent(source, statsId, []byte{1, 2, 3, 4})
+
+	destination := world.CreateEntity()
+	for _, componentId := range []ComponentId{testComponent1Id, ComponentIdOf[testSparseComponent](world), statsId} {
+		data, err := world.AppendComponentBinary(nil, source, componentId)
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+
+		// The component is added, then overwritten.
+		for range 2 {
+			if err = world.SetComponentBinary(destination, componentId, data); err != nil {
+				t.Errorf("%s", err.Error())
+			}
+		}
+	}
+
+	if component := GetComponent[testComponent1](world, destination); component == nil || component.testComponent != (testComponent{x: 1, y: 2, z: 3}) {
+		t.Errorf("the component testComponent1 should be decoded, got %v", component)
+	}
+	if component := GetComponent[testSparseComponent](world, destination); component == nil || component.x != 4 {
+		t.Errorf("the component testSparseComponent should be decoded, got %v", component)
+	}
+	if data, _ := world.GetDynamicComponent(destination, statsId); string(data) != string([]byte{1, 2, 3, 4}) {
+		t.Errorf("the dynamic component should be decoded, got %v", data)
+	}
+
+	if _, err = world.AppendComponentBinary(nil, source, testComponent2Id); err == nil {
+		t.Errorf("AppendComponentBinary should reject a component not registered")
+	}
+	if err = world.SetComponentBinary(destination, testComponent1Id, []byte{1}); err == nil {
+		t.Errorf("SetComponentBinary should reject data of the wrong size")
+	}
+	world.RemoveEntity(source)
+	if _, err = world.AppendComponentBinary(nil, source, testComponent1Id); err == nil {
+		t.Errorf("AppendComponentBinary should reject an entity removed")
+	}
+}
+
+// testPaddedComponent has padding bytes between its fields, and at its end.
+type testPaddedComponent struct {
+	flag  uint8
+	value uint32
+	cells [2]struct {
+		kind uint8
+		size uint16
+	}
+	last uint8
+}
+
+func (t testPaddedComponent) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+func TestWorld_ComponentBinary_Padding(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testPaddedComponent](world, &ComponentConfig[testPaddedComponent]{})
+	componentId := ComponentIdOf[testPaddedComponent](world)
+
+	component := testPaddedComponent{flag: 1, value: 2, last: 5}
+	component.cells[1].kind, component.cells[1].size = 3, 4
+	entityA, entityB := world.CreateEntity(), world.CreateEntity()
+	AddComponent(world, entityA, component)
+	AddComponent(world, entityB, component)
+
+	// The padding bytes of the entity B are dirtied: its encoding must not change.
+	memory := unsafe.Slice((*byte)(unsafe.Pointer(GetComponent[testPaddedComponent](world, entityB))), unsafe.Sizeof(component))
+	for _, offset := range []int{1, 2, 3, 9, 13, 17, 18, 19} {
+		memory[offset] = 0xFF
+	}
+
+	dataA, err := world.AppendComponentBinary(nil, entityA, componentId)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	dataB, _ := world.AppendComponentBinary(nil, entityB, componentId)
+	if string(dataA) != string(dataB) {
+		t.Errorf("the padding bytes should not be encoded, got %v and %v", dataA, dataB)
+	}
+	if len(dataA) != 1+4+2*(1+2)+1 {
+		t.Errorf("only the bytes of the fields should be encoded, got %d bytes", len(dataA))
+	}
+
+	destination := world.CreateEntity()
+	if err = world.SetComponentBinary(destination, componentId, dataB); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if decoded := GetComponent[testPaddedComponent](world, destination); *decoded != component {
+		t.Errorf("the component should be decoded, got %v instead of %v", *decoded, component)
+	}
+	if err = world.SetComponentBinary(destination, componentId, make([]byte, unsafe.Sizeof(component))); err == nil {
+		t.Errorf("SetComponentBinary should reject the data holding the padding bytes")
+	}
+}
@@ -0,0 +1,303 @@
+package volt
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ConcurrentWorld wraps a World, so that it can be accessed from several goroutines.
+//
+// The structure of the World (entities, pool, archetypes and storage layout) is guarded by a RWMutex:
+// the structural changes (creating or removing an entity, adding or removing a component or a tag)
+// are serialised, while any number of readers can run alongside.
+// Each component also has its own RWMutex, so that the readers of a component are not blocked
+// by the writers of another one.
+//
+// The callbacks of the World (SetEntityAddedFn, ...) and the functions given to Read, Write and Run
+// are called while the lock is held: they must use the World they receive, and not call the ConcurrentWorld again.
+type ConcurrentWorld struct {
+	world *World
+
+	mu           sync.RWMutex
+	componentsMu []sync.RWMutex
+}
+
+// CreateConcurrentWorld returns a ConcurrentWorld wrapping world.
+//
+// The components should be registered beforehand, or through Write.
+// Once wrapped, the world must only be accessed through the ConcurrentWorld.
+func CreateConcurrentWorld(world *World) *ConcurrentWorld {
+	return &ConcurrentWorld{
+		world:        world,
+		componentsMu: make([]sync.RWMutex, TAGS_INDICES),
+	}
+}
+
+// Read calls fn with the World, locked for reading.
+//
+// fn can run queries and read the components, but must not modify them:
+// see Run to write some components while the others are read.
+func (cw *ConcurrentWorld) Read(fn func(world *World)) {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+
+	fn(cw.world)
+}
+
+// Write calls fn with the World, locked exclusively: fn can apply any change to the World.
+func (cw *ConcurrentWorld) Write(fn func(world *World)) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	fn(cw.world)
+}
+
+// Run calls fn with the World, locked for reading, the components readIds locked for reading
+// and the components writeIds locked for writing.
+//
+// fn can then modify the values of the components writeIds (e.g. within a query), but must not
+// apply any structural change. Two calls of Run proceed concurrently if they do not write the same components:
+// the queries used in fn must then not be shared between goroutines, as each one updates its own cache.
+func (cw *ConcurrentWorld) Run(readIds []ComponentId, writeIds []ComponentId, fn func(world *World)) {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+
+	// The components are locked in ascending order, to prevent any deadlock between two calls.
+	componentsIds := slices.Concat(readIds, writeIds)
+	slices.Sort(componentsIds)
+	componentsIds = slices.Compact(componentsIds)
+
+	for _, componentId := range componentsIds {
+		if componentId >= TAGS_INDICES {
+			continue
+		}
+
+		if slices.Contains(writeIds, componentId) {
+			cw.componentsMu[componentId].Lock()
+			defer cw.componentsMu[componentId].Unlock()
+		} else {
+			cw.componentsMu[componentId].RLock()
+			defer cw.componentsMu[componentId].RUnlock()
+		}
+	}
+
+	fn(cw.world)
+}
+
+// CreateEntity creates a new Entity in World, see World.CreateEntity.
+func (cw *ConcurrentWorld) CreateEntity() EntityId {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.CreateEntity()
+}
+
+// CreateEntityWithId creates a new Entity in World, with the given EntityId, see World.CreateEntityWithId.
+func (cw *ConcurrentWorld) CreateEntityWithId(entityId EntityId) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.CreateEntityWithId(entityId)
+}
+
+// ReserveEntityRange reserves the EntityId [first;last], see World.ReserveEntityRange.
+func (cw *ConcurrentWorld) ReserveEntityRange(first EntityId, last EntityId) (*EntityRange, error) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.ReserveEntityRange(first, last)
+}
+
+// CreateEntityInRange creates a new Entity in World, with an EntityId of the range reserved, see World.CreateEntityInRange.
+func (cw *ConcurrentWorld) CreateEntityInRange(entityRange *EntityRange) (EntityId, error) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.CreateEntityInRange(entityRange)
+}
+
+// RemoveEntity removes all the data related to an Entity, see World.RemoveEntity.
+func (cw *ConcurrentWorld) RemoveEntity(entityId EntityId) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	cw.world.RemoveEntity(entityId)
+}
+
+// Exists reports whether entityId refers to a live entity, see World.Exists.
+func (cw *ConcurrentWorld) Exists(entityId EntityId) bool {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+
+	return cw.world.Exists(entityId)
+}
+
+// Count returns the number of entities in World, see World.Count.
+func (cw *ConcurrentWorld) Count() int {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+
+	return cw.world.Count()
+}
+
+// AddComponent adds the component with ComponentId to the EntityId, see World.AddComponent.
+func (cw *ConcurrentWorld) AddComponent(entityId EntityId, componentId ComponentId, conf any) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.AddComponent(entityId, componentId, conf)
+}
+
+// RemoveComponent removes the component with ComponentId from the EntityId, see World.RemoveComponent.
+func (cw *ConcurrentWorld) RemoveComponent(entityId EntityId, componentId ComponentId) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.RemoveComponent(entityId, componentId)
+}
+
+// HasComponents returns whether the entity has the given variadic list of ComponentId, see World.HasComponents.
+func (cw *ConcurrentWorld) HasComponents(entityId EntityId, componentsIds ...ComponentId) bool {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+
+	return cw.world.HasComponents(entityId, componentsIds...)
+}
+
+// AddTag adds a TagId to a given EntityId, see World.AddTag.
+func (cw *ConcurrentWorld) AddTag(tagId TagId, entityId EntityId) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.AddTag(tagId, entityId)
+}
+
+// RemoveTag removes a Tag for a given EntityId, see World.RemoveTag.
+func (cw *ConcurrentWorld) RemoveTag(tagId TagId, entityId EntityId) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.RemoveTag(tagId, entityId)
+}
+
+// HasTag returns a boolean, to check if an EntityId owns a Tag, see World.HasTag.
+func (cw *ConcurrentWorld) HasTag(tagId TagId, entityId EntityId) bool {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+
+	return cw.world.HasTag(tagId, entityId)
+}
+
+// Snapshot copies the whole state of the World, see World.Snapshot.
+func (cw *ConcurrentWorld) Snapshot() Snapshot {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.Snapshot()
+}
+
+// Restore sets the World back to the state of the snapshot, see World.Restore.
+func (cw *ConcurrentWorld) Restore(snapshot Snapshot) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.Restore(snapshot)
+}
+
+// Clear removes all the entities of the World, see World.Clear.
+func (cw *ConcurrentWorld) Clear(publish bool) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	cw.world.Clear(publish)
+}
+
+// Clone returns an independent copy of the World, see World.Clone.
+//
+// The copy is a World, that is not wrapped.
+func (cw *ConcurrentWorld) Clone() *World {
+	// The World is locked exclusively, as the components may be written through Run meanwhile.
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return cw.world.Clone()
+}
+
+// ConcurrentAddComponent adds the component T to the existing EntityId, see AddComponent.
+func ConcurrentAddComponent[T ComponentInterface](cw *ConcurrentWorld, entityId EntityId, component T) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return AddComponent(cw.world, entityId, component)
+}
+
+// ConcurrentRemoveComponent removes the component T from the EntityId, see RemoveComponent.
+func ConcurrentRemoveComponent[T ComponentInterface](cw *ConcurrentWorld, entityId EntityId) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return RemoveComponent[T](cw.world, entityId)
+}
+
+// ConcurrentGetComponent returns a copy of the component T owned by the entity.
+//
+// Unlike GetComponent, it does not return a pointer, which could be read once the lock is released.
+// The boolean is false if the entity does not have the component.
+func ConcurrentGetComponent[T ComponentInterface](cw *ConcurrentWorld, entityId EntityId) (T, bool) {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+
+	var t T
+	componentId := ComponentIdOf[T](cw.world)
+	if componentId >= TAGS_INDICES {
+		return t, false
+	}
+
+	cw.componentsMu[componentId].RLock()
+	defer cw.componentsMu[componentId].RUnlock()
+
+	component := GetComponent[T](cw.world, entityId)
+	if component == nil {
+		return t, false
+	}
+
+	return *component, true
+}
+
+// ConcurrentSetComponent replaces the value of the component T owned by the entity.
+//
+// A shared component moves the entity to the group of the new value, see SetSharedComponent:
+// the World is then locked exclusively, as for a structural change.
+// It returns an error if the entity does not have the component.
+func ConcurrentSetComponent[T ComponentInterface](cw *ConcurrentWorld, entityId EntityId, component T) error {
+	cw.mu.RLock()
+	componentId := ComponentIdOf[T](cw.world)
+	if componentId >= TAGS_INDICES {
+		cw.mu.RUnlock()
+		return fmt.Errorf("the component %T is not registered", component)
+	}
+
+	// The storage is checked under the lock guarding the write, so that no registration lands in between.
+	if !isConcurrentShared(cw.world, componentId) {
+		defer cw.mu.RUnlock()
+
+		cw.componentsMu[componentId].Lock()
+		defer cw.componentsMu[componentId].Unlock()
+
+		return SetComponent(cw.world, entityId, component)
+	}
+	cw.mu.RUnlock()
+
+	// The exclusive lock guards the write whatever the storage, even if it changed once the read lock released.
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	return SetComponent(cw.world, entityId, component)
+}
+
+// isConcurrentShared reports whether the component componentId is registered with SHARED_STORAGE.
+// The World must be locked by the caller.
+func isConcurrentShared(world *World, componentId ComponentId) bool {
+	return int(componentId) < len(world.storage) && world.storage[componentId] != nil && world.storage[componentId].isShared()
+}
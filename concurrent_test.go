@@ -0,0 +1,139 @@
+package volt
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestConcurrentWorld mixes structural changes, component reads and writes from
+// several goroutines. It is meant to be run with the race detector: go test -race.
+func TestConcurrentWorld(t *testing.T) {
+	world := CreateWorld(1024)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testComponent2](world, &ComponentConfig[testComponent2]{})
+	cw := CreateConcurrentWorld(world)
+
+	const spawners = 4
+	const entitiesCount = 100
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	for s := range spawners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range entitiesCount {
+				entityId := cw.CreateEntity()
+				if err := ConcurrentAddComponent(cw, entityId, testComponent1{testComponent{x: i}}); err != nil {
+					t.Errorf("%s", err.Error())
+				}
+				if i%2 == 0 {
+					if err := cw.AddComponent(entityId, testComponent2Id, nil); err != nil {
+						t.Errorf("%s", err.Error())
+					}
+				}
+				cw.AddTag(TAGS_INDICES+TagId(s), entityId)
+
+				// Remove one entity out of four, once created.
+				if i%4 == 3 {
+					cw.RemoveEntity(entityId)
+				}
+			}
+		}()
+	}
+
+	var readers sync.WaitGroup
+	// Simulation: writes testComponent1 while another system reads testComponent2.
+	readers.Add(3)
+	go func() {
+		defer readers.Done()
+		query := CreateQuery1[testComponent1](world, QueryConfiguration{})
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			cw.Run(nil, []ComponentId{testComponent1Id}, func(world *World) {
+				for result := range query.Foreach(nil) {
+					result.A.y++
+				}
+			})
+		}
+	}()
+	go func() {
+		defer readers.Done()
+		query := CreateQuery2[testComponent1, testComponent2](world, QueryConfiguration{})
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			cw.Run([]ComponentId{testComponent2Id}, nil, func(world *World) {
+				for result := range query.Foreach(nil) {
+					_ = result.B.x
+				}
+			})
+		}
+	}()
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for entityId := range EntityId(cw.Count()) {
+				if component, ok := ConcurrentGetComponent[testComponent2](cw, entityId); ok {
+					ConcurrentSetComponent(cw, entityId, testComponent2{testComponent{x: component.x + 1}})
+				}
+				cw.Exists(entityId)
+				cw.HasComponents(entityId, testComponent1Id, testComponent2Id)
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(done)
+	readers.Wait()
+
+	if count := cw.Count(); count != spawners*entitiesCount*3/4 {
+		t.Errorf("expected %d entities, got %d", spawners*entitiesCount*3/4, count)
+	}
+
+	cw.Read(func(world *World) {
+		query := CreateQuery1[testComponent1](world, QueryConfiguration{Tags: []TagId{TAGS_INDICES}})
+		if query.Count() != entitiesCount*3/4 {
+			t.Errorf("expected %d entities with the tag, got %d", entitiesCount*3/4, query.Count())
+		}
+	})
+
+	entityId := cw.CreateEntity()
+	if _, ok := ConcurrentGetComponent[testComponent1](cw, entityId); ok {
+		t.Errorf("ConcurrentGetComponent should not return a component not owned")
+	}
+	if err := ConcurrentSetComponent(cw, entityId, testComponent1{}); err == nil {
+		t.Errorf("ConcurrentSetComponent should reject a component not owned")
+	}
+	if err := ConcurrentAddComponent(cw, entityId, testComponent1{testComponent{x: 42}}); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if component, ok := ConcurrentGetComponent[testComponent1](cw, entityId); !ok || component.x != 42 {
+		t.Errorf("ConcurrentGetComponent returned %v, %v", component, ok)
+	}
+	if err := ConcurrentRemoveComponent[testComponent1](cw, entityId); err != nil || cw.HasComponents(entityId, testComponent1Id) {
+		t.Errorf("ConcurrentRemoveComponent did not remove the component")
+	}
+	if err := cw.RemoveTag(TAGS_INDICES, entityId); err == nil || cw.HasTag(TAGS_INDICES, entityId) {
+		t.Errorf("the entity should not own the tag")
+	}
+	cw.Write(func(world *World) {
+		world.RemoveEntity(entityId)
+	})
+	if cw.Exists(entityId) {
+		t.Errorf("the entity should be removed")
+	}
+}
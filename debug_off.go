@@ -0,0 +1,26 @@
+//go:build !voltdebug
+
+package volt
+
+// accessGuard is empty without the build tag voltdebug, so that the checks cost nothing.
+type accessGuard struct{}
+
+func (world *World) beginIteration(componentsIds []ComponentId, archetypesIds []archetypeId, task bool) {
+}
+
+func (world *World) endIteration(componentsIds []ComponentId, archetypesIds []archetypeId, task bool) {
+}
+
+func (world *World) guardArchetype(entityId EntityId, archetypeId archetypeId) {}
+
+func (world *World) guardMove(entityRecord entityRecord, archetypeId archetypeId) {}
+
+func (world *World) guardSparse(entityId EntityId, s storage) {}
+
+func (world *World) guardWorldChange() {}
+
+func (world *World) guardWrite(entityId EntityId, componentId ComponentId) {}
+
+func guardYield[T any](world *World, componentsIds []ComponentId, archetypesIds []archetypeId, yield func(T) bool) func(T) bool {
+	return yield
+}
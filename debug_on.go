@@ -0,0 +1,180 @@
+//go:build voltdebug
+
+package volt
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// accessGuard tracks the archetypes and components iterated by the active queries and Task workers,
+// to report the structural changes and the conflicting accesses overlapping them.
+//
+// It is only enabled with the build tag voltdebug: go test -tags voltdebug ./...
+type accessGuard struct {
+	mu         sync.Mutex
+	active     int
+	archetypes map[archetypeId]int
+	iterations map[ComponentId]int
+	tasks      map[ComponentId]int
+}
+
+// beginIteration records an iteration over the components componentsIds of the archetypes archetypesIds,
+// by a query iterator or by Task workers.
+//
+// A query iterator starting over components iterated by Task workers panics, as its pointers would race with their writes.
+func (world *World) beginIteration(componentsIds []ComponentId, archetypesIds []archetypeId, task bool) {
+	guard := &world.guard
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	if guard.iterations == nil {
+		guard.archetypes = make(map[archetypeId]int)
+		guard.iterations = make(map[ComponentId]int)
+		guard.tasks = make(map[ComponentId]int)
+	}
+
+	if !task {
+		for _, componentId := range componentsIds {
+			if guard.tasks[componentId] > 0 {
+				panic(fmt.Sprintf("volt: query over the component %s while Task workers iterate it",
+					world.componentName(componentId)))
+			}
+		}
+	}
+
+	guard.active++
+	for _, archetypeId := range archetypesIds {
+		guard.archetypes[archetypeId]++
+	}
+	for _, componentId := range componentsIds {
+		guard.iterations[componentId]++
+		if task {
+			guard.tasks[componentId]++
+		}
+	}
+}
+
+// endIteration releases an iteration recorded by beginIteration.
+func (world *World) endIteration(componentsIds []ComponentId, archetypesIds []archetypeId, task bool) {
+	guard := &world.guard
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	guard.active--
+	for _, archetypeId := range archetypesIds {
+		guard.archetypes[archetypeId]--
+	}
+	for _, componentId := range componentsIds {
+		guard.iterations[componentId]--
+		if task {
+			guard.tasks[componentId]--
+		}
+	}
+}
+
+// guardYield returns yield releasing the iteration recorded by beginIteration if the loop body panics,
+// as the query iterators end their iterations without defer so that they can be inlined.
+func guardYield[T any](world *World, componentsIds []ComponentId, archetypesIds []archetypeId, yield func(T) bool) func(T) bool {
+	return func(result T) bool {
+		returned := false
+		defer func() {
+			if !returned {
+				world.endIteration(componentsIds, archetypesIds, false)
+			}
+		}()
+
+		next := yield(result)
+		returned = true
+		return next
+	}
+}
+
+// guardArchetype panics if the rows of the archetype are about to change, as entityId is added to it
+// or removed from it, while a query iterates the archetype.
+func (world *World) guardArchetype(entityId EntityId, archetypeId archetypeId) {
+	guard := &world.guard
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	if guard.archetypes[archetypeId] == 0 {
+		return
+	}
+
+	panic(fmt.Sprintf("volt: structural change on the entity %d while a query iterates its archetype %v: defer the change after the iteration",
+		entityId, world.componentsNames(world.archetypes[archetypeId].Type...)))
+}
+
+// guardMove guards the archetypes the entity leaves and joins, unless it is live and stays in the same archetype.
+func (world *World) guardMove(entityRecord entityRecord, archetypeId archetypeId) {
+	if entityRecord.key >= 0 && entityRecord.archetypeId == archetypeId {
+		return
+	}
+
+	world.guardArchetype(entityRecord.Id, archetypeId)
+	if entityRecord.key >= 0 {
+		world.guardArchetype(entityRecord.Id, entityRecord.archetypeId)
+	}
+}
+
+// guardSparse panics if the sparse component s of entityId is about to be added or removed while a query iterates it.
+func (world *World) guardSparse(entityId EntityId, s storage) {
+	if !s.isSparse() {
+		return
+	}
+
+	guard := &world.guard
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	componentId := s.getType()
+	if guard.iterations[componentId] == 0 {
+		return
+	}
+
+	panic(fmt.Sprintf("volt: structural change on the entity %d while a query iterates the sparse component %s: defer the change after the iteration",
+		entityId, world.componentName(componentId)))
+}
+
+// guardWorldChange panics if the whole World is about to change, e.g. compacted or restored, while a query iterates it.
+func (world *World) guardWorldChange() {
+	guard := &world.guard
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	if guard.active == 0 {
+		return
+	}
+
+	panic(fmt.Sprintf("volt: change of the whole World while a query iterates the components %v: defer the change after the iteration",
+		world.componentsNames(activeComponents(guard.iterations)...)))
+}
+
+// guardWrite panics if the component of entityId is about to be written, or a pointer to it returned,
+// while Task workers iterate it.
+func (world *World) guardWrite(entityId EntityId, componentId ComponentId) {
+	guard := &world.guard
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	if guard.tasks[componentId] == 0 {
+		return
+	}
+
+	panic(fmt.Sprintf("volt: write of the component %s on the entity %d while Task workers iterate it",
+		world.componentName(componentId), entityId))
+}
+
+// activeComponents returns the components with a positive count.
+func activeComponents(counts map[ComponentId]int) []ComponentId {
+	var componentsIds []ComponentId
+	for componentId, count := range counts {
+		if count > 0 {
+			componentsIds = append(componentsIds, componentId)
+		}
+	}
+	slices.Sort(componentsIds)
+
+	return componentsIds
+}
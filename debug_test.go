@@ -0,0 +1,151 @@
+//go:build voltdebug
+
+package volt
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// expectPanic fails the test if fn does not panic with a message containing expected.
+func expectPanic(t *testing.T, expected string, fn func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		recovered := recover()
+		if recovered == nil {
+			t.Errorf("expected a panic containing %q", expected)
+		} else if message, _ := recovered.(string); !strings.Contains(message, expected) {
+			t.Errorf("expected a panic containing %q, got %v", expected, recovered)
+		}
+	}()
+
+	fn()
+}
+
+func TestAccessGuard_StructuralChange(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testComponent2](world, &ComponentConfig[testComponent2]{})
+	RegisterComponent[testSparseComponent](world, &ComponentConfig[testSparseComponent]{Storage: SPARSE_STORAGE})
+	for range 10 {
+		entityId := world.CreateEntity()
+		AddComponent(world, entityId, testComponent1{})
+	}
+	query := CreateQuery1[testComponent1](world, QueryConfiguration{})
+
+	for name, test := range map[string]struct {
+		change   func(entityId EntityId)
+		expected string
+	}{
+		"RemoveEntity":    {func(entityId EntityId) { world.RemoveEntity(entityId) }, "structural change"},
+		"AddComponent":    {func(entityId EntityId) { AddComponent(world, entityId, testComponent2{}) }, "structural change"},
+		"RemoveComponent": {func(entityId EntityId) { RemoveComponent[testComponent1](world, entityId) }, "structural change"},
+		"AddTag":          {func(entityId EntityId) { world.AddTag(TAGS_INDICES, entityId) }, "structural change"},
+		"Compact":         {func(entityId EntityId) { world.Compact() }, "change of the whole World"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			expectPanic(t, test.expected, func() {
+				for result := range query.Foreach(nil) {
+					test.change(result.EntityId)
+				}
+			})
+		})
+	}
+
+	// The changes outside of the archetypes and components iterated are allowed.
+	for result := range query.Foreach(nil) {
+		entityId := world.CreateEntity()
+		if err := AddComponent(world, entityId, testComponent2{}); err != nil {
+			t.Errorf("%s", err.Error())
+		}
+		if err := AddComponent(world, result.EntityId, testSparseComponent{}); err != nil {
+			t.Errorf("%s", err.Error())
+		}
+	}
+
+	// A sparse component iterated can't be added or removed, whatever the archetype of the entity.
+	sparseQuery := CreateQuery1[testSparseComponent](world, QueryConfiguration{})
+	otherId := world.CreateEntity()
+	AddComponent(world, otherId, testComponent2{})
+	expectPanic(t, "sparse component", func() {
+		for range sparseQuery.Foreach(nil) {
+			AddComponent(world, otherId, testSparseComponent{})
+		}
+	})
+	expectPanic(t, "structural change", func() {
+		for result := range sparseQuery.Foreach(nil) {
+			world.RemoveEntity(result.EntityId)
+		}
+	})
+
+	// The iteration is released once the loop is left, even after a panic.
+	for result := range query.Foreach(nil) {
+		result.A.x++
+		break
+	}
+	entityId := world.CreateEntity()
+	if err := AddComponent(world, entityId, testComponent2{}); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+}
+
+func TestAccessGuard_TaskWrite(t *testing.T) {
+	world := CreateWorld(16)
+	statsId, _ := RegisterDynamicComponent(world, "stats", testDynamicLayout)
+	for range 4 {
+		entityId := world.CreateEntity()
+		world.AddDynamicComponent(entityId, statsId, nil)
+	}
+	entityId := EntityId(0)
+
+	// A write from the iterating goroutine is allowed.
+	query := CreateDynamicQuery(world, QueryConfiguration{}, statsId)
+	for result := range query.Foreach(nil) {
+		if err := SetDynamicField[float32](world, result.EntityId, statsId, "health", 1); err != nil {
+			t.Errorf("%s", err.Error())
+		}
+	}
+
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	AddComponent(world, entityId, testComponent1{})
+	typedQuery := CreateQuery1[testComponent1](world, QueryConfiguration{})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		typedQuery.Task(1, nil, func(result QueryResult1[testComponent1]) {
+			close(started)
+			<-release
+		})
+	}()
+	<-started
+
+	// Task workers iterate testComponent1, not stats: the write is allowed.
+	if err := world.SetDynamicComponent(entityId, statsId, make([]byte, 12)); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	expectPanic(t, "while Task workers iterate it", func() {
+		ConcurrentSetComponent(CreateConcurrentWorld(world), entityId, testComponent1{})
+	})
+	expectPanic(t, "while Task workers iterate it", func() {
+		GetComponent[testComponent1](world, entityId)
+	})
+	expectPanic(t, "while Task workers iterate it", func() {
+		for range typedQuery.Foreach(nil) {
+		}
+	})
+	expectPanic(t, "structural change", func() {
+		world.RemoveEntity(entityId)
+	})
+	close(release)
+	wg.Wait()
+
+	if err := ConcurrentSetComponent(CreateConcurrentWorld(world), entityId, testComponent1{}); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+}
@@ -0,0 +1,130 @@
+package volt
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SetDeterministic sets whether the rows of each archetype are kept in the order of their EntityId,
+// so that the iteration order does not depend on the history of the World, e.g. for lockstep simulations.
+//
+// By default, removing an entity or moving it to another archetype swaps the last row of the archetype into
+// its slot, and the order of iteration depends on the removals. In deterministic mode the following rows are
+// shifted instead, and the rows added are inserted at the position of their EntityId: the structural changes
+// cost O(n) in the size of the archetype, the iteration is unchanged.
+//
+// The archetypes are iterated in their order of creation, identical for two worlds fed the same commands.
+// Foreach and ForeachSorted follow this order, while Task dispatches the rows across its workers.
+// SortArchetypes has no effect on a deterministic World.
+func (world *World) SetDeterministic(deterministic bool) {
+	world.guardWorldChange()
+	world.deterministic = deterministic
+
+	if deterministic {
+		world.sortArchetypesByEntityId()
+	}
+}
+
+// IsDeterministic reports whether the rows of the archetypes are kept in the order of their EntityId.
+func (world *World) IsDeterministic() bool {
+	return world.deterministic
+}
+
+// sortArchetypesByEntityId sorts the rows of every archetype by EntityId, e.g. once the mode is enabled.
+func (world *World) sortArchetypesByEntityId() {
+	var permutation []int
+	var visited []bool
+	for i := range world.archetypes {
+		archetype := &world.archetypes[i]
+		if slices.IsSorted(archetype.entities) {
+			continue
+		}
+
+		permutation = permutation[:0]
+		for key := range archetype.entities {
+			permutation = append(permutation, key)
+		}
+		slices.SortFunc(permutation, func(a, b int) int {
+			return cmp.Compare(archetype.entities[a], archetype.entities[b])
+		})
+
+		visited = resizeVisited(visited, len(archetype.entities))
+		world.permuteArchetype(archetype, permutation, visited)
+	}
+}
+
+// insertRow moves the last row of the archetype, just added, to the position of its EntityId in deterministic mode.
+func (world *World) insertRow(archetype *archetype) {
+	if !world.deterministic {
+		return
+	}
+
+	last := len(archetype.entities) - 1
+	key, _ := slices.BinarySearch(archetype.entities[:last], archetype.entities[last])
+	world.moveRow(archetype, last, key)
+}
+
+// detachRow moves the row of the entity to the end of its archetype in deterministic mode,
+// so that the swap-remove which follows keeps the order of the other rows. It returns the record updated.
+func (world *World) detachRow(entityRecord entityRecord) entityRecord {
+	if !world.deterministic {
+		return entityRecord
+	}
+
+	archetype := &world.archetypes[entityRecord.archetypeId]
+	world.moveRow(archetype, entityRecord.key, len(archetype.entities)-1)
+
+	return world.entities.get(entityRecord.Id)
+}
+
+// moveRow moves the row from to the position to, shifting the rows in between, and updates the keys of their entities.
+func (world *World) moveRow(archetype *archetype, from int, to int) {
+	if from == to {
+		return
+	}
+
+	for _, componentId := range archetype.Type {
+		if componentId < TAGS_INDICES && world.storage[componentId] != nil {
+			world.storage[componentId].moveRow(archetype.Id, from, to)
+		}
+	}
+	moveRow(archetype.entities, from, to)
+
+	for key := min(from, to); key <= max(from, to); key++ {
+		world.entities.at(archetype.entities[key]).key = key
+	}
+}
+
+// moveRow moves column[from] to column[to], shifting the values in between.
+func moveRow[T any](column []T, from int, to int) {
+	value := column[from]
+	if from < to {
+		copy(column[from:to], column[from+1:to+1])
+	} else {
+		copy(column[to+1:from+1], column[to:from])
+	}
+	column[to] = value
+}
+
+func (c *ComponentsStorage[T]) moveRow(archetypeId archetypeId, from int, to int) {
+	if c.shared != nil {
+		return
+	}
+
+	moveRow(c.getColumn(archetypeId), from, to)
+}
+
+// moveRow rotates the bytes of the rows between from and to, without a buffer.
+func (c *dynamicStorage) moveRow(archetypeId archetypeId, from int, to int) {
+	column := c.getColumn(archetypeId)
+	rows := column[min(from, to)*c.stride : (max(from, to)+1)*c.stride]
+
+	// Rotating to the left moves the first row to the end, to the right the last row to the start.
+	shift := c.stride
+	if from > to {
+		shift = len(rows) - c.stride
+	}
+	slices.Reverse(rows[:shift])
+	slices.Reverse(rows[shift:])
+	slices.Reverse(rows)
+}
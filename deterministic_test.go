@@ -0,0 +1,206 @@
+package volt
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// applyCommands feeds the world with random structural changes, the same for the same seed.
+func applyCommands(world *World, statsId ComponentId, seed uint64, count int) {
+	random := rand.New(rand.NewPCG(seed, 0))
+	var entitiesIds []EntityId
+
+	for range count {
+		if len(entitiesIds) == 0 || random.IntN(3) == 0 {
+			entityId := world.CreateEntity()
+			AddComponent(world, entityId, testComponent1{testComponent{x: int(entityId)}})
+			world.AddDynamicComponent(entityId, statsId, nil)
+			SetDynamicField[float32](world, entityId, statsId, "health", float32(entityId)/3)
+			entitiesIds = append(entitiesIds, entityId)
+			continue
+		}
+
+		i := random.IntN(len(entitiesIds))
+		entityId := entitiesIds[i]
+		switch random.IntN(5) {
+		case 0:
+			world.RemoveEntity(entityId)
+			entitiesIds = slices.Delete(entitiesIds, i, i+1)
+		case 1:
+			if AddComponent(world, entityId, testComponent2{}) != nil {
+				RemoveComponent[testComponent2](world, entityId)
+			}
+		case 2:
+			if world.AddTag(TAG_1, entityId) != nil {
+				world.RemoveTag(TAG_1, entityId)
+			}
+		case 3:
+			if AddComponent(world, entityId, testSparseComponent{}) != nil {
+				RemoveComponent[testSparseComponent](world, entityId)
+			}
+		case 4:
+			if world.RemoveComponent(entityId, statsId) != nil {
+				world.AddDynamicComponent(entityId, statsId, nil)
+				SetDynamicField[float32](world, entityId, statsId, "health", float32(entityId)/3)
+			}
+		}
+	}
+}
+
+// iterationOrder returns the EntityId in the order of Foreach, and checks that the components belong to their entity.
+func iterationOrder(t *testing.T, world *World, statsId ComponentId) []EntityId {
+	t.Helper()
+
+	var entitiesIds []EntityId
+	query := CreateQuery1[testComponent1](world, QueryConfiguration{})
+	for result := range query.Foreach(nil) {
+		entitiesIds = append(entitiesIds, result.EntityId)
+		if result.A.x != int(result.EntityId) {
+			t.Errorf("the component of the entity %d should be its own, got %d", result.EntityId, result.A.x)
+		}
+		if health, err := GetDynamicField[float32](world, result.EntityId, statsId, "health"); err == nil && health != float32(result.EntityId)/3 {
+			t.Errorf("the dynamic component of the entity %d should be its own, got %v", result.EntityId, health)
+		}
+	}
+
+	return entitiesIds
+}
+
+// accumulate sums the floats in the order of iteration, whose result depends on the order.
+func accumulate(world *World, statsId ComponentId) float32 {
+	var sum float32
+	query := CreateDynamicQuery(world, QueryConfiguration{}, statsId)
+	for result := range query.Foreach(nil) {
+		health, _ := GetDynamicField[float32](world, result.EntityId, statsId, "health")
+		sum += health * 1.1
+	}
+
+	return sum
+}
+
+func assertOrderedByEntityId(t *testing.T, world *World) {
+	t.Helper()
+
+	for _, archetype := range world.archetypes {
+		if !slices.IsSorted(archetype.entities) {
+			t.Errorf("the entities of the archetype %d should be ordered by EntityId, got %v", archetype.Id, archetype.entities)
+		}
+		for key, entityId := range archetype.entities {
+			if world.entities.get(entityId).key != key {
+				t.Errorf("the record of the entity %d should point to the row %d, got %d", entityId, key, world.entities.get(entityId).key)
+			}
+		}
+	}
+}
+
+func TestWorld_SetDeterministic(t *testing.T) {
+	a := CreateWorld(16)
+	statsId := registerTestComponents(t, a)
+	a.SetDeterministic(true)
+	b := CreateWorld(16)
+	registerTestComponents(t, b)
+	b.SetDeterministic(true)
+	applyCommands(a, statsId, 42, 2000)
+	applyCommands(b, statsId, 42, 2000)
+
+	assertOrderedByEntityId(t, a)
+	order := iterationOrder(t, a, statsId)
+	if len(order) == 0 || !slices.Equal(order, iterationOrder(t, b, statsId)) {
+		t.Errorf("two worlds fed the same commands should iterate in the same order")
+	}
+	if accumulate(a, statsId) != accumulate(b, statsId) {
+		t.Errorf("two worlds fed the same commands should accumulate the same floats")
+	}
+
+	// The order does not depend on the order of the removals.
+	c := CreateWorld(16)
+	registerTestComponents(t, c)
+	c.SetDeterministic(true)
+	d := CreateWorld(16)
+	registerTestComponents(t, d)
+	d.SetDeterministic(true)
+	for _, world := range []*World{c, d} {
+		for i := range 20 {
+			entityId := world.CreateEntity()
+			AddComponent(world, entityId, testComponent1{testComponent{x: i}})
+			world.AddDynamicComponent(entityId, statsId, nil)
+			SetDynamicField[float32](world, entityId, statsId, "health", float32(entityId)/3)
+		}
+	}
+	for i := 1; i < 20; i += 3 {
+		c.RemoveEntity(EntityId(i))
+		d.RemoveEntity(EntityId(20 - i))
+	}
+	for i := 1; i < 20; i += 3 {
+		c.RemoveEntity(EntityId(20 - i))
+		d.RemoveEntity(EntityId(i))
+	}
+	assertOrderedByEntityId(t, c)
+	if !slices.Equal(iterationOrder(t, c, statsId), iterationOrder(t, d, statsId)) {
+		t.Errorf("the order should not depend on the history of the removals")
+	}
+
+	// Without the mode, the order depends on the history.
+	e := CreateWorld(16)
+	registerTestComponents(t, e)
+	f := CreateWorld(16)
+	registerTestComponents(t, f)
+	for _, world := range []*World{e, f} {
+		for i := range 4 {
+			AddComponent(world, world.CreateEntity(), testComponent1{testComponent{x: i}})
+		}
+	}
+	e.RemoveEntity(0)
+	e.RemoveEntity(1)
+	f.RemoveEntity(1)
+	f.RemoveEntity(0)
+	if slices.Equal(iterationOrder(t, e, statsId), iterationOrder(t, f, statsId)) {
+		t.Errorf("the default order should follow the swap-removes")
+	}
+}
+
+func TestWorld_SetDeterministic_Enable(t *testing.T) {
+	world := CreateWorld(16)
+	statsId := registerTestComponents(t, world)
+	applyCommands(world, statsId, 7, 500)
+	reference := CreateWorld(16)
+	registerTestComponents(t, reference)
+	reference.SetDeterministic(true)
+	applyCommands(reference, statsId, 7, 500)
+
+	// Enabling the mode sorts the rows already added.
+	world.SetDeterministic(true)
+	if !world.IsDeterministic() {
+		t.Errorf("the world should be deterministic")
+	}
+	assertOrderedByEntityId(t, world)
+	if !slices.Equal(iterationOrder(t, world, statsId), iterationOrder(t, reference, statsId)) {
+		t.Errorf("the order should be the same as a world deterministic from the start")
+	}
+
+	// A snapshot taken before the mode is restored in order.
+	other := CreateWorld(16)
+	registerTestComponents(t, other)
+	applyCommands(other, statsId, 9, 500)
+	snapshot := other.Snapshot()
+	other.SetDeterministic(true)
+	applyCommands(other, statsId, 10, 100)
+	if err := other.Restore(snapshot); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	assertOrderedByEntityId(t, other)
+
+	// The clone keeps the mode, and SortArchetypes does not break the order.
+	clone := world.Clone()
+	applyCommands(clone, statsId, 11, 500)
+	if !clone.IsDeterministic() {
+		t.Errorf("the clone should be deterministic")
+	}
+	query := CreateQuery1[testComponent1](clone, QueryConfiguration{})
+	query.SortArchetypes(func(a, b QueryResult1[testComponent1]) bool {
+		return a.EntityId > b.EntityId
+	})
+	assertOrderedByEntityId(t, clone)
+	iterationOrder(t, clone, statsId)
+}
@@ -0,0 +1,266 @@
+package volt
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// WorldDiff reports the differences between two Worlds a and b, see Diff.
+//
+// The entities are matched by EntityId, and the components by name.
+type WorldDiff struct {
+	// MissingEntities are the entities of a, missing in b.
+	MissingEntities []EntityId
+	// ExtraEntities are the entities of b, missing in a.
+	ExtraEntities []EntityId
+	// MissingArchetypes are the archetypes with entities in a and not in b, as the names of their components and tags.
+	MissingArchetypes [][]string
+	// ExtraArchetypes are the archetypes with entities in b and not in a.
+	ExtraArchetypes [][]string
+	// Entities are the differences of the entities existing in both Worlds.
+	Entities []EntityDiff
+}
+
+// EntityDiff reports the differences of an entity existing in two Worlds a and b.
+type EntityDiff struct {
+	EntityId EntityId
+	// MissingComponents are the names of the components of the entity in a, missing in b.
+	MissingComponents []string
+	// ExtraComponents are the names of the components of the entity in b, missing in a.
+	ExtraComponents []string
+	MissingTags     []TagId
+	ExtraTags       []TagId
+	// Components are the components of the entity with different values.
+	Components []ComponentDiff
+}
+
+// ComponentDiff reports a component with different values in two Worlds a and b.
+type ComponentDiff struct {
+	Name string
+	A    any
+	B    any
+}
+
+// Diff returns the differences between the Worlds a and b, e.g. to find where a replay diverges:
+// the entities missing or extra, the archetypes, the components and tags of each entity, and the values of the components.
+//
+// The values are compared with the EqualFn of the ComponentConfig in a if set, or else with reflect.DeepEqual.
+func Diff(a *World, b *World) WorldDiff {
+	var diff WorldDiff
+
+	entitiesA := slices.Collect(a.Entities())
+	entitiesB := slices.Collect(b.Entities())
+	i, j := 0, 0
+	for i < len(entitiesA) || j < len(entitiesB) {
+		switch {
+		case j == len(entitiesB) || (i < len(entitiesA) && entitiesA[i] < entitiesB[j]):
+			diff.MissingEntities = append(diff.MissingEntities, entitiesA[i])
+			i++
+		case i == len(entitiesA) || entitiesB[j] < entitiesA[i]:
+			diff.ExtraEntities = append(diff.ExtraEntities, entitiesB[j])
+			j++
+		default:
+			if entityDiff := diffEntity(a, b, entitiesA[i]); !entityDiff.Empty() {
+				diff.Entities = append(diff.Entities, entityDiff)
+			}
+			i++
+			j++
+		}
+	}
+
+	archetypesA := a.populatedArchetypes()
+	archetypesB := b.populatedArchetypes()
+	for key, names := range archetypesA {
+		if _, ok := archetypesB[key]; !ok {
+			diff.MissingArchetypes = append(diff.MissingArchetypes, names)
+		}
+	}
+	for key, names := range archetypesB {
+		if _, ok := archetypesA[key]; !ok {
+			diff.ExtraArchetypes = append(diff.ExtraArchetypes, names)
+		}
+	}
+	slices.SortFunc(diff.MissingArchetypes, slices.Compare)
+	slices.SortFunc(diff.ExtraArchetypes, slices.Compare)
+
+	return diff
+}
+
+func diffEntity(a *World, b *World, entityId EntityId) EntityDiff {
+	entityDiff := EntityDiff{EntityId: entityId}
+
+	componentsA, tagsA := a.entityComponents(entityId)
+	componentsB, tagsB := b.entityComponents(entityId)
+	for _, name := range sortedKeys(componentsA) {
+		componentIdB, ok := componentsB[name]
+		if !ok {
+			entityDiff.MissingComponents = append(entityDiff.MissingComponents, name)
+			continue
+		}
+
+		componentA, _ := a.GetComponent(entityId, componentsA[name])
+		componentB, _ := b.GetComponent(entityId, componentIdB)
+		if !a.componentsRegistry[componentsA[name]].equalComponents(componentA, componentB) {
+			entityDiff.Components = append(entityDiff.Components, ComponentDiff{
+				Name: name,
+				A:    componentValue(componentA),
+				B:    componentValue(componentB),
+			})
+		}
+	}
+	for _, name := range sortedKeys(componentsB) {
+		if _, ok := componentsA[name]; !ok {
+			entityDiff.ExtraComponents = append(entityDiff.ExtraComponents, name)
+		}
+	}
+
+	for _, tagId := range tagsA {
+		if !slices.Contains(tagsB, tagId) {
+			entityDiff.MissingTags = append(entityDiff.MissingTags, tagId)
+		}
+	}
+	for _, tagId := range tagsB {
+		if !slices.Contains(tagsA, tagId) {
+			entityDiff.ExtraTags = append(entityDiff.ExtraTags, tagId)
+		}
+	}
+
+	return entityDiff
+}
+
+// entityComponents returns the ComponentId of the components of the entity by name, and its tags.
+func (world *World) entityComponents(entityId EntityId) (map[string]ComponentId, []TagId) {
+	components := make(map[string]ComponentId)
+	var tags []TagId
+	for _, componentId := range world.archetypes[world.entities.get(entityId).archetypeId].Type {
+		if componentId >= SHARED_INDICES {
+			continue
+		} else if componentId >= TAGS_INDICES {
+			tags = append(tags, componentId)
+		} else {
+			components[world.componentsRegistry[componentId].getInfo().Name] = componentId
+		}
+	}
+	for _, componentId := range world.sparseComponentsIds {
+		if world.storage[componentId].hasEntity(entityId) {
+			components[world.componentsRegistry[componentId].getInfo().Name] = componentId
+		}
+	}
+	slices.Sort(tags)
+
+	return components, tags
+}
+
+// populatedArchetypes returns the names of the components and tags of each archetype with entities,
+// keyed by their concatenation.
+func (world *World) populatedArchetypes() map[string][]string {
+	archetypes := make(map[string][]string)
+	for _, archetype := range world.archetypes {
+		if len(archetype.entities) == 0 {
+			continue
+		}
+
+		names := make([]string, len(archetype.Type))
+		for i, componentId := range archetype.Type {
+			if componentId >= SHARED_INDICES {
+				names[i] = world.sharedGroupName(archetype.Id, componentId)
+			} else if componentId >= TAGS_INDICES {
+				names[i] = fmt.Sprintf("tag(%d)", componentId)
+			} else {
+				names[i] = world.componentsRegistry[componentId].getInfo().Name
+			}
+		}
+		slices.Sort(names)
+		archetypes[strings.Join(names, ",")] = names
+	}
+
+	return archetypes
+}
+
+// sharedGroupName returns the name of the shared component of the group, with its value.
+func (world *World) sharedGroupName(archetypeId archetypeId, groupId ComponentId) string {
+	group := world.sharedGroups[groupId-SHARED_INDICES]
+
+	return fmt.Sprintf("%s=%v", world.componentName(group.componentId), componentValue(world.storage[group.componentId].get(archetypeId, 0)))
+}
+
+func sortedKeys(components map[string]ComponentId) []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// componentValue returns the value of a component returned by World.GetComponent: a *T is dereferenced.
+func componentValue(component any) any {
+	if data, ok := component.([]byte); ok {
+		return bytes.Clone(data)
+	}
+
+	value := reflect.ValueOf(component)
+	if value.Kind() == reflect.Pointer && !value.IsNil() {
+		return value.Elem().Interface()
+	}
+
+	return component
+}
+
+// Empty reports whether the Worlds compared are identical.
+func (diff WorldDiff) Empty() bool {
+	return len(diff.MissingEntities) == 0 && len(diff.ExtraEntities) == 0 &&
+		len(diff.MissingArchetypes) == 0 && len(diff.ExtraArchetypes) == 0 && len(diff.Entities) == 0
+}
+
+// Empty reports whether the entity is identical in the Worlds compared.
+func (entityDiff EntityDiff) Empty() bool {
+	return len(entityDiff.MissingComponents) == 0 && len(entityDiff.ExtraComponents) == 0 &&
+		len(entityDiff.MissingTags) == 0 && len(entityDiff.ExtraTags) == 0 && len(entityDiff.Components) == 0
+}
+
+// String formats the differences one per line, e.g. for the failure of a test.
+func (diff WorldDiff) String() string {
+	if diff.Empty() {
+		return "no difference"
+	}
+
+	var builder strings.Builder
+	if len(diff.MissingEntities) > 0 {
+		fmt.Fprintf(&builder, "entities missing in b: %v\n", diff.MissingEntities)
+	}
+	if len(diff.ExtraEntities) > 0 {
+		fmt.Fprintf(&builder, "entities extra in b: %v\n", diff.ExtraEntities)
+	}
+	for _, names := range diff.MissingArchetypes {
+		fmt.Fprintf(&builder, "archetype %v missing in b\n", names)
+	}
+	for _, names := range diff.ExtraArchetypes {
+		fmt.Fprintf(&builder, "archetype %v extra in b\n", names)
+	}
+
+	for _, entityDiff := range diff.Entities {
+		fmt.Fprintf(&builder, "entity %d:\n", entityDiff.EntityId)
+		for _, name := range entityDiff.MissingComponents {
+			fmt.Fprintf(&builder, "  component %s missing in b\n", name)
+		}
+		for _, name := range entityDiff.ExtraComponents {
+			fmt.Fprintf(&builder, "  component %s extra in b\n", name)
+		}
+		for _, tagId := range entityDiff.MissingTags {
+			fmt.Fprintf(&builder, "  tag %d missing in b\n", tagId)
+		}
+		for _, tagId := range entityDiff.ExtraTags {
+			fmt.Fprintf(&builder, "  tag %d extra in b\n", tagId)
+		}
+		for _, componentDiff := range entityDiff.Components {
+			fmt.Fprintf(&builder, "  component %s: %+v != %+v\n", componentDiff.Name, componentDiff.A, componentDiff.B)
+		}
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,114 @@
+package volt
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := CreateWorld(16)
+	statsIdA := registerTestComponents(t, a)
+	RegisterComponent[testAutoComponent1](a, &ComponentConfig[testAutoComponent1]{})
+	RegisterComponent[testInventoryComponent](a, &ComponentConfig[testInventoryComponent]{})
+	// The components are registered in another order, so that their ComponentId differ.
+	b := CreateWorld(16)
+	RegisterComponent[testInventoryComponent](b, &ComponentConfig[testInventoryComponent]{})
+	RegisterComponent[testAutoComponent1](b, &ComponentConfig[testAutoComponent1]{})
+	statsIdB := registerTestComponents(t, b)
+	for i := range 5 {
+		createTransferEntity(a, statsIdA, i)
+		createTransferEntity(b, statsIdB, i)
+	}
+
+	// The components are matched by name, whatever their ComponentId.
+	if diff := Diff(a, b); !diff.Empty() {
+		t.Fatalf("the worlds should be identical, got:\n%s", diff)
+	}
+
+	a.RemoveEntity(1)
+	b.CreateEntity()
+	GetComponent[testAutoComponent1](b, 2).value = 20
+	GetComponent[testInventoryComponent](b, 2).items[0] = "shield"
+	SetDynamicField[float32](b, 3, statsIdB, "health", 30)
+	RemoveComponent[testSparseComponent](b, 3)
+	b.RemoveTag(TAG_1, 4)
+	b.AddTag(TAG_2, 4)
+
+	diff := Diff(a, b)
+	if len(diff.MissingEntities) != 0 {
+		t.Errorf("no entity should be missing in b, got %v", diff.MissingEntities)
+	}
+	if !slices.Equal(diff.ExtraEntities, []EntityId{1, 5}) {
+		t.Errorf("the entities 1 and 5 should be extra in b, got %v", diff.ExtraEntities)
+	}
+	if len(diff.MissingArchetypes) != 0 || len(diff.ExtraArchetypes) != 2 || len(diff.ExtraArchetypes[0]) != 0 {
+		t.Errorf("the archetypes of the entities 4 and 5 should be extra in b, got %v %v", diff.MissingArchetypes, diff.ExtraArchetypes)
+	}
+	if len(diff.Entities) != 3 {
+		t.Fatalf("the entities 2, 3 and 4 should differ, got:\n%s", diff)
+	}
+
+	entityDiff := diff.Entities[0]
+	if entityDiff.EntityId != 2 || len(entityDiff.Components) != 2 {
+		t.Fatalf("the entity 2 should differ by 2 components, got %+v", entityDiff)
+	}
+	if componentDiff := entityDiff.Components[0]; componentDiff.Name != "github.com/akmonengine/volt.testAutoComponent1" ||
+		componentDiff.A.(testAutoComponent1).value != 2 || componentDiff.B.(testAutoComponent1).value != 20 {
+		t.Errorf("the values of testAutoComponent1 should be reported, got %+v", componentDiff)
+	}
+
+	entityDiff = diff.Entities[1]
+	if entityDiff.EntityId != 3 || !slices.Equal(entityDiff.MissingComponents, []string{"github.com/akmonengine/volt.testSparseComponent"}) ||
+		len(entityDiff.Components) != 1 || entityDiff.Components[0].Name != "stats" {
+		t.Errorf("the entity 3 should miss testSparseComponent and differ by stats, got %+v", entityDiff)
+	}
+
+	entityDiff = diff.Entities[2]
+	if entityDiff.EntityId != 4 || !slices.Equal(entityDiff.MissingTags, []TagId{TAG_1}) || !slices.Equal(entityDiff.ExtraTags, []TagId{TAG_2}) {
+		t.Errorf("the entity 4 should differ by its tags, got %+v", entityDiff)
+	}
+
+	for _, expected := range []string{
+		"entities extra in b: [1 5]",
+		"entity 2:\n  component github.com/akmonengine/volt.testAutoComponent1: {value:2} != {value:20}",
+		"  component github.com/akmonengine/volt.testSparseComponent missing in b",
+		"  tag 2049 extra in b",
+	} {
+		if !strings.Contains(diff.String(), expected) {
+			t.Errorf("the diff should contain %q, got:\n%s", expected, diff)
+		}
+	}
+
+	// Diff is symmetric.
+	reverse := Diff(b, a)
+	if !slices.Equal(reverse.MissingEntities, []EntityId{1, 5}) || len(reverse.MissingArchetypes) != 2 || len(reverse.Entities) != 3 {
+		t.Errorf("the reverse diff should mirror the diff, got:\n%s", reverse)
+	}
+}
+
+func TestDiff_EqualFn(t *testing.T) {
+	a := CreateWorld(16)
+	b := CreateWorld(16)
+	for _, world := range []*World{a, b} {
+		RegisterComponent[testInventoryComponent](world, &ComponentConfig[testInventoryComponent]{
+			EqualFn: func(a testInventoryComponent, b testInventoryComponent) bool {
+				return len(a.items) == len(b.items)
+			},
+		})
+	}
+
+	entityId := a.CreateEntity()
+	AddComponent(a, entityId, testInventoryComponent{items: []string{"sword"}})
+	entityId = b.CreateEntity()
+	AddComponent(b, entityId, testInventoryComponent{items: []string{"shield"}})
+
+	if diff := Diff(a, b); !diff.Empty() {
+		t.Errorf("the components should be compared with EqualFn, got:\n%s", diff)
+	}
+
+	GetComponent[testInventoryComponent](b, entityId).items = nil
+	if diff := Diff(a, b); len(diff.Entities) != 1 {
+		t.Errorf("the components should differ, got:\n%s", diff)
+	}
+}
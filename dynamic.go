@@ -0,0 +1,575 @@
+package volt
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+	"reflect"
+	"unsafe"
+)
+
+// DynamicLayout describes the memory of a dynamic component: its size in bytes, and its fields.
+//
+// The Type of a field is optional; if set, it is checked by GetDynamicField and SetDynamicField.
+type DynamicLayout struct {
+	Size   uintptr
+	Fields []ComponentField
+}
+
+// Field returns the field called name.
+func (layout DynamicLayout) Field(name string) (ComponentField, bool) {
+	for _, field := range layout.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+
+	return ComponentField{}, false
+}
+
+func (layout DynamicLayout) validate() error {
+	if layout.Size == 0 {
+		return fmt.Errorf("a dynamic component cannot be empty, use a tag instead")
+	}
+
+	for _, field := range layout.Fields {
+		if field.Type != nil && field.Type.Size() != field.Size {
+			return fmt.Errorf("the field %s has a size %d, but its type %v has a size %d", field.Name, field.Size, field.Type, field.Type.Size())
+		}
+		if field.Offset+field.Size > layout.Size {
+			return fmt.Errorf("the field %s overflows the component size %d", field.Name, layout.Size)
+		}
+	}
+
+	return nil
+}
+
+// dynamicComponent is the value of a dynamic component, passed to the storage interface.
+type dynamicComponent struct {
+	componentId ComponentId
+	data        []byte
+}
+
+func (component dynamicComponent) GetComponentId() ComponentId {
+	return component.componentId
+}
+
+// dynamicComponentConfig registers a component defined at runtime, without Go type.
+//
+// The configuration given to World.AddComponent is the []byte data of the
+// component; a nil configuration adds a zeroed component.
+type dynamicComponentConfig struct {
+	info ComponentInfo
+}
+
+func (config *dynamicComponentConfig) builderFn(component any, configuration any) {
+	if data, ok := configuration.([]byte); ok {
+		copy(*component.(*[]byte), data)
+	}
+}
+
+func (config *dynamicComponentConfig) getComponentId() ComponentId {
+	return config.info.Id
+}
+
+func (config *dynamicComponentConfig) getType() reflect.Type {
+	return nil
+}
+
+func (config *dynamicComponentConfig) getInfo() ComponentInfo {
+	return config.info
+}
+
+func (config *dynamicComponentConfig) getName() string {
+	return config.info.Name
+}
+
+func (config *dynamicComponentConfig) getStorageStrategy() StorageStrategy {
+	return TABLE_STORAGE
+}
+
+// isReplicated is false: a dynamic component has no ComponentConfig to set Replicated, see RegisterDynamicComponent.
+func (config *dynamicComponentConfig) isReplicated() bool {
+	return false
+}
+
+func (config *dynamicComponentConfig) setComponent(component any, componentId ComponentId) {
+	config.info.Id = componentId
+}
+
+// addComponent adds the component set with the configuration, which is its data, as with AddDynamicComponent.
+func (config *dynamicComponentConfig) addComponent(world *World, entityId EntityId, configuration any) error {
+	if data, ok := configuration.([]byte); ok && len(data) != int(config.info.Size) {
+		return fmt.Errorf("the component %s has a size of %d bytes, got %d", config.info.Name, config.info.Size, len(data))
+	}
+
+	data := make([]byte, config.info.Size)
+	config.builderFn(&data, configuration)
+
+	return world.addDynamicComponent(world.entities.get(entityId), config.info.Id, data)
+}
+
+func (config *dynamicComponentConfig) addComponentValue(world *World, entityId EntityId, component any, clone bool) error {
+	return world.AddDynamicComponent(entityId, config.info.Id, component.([]byte))
+}
+
+func (config *dynamicComponentConfig) remapComponent(world *World, entityId EntityId, entitiesIds map[EntityId]EntityId) error {
+	return nil
+}
+
+func (config *dynamicComponentConfig) equalComponents(a any, b any) bool {
+	dataA, okA := a.([]byte)
+	dataB, okB := b.([]byte)
+
+	return okA && okB && bytes.Equal(dataA, dataB)
+}
+
+func (config *dynamicComponentConfig) appendBinary(world *World, entityId EntityId, buf []byte) ([]byte, error) {
+	data, err := world.GetDynamicComponent(entityId, config.info.Id)
+	if err != nil {
+		return buf, err
+	}
+
+	return append(buf, data...), nil
+}
+
+func (config *dynamicComponentConfig) setBinary(world *World, entityId EntityId, data []byte) error {
+	if world.HasComponents(entityId, config.info.Id) {
+		return world.SetDynamicComponent(entityId, config.info.Id, data)
+	}
+
+	return world.AddDynamicComponent(entityId, config.info.Id, data)
+}
+
+// RegisterDynamicComponent registers in the World a component defined at runtime, e.g. from a JSON schema or a script.
+//
+// The component has no Go type: its data is a slice of layout.Size bytes, stored in
+// Structure of Arrays like any other component. A free ComponentId is allocated and returned.
+// A dynamic component is not replicated: the package replication only sends the components of a ComponentConfig with Replicated.
+//
+// It returns an error if:
+//   - the layout is invalid
+//   - the name is already used by another component
+//   - no ComponentId is left to allocate
+func RegisterDynamicComponent(world *World, name string, layout DynamicLayout) (ComponentId, error) {
+	if err := layout.validate(); err != nil {
+		return 0, err
+	}
+	if world.componentsRegistry == nil {
+		world.componentsRegistry = make(ComponentsRegister, TAGS_INDICES)
+	}
+	if registeredId, ok := world.componentsByName[name]; ok {
+		return 0, fmt.Errorf("the name %q is already used by the component %d", name, registeredId)
+	}
+
+	componentId, err := world.allocateComponentId(name)
+	if err != nil {
+		return 0, err
+	}
+
+	config := &dynamicComponentConfig{info: ComponentInfo{
+		Name:   name,
+		Size:   layout.Size,
+		Fields: layout.Fields,
+	}}
+	config.setComponent(nil, componentId)
+
+	world.componentsRegistry[componentId] = config
+	world.componentsByName[name] = componentId
+	world.storage[componentId] = &dynamicStorage{
+		componentId: componentId,
+		stride:      int(layout.Size),
+	}
+
+	return componentId, nil
+}
+
+// getDynamicStorage returns the storage of the dynamic component componentId.
+func (world *World) getDynamicStorage(componentId ComponentId) (*dynamicStorage, error) {
+	s, err := world.getStorageForComponentId(componentId)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicStorage, ok := s.(*dynamicStorage)
+	if !ok {
+		return nil, fmt.Errorf("the component %s is not a dynamic component", world.componentName(componentId))
+	}
+
+	return dynamicStorage, nil
+}
+
+// AddDynamicComponent adds the dynamic component componentId to the EntityId, set with data.
+//
+// A nil data adds a zeroed component.
+// It returns an error if:
+//   - the entity does not exist
+//   - the entity has the component
+//   - componentId is not a dynamic component
+//   - data does not match the size of the component
+func (world *World) AddDynamicComponent(entityId EntityId, componentId ComponentId, data []byte) error {
+	if !world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
+	}
+	entityRecord := world.entities.get(entityId)
+
+	if world.hasComponents(entityRecord, componentId) {
+		return fmt.Errorf("the entity %d already owns the component %s", entityId, world.componentName(componentId))
+	}
+
+	s, err := world.getDynamicStorage(componentId)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		data = make([]byte, s.stride)
+	}
+	if len(data) != s.stride {
+		return fmt.Errorf("the component %s has a size of %d bytes, got %d", world.componentName(componentId), s.stride, len(data))
+	}
+
+	err = world.addDynamicComponent(entityRecord, componentId, data)
+	if err != nil {
+		return err
+	}
+
+	world.componentAddedFn(entityId, componentId)
+
+	return nil
+}
+
+func (world *World) addDynamicComponent(entityRecord entityRecord, componentId ComponentId, data []byte) error {
+	s, err := world.getDynamicStorage(componentId)
+	if err != nil {
+		return err
+	}
+
+	archetype := world.getNextArchetype(entityRecord, componentId)
+	s.addBytes(archetype.Id, data)
+	if entityRecord.key < 0 {
+		world.setArchetype(entityRecord, archetype)
+	} else {
+		oldArchetype := world.getArchetype(entityRecord)
+		if archetype.Id != oldArchetype.Id {
+			moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+			world.setArchetype(entityRecord, archetype)
+		}
+	}
+
+	return nil
+}
+
+// GetDynamicComponent returns the data of the dynamic component componentId owned by the EntityId.
+//
+// The returned slice is a view on the storage: writing to it updates the component.
+// It is valid until the next structural change of the World.
+// It returns an error if:
+//   - the entity does not exist
+//   - componentId is not a dynamic component
+//   - the entity does not have the component
+func (world *World) GetDynamicComponent(entityId EntityId, componentId ComponentId) ([]byte, error) {
+	if !world.Exists(entityId) {
+		return nil, fmt.Errorf("entity %v does not exist", entityId)
+	}
+	entityRecord := world.entities.get(entityId)
+
+	s, err := world.getDynamicStorage(componentId)
+	if err != nil {
+		return nil, err
+	}
+	if !s.hasArchetype(entityRecord.archetypeId) {
+		return nil, fmt.Errorf("the entity %d doesn't own the component %s", entityId, world.componentName(componentId))
+	}
+	world.guardWrite(entityId, componentId)
+
+	return s.row(entityRecord.archetypeId, entityRecord.key), nil
+}
+
+// SetDynamicComponent overwrites the data of the dynamic component componentId owned by the EntityId.
+//
+// It returns an error if:
+//   - the entity does not exist, or does not have the component
+//   - componentId is not a dynamic component
+//   - data does not match the size of the component
+func (world *World) SetDynamicComponent(entityId EntityId, componentId ComponentId, data []byte) error {
+	row, err := world.GetDynamicComponent(entityId, componentId)
+	if err != nil {
+		return err
+	}
+	if len(data) != len(row) {
+		return fmt.Errorf("the component %s has a size of %d bytes, got %d", world.componentName(componentId), len(row), len(data))
+	}
+
+	copy(row, data)
+
+	return nil
+}
+
+// DynamicFieldType is the set of types a field of a dynamic component can be read or written as.
+type DynamicFieldType interface {
+	~bool | ~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~float32 | ~float64
+}
+
+// dynamicField returns the bytes of the field fieldName in the dynamic component, checked against the type T.
+func dynamicField[T DynamicFieldType](world *World, entityId EntityId, componentId ComponentId, fieldName string) ([]byte, error) {
+	row, err := world.GetDynamicComponent(entityId, componentId)
+	if err != nil {
+		return nil, err
+	}
+
+	info := world.componentsRegistry[componentId].getInfo()
+	field, ok := DynamicLayout{Size: info.Size, Fields: info.Fields}.Field(fieldName)
+	if !ok {
+		return nil, fmt.Errorf("the component %s has no field %s", world.componentName(componentId), fieldName)
+	}
+
+	fieldType := reflect.TypeFor[T]()
+	if field.Size != fieldType.Size() || (field.Type != nil && field.Type != fieldType) {
+		return nil, fmt.Errorf("the field %s of the component %s cannot be accessed as %v", fieldName, world.componentName(componentId), fieldType)
+	}
+
+	return row[field.Offset : field.Offset+field.Size], nil
+}
+
+// GetDynamicField returns the value of the field fieldName, of the dynamic component componentId owned by the EntityId.
+//
+// The value is read in the native byte order.
+func GetDynamicField[T DynamicFieldType](world *World, entityId EntityId, componentId ComponentId, fieldName string) (T, error) {
+	var value T
+	data, err := dynamicField[T](world, entityId, componentId, fieldName)
+	if err != nil {
+		return value, err
+	}
+
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(&value)), len(data)), data)
+
+	return value, nil
+}
+
+// SetDynamicField sets the value of the field fieldName, of the dynamic component componentId owned by the EntityId.
+//
+// The value is written in the native byte order.
+func SetDynamicField[T DynamicFieldType](world *World, entityId EntityId, componentId ComponentId, fieldName string, value T) error {
+	data, err := dynamicField[T](world, entityId, componentId, fieldName)
+	if err != nil {
+		return err
+	}
+
+	copy(data, unsafe.Slice((*byte)(unsafe.Pointer(&value)), len(data)))
+
+	return nil
+}
+
+// dynamicStorage stores, for each archetype, a column of dynamic components.
+// A column is a single slice of bytes, where each component takes stride bytes.
+type dynamicStorage struct {
+	componentId ComponentId
+	stride      int
+	columns     [][]byte
+}
+
+func (c *dynamicStorage) getType() ComponentId {
+	return c.componentId
+}
+
+func (c *dynamicStorage) getArchetypes() []archetypeId {
+	var archetypes []archetypeId
+	for id, column := range c.columns {
+		if column != nil {
+			archetypes = append(archetypes, archetypeId(id))
+		}
+	}
+
+	return archetypes
+}
+
+func (c *dynamicStorage) hasArchetype(archetypeId archetypeId) bool {
+	return int(archetypeId) < len(c.columns) && c.columns[archetypeId] != nil
+}
+
+// getColumn returns the bytes of all the components in archetypeId, or nil.
+func (c *dynamicStorage) getColumn(archetypeId archetypeId) []byte {
+	if int(archetypeId) >= len(c.columns) {
+		return nil
+	}
+
+	return c.columns[archetypeId]
+}
+
+// row returns the view on the component at key in archetypeId.
+// Its capacity is clipped, so that appending to it cannot overwrite the next component.
+func (c *dynamicStorage) row(archetypeId archetypeId, key int) []byte {
+	start := key * c.stride
+
+	return c.columns[archetypeId][start : start+c.stride : start+c.stride]
+}
+
+func (c *dynamicStorage) addBytes(archetypeId archetypeId, data []byte) int {
+	for len(c.columns) <= int(archetypeId) {
+		c.columns = append(c.columns, nil)
+	}
+	if c.columns[archetypeId] == nil {
+		c.columns[archetypeId] = make([]byte, 0, c.stride)
+	}
+	c.columns[archetypeId] = append(c.columns[archetypeId], data...)
+
+	return c.size(archetypeId) - 1
+}
+
+func (c *dynamicStorage) add(archetypeId archetypeId, component ComponentInterface) int {
+	return c.addBytes(archetypeId, component.(dynamicComponent).data)
+}
+
+func (c *dynamicStorage) set(archetypeId archetypeId, key int, component ComponentInterface) {
+	copy(c.row(archetypeId, key), component.(dynamicComponent).data)
+}
+
+func (c *dynamicStorage) get(archetypeId archetypeId, key int) any {
+	return c.row(archetypeId, key)
+}
+
+func (c *dynamicStorage) copy(oldArchetypeId archetypeId, archetypeId archetypeId, recordKey int) int {
+	return c.addBytes(archetypeId, c.row(oldArchetypeId, recordKey))
+}
+
+func (c *dynamicStorage) size(archetypeId archetypeId) int {
+	return len(c.getColumn(archetypeId)) / c.stride
+}
+
+func (c *dynamicStorage) moveLastToKey(archetypeId archetypeId, recordKey int) {
+	data := c.columns[archetypeId]
+	last := len(data) - c.stride
+
+	copy(data[recordKey*c.stride:], data[last:])
+	c.columns[archetypeId] = data[:last]
+}
+
+func (c *dynamicStorage) delete(archetypeId archetypeId, key int) {
+	if key < c.size(archetypeId) {
+		data := c.columns[archetypeId]
+		c.columns[archetypeId] = append(data[:key*c.stride], data[(key+1)*c.stride:]...)
+	}
+}
+
+func (c *dynamicStorage) isSparse() bool {
+	return false
+}
+
+func (c *dynamicStorage) isShared() bool {
+	return false
+}
+
+func (c *dynamicStorage) linkGroup(archetypeId archetypeId, value int) {}
+
+func (c *dynamicStorage) freeGroup(value int) {}
+
+func (c *dynamicStorage) hasEntity(entityId EntityId) bool {
+	return false
+}
+
+func (c *dynamicStorage) getEntity(entityId EntityId) any {
+	return nil
+}
+
+func (c *dynamicStorage) removeEntity(entityId EntityId) {
+}
+
+// DynamicQuery fetches the entities owning a list of ComponentId, typically dynamic components.
+type DynamicQuery struct {
+	World              *World
+	componentsIds      []ComponentId
+	queryConfiguration QueryConfiguration
+
+	cache filterCache
+}
+
+// Result returned for DynamicQuery.
+//
+// Components holds the data of each ComponentId of the query, in the same order;
+// an optional component not owned by the entity is nil.
+// Components is reused during the iteration: it must be copied to be kept.
+type DynamicQueryResult struct {
+	EntityId   EntityId
+	Components [][]byte
+}
+
+// CreateDynamicQuery returns a new DynamicQuery, with the dynamic components componentsIds.
+func CreateDynamicQuery(world *World, queryConfiguration QueryConfiguration, componentsIds ...ComponentId) DynamicQuery {
+	return DynamicQuery{
+		World:              world,
+		componentsIds:      componentsIds,
+		queryConfiguration: queryConfiguration,
+		cache:              newFilterCache(world, componentsIds, queryConfiguration),
+	}
+}
+
+func (query *DynamicQuery) GetComponentsIds() []ComponentId {
+	return query.componentsIds
+}
+
+func (query *DynamicQuery) filter() []archetypeId {
+	return query.cache.resolve(query.World)
+}
+
+// Count returns the total of entities fetched for DynamicQuery.
+func (query *DynamicQuery) Count() int {
+	return query.cache.count(query.World)
+}
+
+// GetEntitiesIds returns a slice of all the EntityId fetched for DynamicQuery.
+func (query *DynamicQuery) GetEntitiesIds() []EntityId {
+	return query.cache.entities(query.World)
+}
+
+// Foreach returns an iterator of DynamicQueryResult for all the entities with the components of the query
+// to which filterFn function returns true.
+//
+// The components of the query that are not dynamic components are always nil in the results.
+func (query *DynamicQuery) Foreach(filterFn func(DynamicQueryResult) bool) iter.Seq[DynamicQueryResult] {
+	return func(yield func(DynamicQueryResult) bool) {
+		storages := make([]*dynamicStorage, len(query.componentsIds))
+		for i, componentId := range query.componentsIds {
+			storages[i], _ = query.World.getDynamicStorage(componentId)
+		}
+		columns := make([][]byte, len(query.componentsIds))
+		sparseIds := query.cache.sparseIds
+		archetypesIds := query.filter()
+
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+		result := DynamicQueryResult{Components: make([][]byte, len(query.componentsIds))}
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			for c, s := range storages {
+				columns[c] = nil
+				if s != nil {
+					columns[c] = s.getColumn(archetype.Id)
+				}
+			}
+
+			for i, entityId := range archetype.entities {
+				if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+					continue
+				}
+
+				for c, column := range columns {
+					result.Components[c] = nil
+					if column != nil {
+						stride := storages[c].stride
+						result.Components[c] = column[i*stride : (i+1)*stride : (i+1)*stride]
+					}
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					continue
+				}
+
+				if !yield(result) {
+					return
+				}
+			}
+		}
+	}
+}
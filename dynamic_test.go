@@ -0,0 +1,229 @@
+package volt
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testDynamicLayout = DynamicLayout{
+	Size: 12,
+	Fields: []ComponentField{
+		{Name: "health", Type: reflect.TypeFor[float32](), Offset: 0, Size: 4},
+		{Name: "armor", Offset: 4, Size: 8},
+	},
+}
+
+func TestRegisterDynamicComponent(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+
+	componentId, err := RegisterDynamicComponent(world, "stats", testDynamicLayout)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if componentId == testComponent1Id || componentId >= TAGS_INDICES {
+		t.Errorf("invalid ComponentId %d allocated", componentId)
+	}
+
+	info, err := world.ComponentInfo(componentId)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if info.Name != "stats" || info.Size != 12 || len(info.Fields) != 2 || info.Type != nil {
+		t.Errorf("unexpected ComponentInfo %+v", info)
+	}
+	if byName, _ := world.ComponentIdByName("stats"); byName != componentId {
+		t.Errorf("ComponentIdByName returned %d instead of %d", byName, componentId)
+	}
+
+	if _, err = RegisterDynamicComponent(world, "stats", testDynamicLayout); err == nil {
+		t.Errorf("RegisterDynamicComponent should reject a name already used")
+	}
+	if _, err = RegisterDynamicComponent(world, "empty", DynamicLayout{}); err == nil {
+		t.Errorf("RegisterDynamicComponent should reject an empty component")
+	}
+	overflow := DynamicLayout{Size: 4, Fields: []ComponentField{{Name: "x", Offset: 2, Size: 4}}}
+	if _, err = RegisterDynamicComponent(world, "overflow", overflow); err == nil {
+		t.Errorf("RegisterDynamicComponent should reject a field out of the component")
+	}
+}
+
+func TestWorld_AddDynamicComponent(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	componentId, _ := RegisterDynamicComponent(world, "stats", testDynamicLayout)
+
+	entities := make([]EntityId, 10)
+	for i := range entities {
+		entities[i] = world.CreateEntity()
+		AddComponent(world, entities[i], testComponent1{testComponent{x: i}})
+
+		data := make([]byte, 12)
+		data[0] = byte(i)
+		if err := world.AddDynamicComponent(entities[i], componentId, data); err != nil {
+			t.Errorf("%s", err.Error())
+		}
+	}
+
+	if err := world.AddDynamicComponent(entities[0], componentId, nil); err == nil {
+		t.Errorf("AddDynamicComponent should reject a component already owned")
+	}
+	entityId := world.CreateEntity()
+	if err := world.AddDynamicComponent(entityId, componentId, []byte{1}); err == nil {
+		t.Errorf("AddDynamicComponent should reject data of a wrong size")
+	}
+	if err := world.AddDynamicComponent(entityId, testComponent1Id, nil); err == nil {
+		t.Errorf("AddDynamicComponent should reject a typed component")
+	}
+
+	// Remove an entity in the middle, so that the last one is moved.
+	world.RemoveEntity(entities[2])
+	if err := world.RemoveComponent(entities[5], testComponent1Id); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+
+	for i, entityId := range entities {
+		if i == 2 {
+			continue
+		}
+		data, err := world.GetDynamicComponent(entityId, componentId)
+		if err != nil {
+			t.Errorf("%s", err.Error())
+			continue
+		}
+		if data[0] != byte(i) {
+			t.Errorf("the entity %d has the data %d instead of %d", entityId, data[0], i)
+		}
+	}
+
+	if err := world.RemoveComponent(entities[3], componentId); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if _, err := world.GetDynamicComponent(entities[3], componentId); err == nil {
+		t.Errorf("the dynamic component should be removed")
+	}
+	if component := GetComponent[testComponent1](world, entities[3]); component == nil || component.x != 3 {
+		t.Errorf("the typed component should be kept")
+	}
+}
+
+func TestWorld_AddComponent_Dynamic(t *testing.T) {
+	world := CreateWorld(16)
+	componentId, _ := RegisterDynamicComponent(world, "stats", testDynamicLayout)
+
+	entityId := world.CreateEntity()
+	if err := world.AddComponent(entityId, componentId, []byte{1, 2, 3}); err == nil {
+		t.Errorf("AddComponent should reject a configuration which does not match the size of the component")
+	}
+	if err := world.AddComponent(entityId, componentId, make([]byte, 13)); err == nil {
+		t.Errorf("AddComponent should reject a configuration which does not match the size of the component")
+	}
+	if err := world.AddComponent(entityId, componentId, []byte{1, 2, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	component, err := world.GetComponent(entityId, componentId)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	data := component.([]byte)
+	if len(data) != 12 || data[0] != 1 || data[2] != 3 || data[3] != 0 {
+		t.Errorf("unexpected data %v", data)
+	}
+}
+
+func TestDynamicField(t *testing.T) {
+	world := CreateWorld(16)
+	componentId, _ := RegisterDynamicComponent(world, "stats", testDynamicLayout)
+	entityId := world.CreateEntity()
+	world.AddDynamicComponent(entityId, componentId, nil)
+
+	if err := SetDynamicField[float32](world, entityId, componentId, "health", 42.5); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := SetDynamicField[int64](world, entityId, componentId, "armor", -7); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	health, err := GetDynamicField[float32](world, entityId, componentId, "health")
+	if err != nil || health != 42.5 {
+		t.Errorf("GetDynamicField returned %v, %v", health, err)
+	}
+	armor, err := GetDynamicField[int64](world, entityId, componentId, "armor")
+	if err != nil || armor != -7 {
+		t.Errorf("GetDynamicField returned %v, %v", armor, err)
+	}
+
+	if _, err = GetDynamicField[int32](world, entityId, componentId, "health"); err == nil {
+		t.Errorf("GetDynamicField should reject a type different from the field type")
+	}
+	if _, err = GetDynamicField[int32](world, entityId, componentId, "armor"); err == nil {
+		t.Errorf("GetDynamicField should reject a type of a different size")
+	}
+	if _, err = GetDynamicField[int32](world, entityId, componentId, "unknown"); err == nil {
+		t.Errorf("GetDynamicField should reject an unknown field")
+	}
+
+	err = world.SetDynamicComponent(entityId, componentId, make([]byte, 12))
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if health, _ = GetDynamicField[float32](world, entityId, componentId, "health"); health != 0 {
+		t.Errorf("SetDynamicComponent did not overwrite the data")
+	}
+}
+
+func TestDynamicQuery_Foreach(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	statsId, _ := RegisterDynamicComponent(world, "stats", testDynamicLayout)
+	flagsId, _ := RegisterDynamicComponent(world, "flags", DynamicLayout{Size: 1})
+
+	for i := range 10 {
+		entityId := world.CreateEntity()
+		world.AddDynamicComponent(entityId, statsId, nil)
+		SetDynamicField[float32](world, entityId, statsId, "health", float32(i))
+		if i%2 == 0 {
+			world.AddDynamicComponent(entityId, flagsId, []byte{1})
+		}
+		if i%3 == 0 {
+			AddComponent(world, entityId, testComponent1{})
+		}
+	}
+
+	query := CreateDynamicQuery(world, QueryConfiguration{}, statsId, flagsId)
+	if query.Count() != 5 {
+		t.Errorf("query should return 5 entities, got %d", query.Count())
+	}
+
+	query = CreateDynamicQuery(world, QueryConfiguration{OptionalComponents: []OptionalComponent{OptionalComponent(flagsId)}}, statsId, flagsId)
+	if query.Count() != 10 || len(query.GetEntitiesIds()) != 10 {
+		t.Errorf("query should return 10 entities, got %d", query.Count())
+	}
+
+	count := 0
+	for result := range query.Foreach(nil) {
+		health, _ := GetDynamicField[float32](world, result.EntityId, statsId, "health")
+		if len(result.Components[0]) != 12 {
+			t.Errorf("unexpected data for the component stats: %v", result.Components[0])
+		}
+		if health != float32(result.EntityId) {
+			t.Errorf("the entity %d has the health %v", result.EntityId, health)
+		}
+		if hasFlags := world.HasComponents(result.EntityId, flagsId); hasFlags != (result.Components[1] != nil) {
+			t.Errorf("the optional component flags is not consistent for the entity %d", result.EntityId)
+		}
+		count++
+	}
+	if count != 10 {
+		t.Errorf("Foreach should iterate over 10 entities, got %d", count)
+	}
+
+	count = 0
+	for range query.Foreach(func(result DynamicQueryResult) bool { return result.Components[1] != nil }) {
+		count++
+	}
+	if count != 5 {
+		t.Errorf("Foreach should filter 5 entities, got %d", count)
+	}
+}
@@ -0,0 +1,228 @@
+package volt
+
+import (
+	"fmt"
+)
+
+// Index maps the keys computed from the component T to the entities owning it, see CreateIndex.
+type Index[T ComponentInterface, K comparable] struct {
+	world       *World
+	componentId ComponentId
+	keyFn       func(component T) K
+	unique      bool
+
+	entities map[K][]EntityId
+	keys     map[EntityId]K
+}
+
+// componentIndex is the interface of the Index of a component, kept in sync by the World.
+type componentIndex interface {
+	// insert indexes the component of the entity, replacing its previous key.
+	insert(entityId EntityId)
+	remove(entityId EntityId)
+	rebuild() error
+}
+
+// CreateIndex returns an Index of the component T, keyed by keyFn, e.g. to find the entities by name.
+//
+// The Index is kept in sync as the component T is added, set with SetComponent, or removed, and as the entities are removed.
+// The changes made through the pointers returned by GetComponent or the queries are not seen:
+// SetComponent must be used to change the key of a component.
+// With a ConcurrentWorld, the Index must be read within Run, with T among the components read.
+// It returns an error if the component T is not registered.
+func CreateIndex[T ComponentInterface, K comparable](world *World, keyFn func(component T) K) (*Index[T, K], error) {
+	return createIndex(world, keyFn, false)
+}
+
+// CreateUniqueIndex returns an Index of the component T where a key refers to at most one entity, see CreateIndex.
+//
+// Adding or setting the component of an entity with the key of another one is rejected with an error.
+// It returns an error if the component T is not registered, or if several entities already have the same key.
+func CreateUniqueIndex[T ComponentInterface, K comparable](world *World, keyFn func(component T) K) (*Index[T, K], error) {
+	return createIndex(world, keyFn, true)
+}
+
+func createIndex[T ComponentInterface, K comparable](world *World, keyFn func(component T) K, unique bool) (*Index[T, K], error) {
+	componentId := ComponentIdOf[T](world)
+	if getStorage[T](world) == nil {
+		var t T
+		return nil, fmt.Errorf("the component %T is not registered", t)
+	}
+
+	index := &Index[T, K]{
+		world:       world,
+		componentId: componentId,
+		keyFn:       keyFn,
+		unique:      unique,
+	}
+	if err := index.rebuild(); err != nil {
+		return nil, err
+	}
+
+	if world.indexes == nil {
+		world.indexes = make(map[ComponentId][]componentIndex)
+	}
+	world.indexes[componentId] = append(world.indexes[componentId], index)
+
+	return index, nil
+}
+
+// Lookup returns the entities whose component T has the key. The slice must not be modified.
+func (index *Index[T, K]) Lookup(key K) []EntityId {
+	return index.entities[key]
+}
+
+// LookupUnique returns the entity whose component T has the key, or false if none has.
+func (index *Index[T, K]) LookupUnique(key K) (EntityId, bool) {
+	entities := index.entities[key]
+	if len(entities) == 0 {
+		return 0, false
+	}
+
+	return entities[0], true
+}
+
+// Count returns the number of keys indexed.
+func (index *Index[T, K]) Count() int {
+	return len(index.entities)
+}
+
+// check returns an error if the component would give the entity the key of another one, in a unique Index.
+func (index *Index[T, K]) check(entityId EntityId, component T) error {
+	if !index.unique {
+		return nil
+	}
+
+	key := index.keyFn(component)
+	if entities := index.entities[key]; len(entities) > 0 && entities[0] != entityId {
+		return fmt.Errorf("the key %v of the component %s is already indexed for the entity %d", key, index.world.componentName(index.componentId), entities[0])
+	}
+
+	return nil
+}
+
+func (index *Index[T, K]) insert(entityId EntityId) {
+	component := GetComponent[T](index.world, entityId)
+	if component == nil {
+		return
+	}
+
+	key := index.keyFn(*component)
+	if previous, ok := index.keys[entityId]; ok {
+		if previous == key {
+			return
+		}
+		index.remove(entityId)
+	}
+
+	index.entities[key] = append(index.entities[key], entityId)
+	index.keys[entityId] = key
+}
+
+func (index *Index[T, K]) remove(entityId EntityId) {
+	key, ok := index.keys[entityId]
+	if !ok {
+		return
+	}
+
+	entities := index.entities[key]
+	for i, indexedId := range entities {
+		if indexedId == entityId {
+			entities[i] = entities[len(entities)-1]
+			entities = entities[:len(entities)-1]
+			break
+		}
+	}
+
+	if len(entities) == 0 {
+		delete(index.entities, key)
+	} else {
+		index.entities[key] = entities
+	}
+	delete(index.keys, entityId)
+}
+
+// rebuild indexes all the components T of the World again.
+func (index *Index[T, K]) rebuild() error {
+	if index.entities == nil {
+		index.entities = make(map[K][]EntityId)
+		index.keys = make(map[EntityId]K)
+	}
+	clear(index.entities)
+	clear(index.keys)
+
+	for entityId := range index.world.Entities() {
+		component := GetComponent[T](index.world, entityId)
+		if component == nil {
+			continue
+		}
+
+		if err := index.check(entityId, *component); err != nil {
+			return err
+		}
+		index.insert(entityId)
+	}
+
+	return nil
+}
+
+// typedIndex is implemented by the Index of the component T, whatever its key.
+type typedIndex[T ComponentInterface] interface {
+	check(entityId EntityId, component T) error
+}
+
+// checkIndexes returns an error if the component T would break a unique Index, before it is added or set.
+func checkIndexes[T ComponentInterface](world *World, entityId EntityId, componentId ComponentId, component T) error {
+	if len(world.indexes) == 0 {
+		return nil
+	}
+
+	for _, index := range world.indexes[componentId] {
+		if err := index.(typedIndex[T]).check(entityId, component); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateIndexes indexes the component of the entity, once added or set.
+func (world *World) updateIndexes(entityId EntityId, componentId ComponentId) {
+	if len(world.indexes) == 0 {
+		return
+	}
+
+	for _, index := range world.indexes[componentId] {
+		index.insert(entityId)
+	}
+}
+
+// unindexComponent removes the component of the entity from the indexes, before it is removed.
+func (world *World) unindexComponent(entityId EntityId, componentId ComponentId) {
+	if len(world.indexes) == 0 {
+		return
+	}
+
+	for _, index := range world.indexes[componentId] {
+		index.remove(entityId)
+	}
+}
+
+// unindexEntity removes all the components of the entity from the indexes, before it is removed.
+func (world *World) unindexEntity(entityId EntityId) {
+	for _, indexes := range world.indexes {
+		for _, index := range indexes {
+			index.remove(entityId)
+		}
+	}
+}
+
+// rebuildIndexes indexes all the components again, once the World is restored or cleared.
+func (world *World) rebuildIndexes() {
+	for _, indexes := range world.indexes {
+		for _, index := range indexes {
+			// The components restored were valid when indexed, a duplicate key cannot occur.
+			_ = index.rebuild()
+		}
+	}
+}
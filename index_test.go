@@ -0,0 +1,141 @@
+package volt
+
+import (
+	"slices"
+	"testing"
+)
+
+type testNameComponent struct {
+	name string
+}
+
+func (t testNameComponent) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+func nameKey(component testNameComponent) string {
+	return component.name
+}
+
+func TestCreateIndex(t *testing.T) {
+	world := CreateWorld(16)
+	registerTestComponents(t, world)
+	RegisterComponent[testNameComponent](world, &ComponentConfig[testNameComponent]{})
+	orc := world.CreateEntity()
+	AddComponent(world, orc, testNameComponent{name: "orc"})
+
+	index, err := CreateIndex(world, nameKey)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	sparseIndex, err := CreateIndex(world, func(component testSparseComponent) int { return component.x })
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if _, err = CreateIndex(world, func(component testComponent3) int { return 0 }); err == nil {
+		t.Errorf("CreateIndex should reject a component not registered")
+	}
+
+	// The components added before and after the index are indexed, whatever the function adding them.
+	goblin := world.CreateEntity()
+	AddComponents2(world, goblin, testNameComponent{name: "goblin"}, testComponent1{})
+	otherOrc := world.CreateEntity()
+	world.AddComponent(otherOrc, ComponentIdOf[testNameComponent](world), nil)
+	SetComponent(world, otherOrc, testNameComponent{name: "orc"})
+	AddComponent(world, goblin, testSparseComponent{testComponent{x: 3}})
+
+	if entities := index.Lookup("orc"); !slices.Equal(slices.Sorted(slices.Values(entities)), []EntityId{orc, otherOrc}) {
+		t.Errorf("the orcs should be indexed, got %v", entities)
+	}
+	if entities := index.Lookup("goblin"); !slices.Equal(entities, []EntityId{goblin}) {
+		t.Errorf("the goblin should be indexed, got %v", entities)
+	}
+	if entities := sparseIndex.Lookup(3); !slices.Equal(entities, []EntityId{goblin}) {
+		t.Errorf("the sparse component should be indexed, got %v", entities)
+	}
+	if index.Count() != 2 {
+		t.Errorf("the key of the component set should be replaced, got %d keys", index.Count())
+	}
+
+	// Moving the entity across archetypes does not change its key.
+	RemoveComponent[testComponent1](world, goblin)
+	SetComponent(world, goblin, testNameComponent{name: "troll"})
+	if len(index.Lookup("goblin")) != 0 || !slices.Equal(index.Lookup("troll"), []EntityId{goblin}) {
+		t.Errorf("the key should follow SetComponent, got %v %v", index.Lookup("goblin"), index.Lookup("troll"))
+	}
+
+	RemoveComponent[testNameComponent](world, orc)
+	world.RemoveEntity(goblin)
+	if !slices.Equal(index.Lookup("orc"), []EntityId{otherOrc}) || len(index.Lookup("troll")) != 0 || len(sparseIndex.Lookup(3)) != 0 {
+		t.Errorf("the components removed should be unindexed, got %v %v", index.Lookup("orc"), index.Lookup("troll"))
+	}
+
+	// The index follows the snapshots restored, and the world cleared.
+	snapshot := world.Snapshot()
+	world.RemoveEntity(otherOrc)
+	if err = world.Restore(snapshot); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if !slices.Equal(index.Lookup("orc"), []EntityId{otherOrc}) {
+		t.Errorf("the index should be rebuilt once restored, got %v", index.Lookup("orc"))
+	}
+	world.Clear(false)
+	if index.Count() != 0 {
+		t.Errorf("the index should be empty once the world cleared, got %d keys", index.Count())
+	}
+}
+
+func TestCreateUniqueIndex(t *testing.T) {
+	world := CreateWorld(16)
+	registerTestComponents(t, world)
+	RegisterComponent[testNameComponent](world, &ComponentConfig[testNameComponent]{})
+	index, err := CreateUniqueIndex(world, nameKey)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	player := world.CreateEntity()
+	AddComponent(world, player, testNameComponent{name: "player"})
+	other := world.CreateEntity()
+
+	if err = AddComponent(world, other, testNameComponent{name: "player"}); err == nil {
+		t.Errorf("AddComponent should reject a duplicate key")
+	}
+	if err = AddComponents2(world, other, testComponent1{}, testNameComponent{name: "player"}); err == nil {
+		t.Errorf("AddComponents2 should reject a duplicate key")
+	}
+	if world.HasComponents(other, ComponentIdOf[testComponent1](world)) {
+		t.Errorf("a rejected AddComponents2 should not add any component")
+	}
+
+	AddComponent(world, other, testNameComponent{name: "npc"})
+	if err = SetComponent(world, other, testNameComponent{name: "player"}); err == nil {
+		t.Errorf("SetComponent should reject a duplicate key")
+	}
+	if GetComponent[testNameComponent](world, other).name != "npc" {
+		t.Errorf("a rejected SetComponent should not change the component")
+	}
+	if err = SetComponent(world, player, testNameComponent{name: "player"}); err != nil {
+		t.Errorf("an entity should keep its own key, got %s", err.Error())
+	}
+
+	if entityId, ok := index.LookupUnique("player"); !ok || entityId != player {
+		t.Errorf("LookupUnique should return the player, got %d %v", entityId, ok)
+	}
+	if _, ok := index.LookupUnique("troll"); ok {
+		t.Errorf("LookupUnique should return false for an unknown key")
+	}
+
+	// The key is freed once the component is removed.
+	world.RemoveEntity(player)
+	if err = SetComponent(world, other, testNameComponent{name: "player"}); err != nil {
+		t.Errorf("the key of a removed entity should be free, got %s", err.Error())
+	}
+
+	third := world.CreateEntity()
+	AddComponent(world, third, testComponent1{})
+	world.AddComponent(third, ComponentIdOf[testNameComponent](world), nil)
+	if _, err = CreateUniqueIndex(world, func(component testNameComponent) bool { return true }); err == nil {
+		t.Errorf("CreateUniqueIndex should reject duplicate keys among the existing components")
+	}
+}
@@ -0,0 +1,111 @@
+package volt
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Merge copies all the entities of other into the World, with their components and tags,
+// and returns the map of their EntityId in other to their new EntityId.
+//
+// The entities are copied as with CopyEntity, in ascending order of EntityId; other is not modified.
+// Once all of them are copied, the components whose ComponentConfig sets a RemapFn are updated with the map,
+// so that the references between the entities merged stay correct.
+// It returns an error, before any change, if:
+//   - other is the World itself
+//   - a component of other is not registered in the World
+//
+// It returns an error along with the map of the entities merged so far, if:
+//   - a component cannot be added, e.g. with the key of another entity in a unique Index; the entity is not merged,
+//     and the components of the entities merged before it are not remapped
+//   - the value remapped of a shared component cannot be set, see SetSharedComponent; the components following it,
+//     in ascending order of EntityId then ComponentId, are not remapped
+//
+// The World is then partly merged: the entities of the map are kept, and left to the caller to fix or remove.
+func (world *World) Merge(other *World) (map[EntityId]EntityId, error) {
+	if world == other {
+		return nil, fmt.Errorf("a world cannot be merged into itself")
+	}
+
+	// All the components used by other are resolved, before any entity is copied.
+	componentsIds := make(map[ComponentId]ComponentId)
+	for _, archetype := range other.archetypes {
+		if len(archetype.entities) == 0 {
+			continue
+		}
+
+		for _, componentId := range archetype.Type {
+			if err := other.resolveTransferredComponent(world, componentId, componentsIds); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for entityId := range other.Entities() {
+		for _, componentId := range other.sparseComponentsIds {
+			if !other.storage[componentId].hasEntity(entityId) {
+				continue
+			}
+			if err := other.resolveTransferredComponent(world, componentId, componentsIds); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	entitiesIds := make(map[EntityId]EntityId, other.Count())
+	for entityId := range other.Entities() {
+		newEntityId, err := transferEntity(other, world, entityId, componentsIds, true)
+		if err != nil {
+			return entitiesIds, err
+		}
+		entitiesIds[entityId] = newEntityId
+	}
+
+	// The remaps follow the order of the entities and of the components, so that a Merge gives the same World on each run,
+	// e.g. the same shared groups created, see SetDeterministic.
+	remappedIds := slices.Sorted(maps.Values(componentsIds))
+	for entityId := range other.Entities() {
+		newEntityId := entitiesIds[entityId]
+		for _, componentId := range remappedIds {
+			if !world.HasComponents(newEntityId, componentId) {
+				continue
+			}
+			if err := world.componentsRegistry[componentId].remapComponent(world, newEntityId, entitiesIds); err != nil {
+				return entitiesIds, err
+			}
+		}
+	}
+
+	return entitiesIds, nil
+}
+
+// RemapComponent calls the RemapFn of the component componentId of the entity, if its ComponentConfig sets one,
+// with entitiesIds mapping the EntityId the component may hold to their new EntityId,
+// e.g. once received from another World.
+//
+// It returns an error if:
+//   - the entity does not have the component
+//   - the value remapped of a shared component cannot be set, see SetSharedComponent
+func (world *World) RemapComponent(entityId EntityId, componentId ComponentId, entitiesIds map[EntityId]EntityId) error {
+	if componentId >= TAGS_INDICES || !world.HasComponents(entityId, componentId) {
+		return fmt.Errorf("the entity %d doesn't own the component %s", entityId, world.componentName(componentId))
+	}
+
+	return world.componentsRegistry[componentId].remapComponent(world, entityId, entitiesIds)
+}
+
+// resolveTransferredComponent adds to componentsIds the ComponentId in dst of the component componentId of the World.
+// The tags need no translation.
+func (world *World) resolveTransferredComponent(dst *World, componentId ComponentId, componentsIds map[ComponentId]ComponentId) error {
+	if _, ok := componentsIds[componentId]; ok || componentId >= TAGS_INDICES {
+		return nil
+	}
+
+	dstId, err := translateComponentId(world, dst, componentId)
+	if err != nil {
+		return err
+	}
+	componentsIds[componentId] = dstId
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package volt
+
+import (
+	"slices"
+	"testing"
+)
+
+type testParentComponent struct {
+	parent EntityId
+}
+
+func (t testParentComponent) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+func TestWorld_Merge(t *testing.T) {
+	scene := CreateWorld(16)
+	statsId := registerTestComponents(t, scene)
+	RegisterComponent[testAutoComponent1](scene, &ComponentConfig[testAutoComponent1]{})
+	RegisterComponent[testInventoryComponent](scene, &ComponentConfig[testInventoryComponent]{})
+	// The components are registered in another order, so that their ComponentId differ.
+	world := CreateWorld(16)
+	RegisterComponent[testInventoryComponent](world, &ComponentConfig[testInventoryComponent]{})
+	RegisterComponent[testAutoComponent1](world, &ComponentConfig[testAutoComponent1]{})
+	registerTestComponents(t, world)
+	for _, w := range []*World{world, scene} {
+		RegisterComponent[testParentComponent](w, &ComponentConfig[testParentComponent]{
+			RemapFn: func(component *testParentComponent, entitiesIds map[EntityId]EntityId) {
+				component.parent = entitiesIds[component.parent]
+			},
+		})
+	}
+	for range 3 {
+		world.CreateEntity()
+	}
+
+	// A hierarchy of entities in the scene, with a removed entity in between.
+	root := createTransferEntity(scene, statsId, 1)
+	removed := scene.CreateEntity()
+	child := createTransferEntity(scene, statsId, 2)
+	AddComponent(scene, child, testParentComponent{parent: root})
+	grandChild := scene.CreateEntity()
+	AddComponent(scene, grandChild, testParentComponent{parent: child})
+	scene.RemoveEntity(removed)
+
+	added := 0
+	world.SetEntityAddedFn(func(entityId EntityId) { added++ })
+	entitiesIds, err := world.Merge(scene)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	if len(entitiesIds) != 3 || world.Count() != 6 || scene.Count() != 3 || added != 3 {
+		t.Fatalf("expected 3 entities merged, got %v", entitiesIds)
+	}
+	assertTransferred(t, world, entitiesIds[root], 1)
+	assertTransferred(t, world, entitiesIds[child], 2)
+	if parent := GetComponent[testParentComponent](world, entitiesIds[child]).parent; parent != entitiesIds[root] {
+		t.Errorf("the parent of the child should be remapped to %d, got %d", entitiesIds[root], parent)
+	}
+	if parent := GetComponent[testParentComponent](world, entitiesIds[grandChild]).parent; parent != entitiesIds[child] {
+		t.Errorf("the parent of the grand child should be remapped to %d, got %d", entitiesIds[child], parent)
+	}
+	if parent := GetComponent[testParentComponent](scene, grandChild).parent; parent != child {
+		t.Errorf("the scene should not be modified, got the parent %d", parent)
+	}
+
+	if _, err = world.Merge(world); err == nil {
+		t.Errorf("Merge should reject the world itself")
+	}
+
+	// A component not registered rejects the merge, before any change.
+	RegisterComponent[testComponent3](scene, &ComponentConfig[testComponent3]{})
+	AddComponent(scene, grandChild, testComponent3{})
+	if _, err = world.Merge(scene); err == nil || world.Count() != 6 {
+		t.Errorf("Merge should reject a component not registered, without change")
+	}
+
+	// A component rejected by a unique Index stops the merge: the entities merged so far are kept.
+	RegisterComponent[testComponent3](world, &ComponentConfig[testComponent3]{})
+	if _, err = CreateUniqueIndex(world, func(component testComponent3) int { return 0 }); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	AddComponent(world, world.CreateEntity(), testComponent3{})
+	entitiesIds, err = world.Merge(scene)
+	if err == nil {
+		t.Errorf("Merge should reject a component with the key of another entity in a unique Index")
+	}
+	if _, ok := entitiesIds[grandChild]; ok || len(entitiesIds) != 2 || world.Count() != 9 {
+		t.Errorf("the entities merged before the error should be kept, got %v", entitiesIds)
+	}
+}
+
+func TestWorld_Merge_Order(t *testing.T) {
+	var remapped []EntityId
+	config := &ComponentConfig[testParentComponent]{
+		RemapFn: func(component *testParentComponent, entitiesIds map[EntityId]EntityId) {
+			component.parent = entitiesIds[component.parent]
+			remapped = append(remapped, component.parent)
+		},
+	}
+	scene := CreateWorld(16)
+	RegisterComponent[testParentComponent](scene, config)
+	for i := range 50 {
+		AddComponent(scene, scene.CreateEntity(), testParentComponent{parent: EntityId(i)})
+	}
+
+	// The RemapFn are called in ascending order of EntityId, whatever the order of the maps.
+	for range 5 {
+		remapped = remapped[:0]
+		world := CreateWorld(16)
+		RegisterComponent[testParentComponent](world, config)
+		if _, err := world.Merge(scene); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		if len(remapped) != 50 || !slices.IsSorted(remapped) {
+			t.Errorf("the components should be remapped in ascending order of EntityId, got %v", remapped)
+		}
+	}
+}
+
+func TestWorld_RemapComponent(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testParentComponent](world, &ComponentConfig[testParentComponent]{
+		RemapFn: func(component *testParentComponent, entitiesIds map[EntityId]EntityId) {
+			component.parent = entitiesIds[component.parent]
+		},
+	})
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	parentId := world.CreateEntity()
+	childId := world.CreateEntity()
+	AddComponent(world, childId, testParentComponent{parent: 7})
+	AddComponent(world, childId, testComponent1{testComponent{x: 1}})
+
+	if err := world.RemapComponent(childId, ComponentIdOf[testParentComponent](world), map[EntityId]EntityId{7: parentId}); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if parent := GetComponent[testParentComponent](world, childId).parent; parent != parentId {
+		t.Errorf("the parent should be remapped to %d, got %d", parentId, parent)
+	}
+
+	// A component without RemapFn is left unchanged.
+	if err := world.RemapComponent(childId, ComponentIdOf[testComponent1](world), map[EntityId]EntityId{}); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if x := GetComponent[testComponent1](world, childId).x; x != 1 {
+		t.Errorf("a component without RemapFn should not change, got %d", x)
+	}
+
+	if err := world.RemapComponent(parentId, ComponentIdOf[testParentComponent](world), map[EntityId]EntityId{}); err == nil {
+		t.Errorf("RemapComponent should reject a component not owned")
+	}
+	if err := world.RemapComponent(childId, TAGS_INDICES, map[EntityId]EntityId{}); err == nil {
+		t.Errorf("RemapComponent should reject a tag")
+	}
+}
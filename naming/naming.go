@@ -0,0 +1,311 @@
+// Package naming names the entities of a World, and organises them in a hierarchy of paths such as "Level/Room1/Door".
+//
+// It is opt-in: the names are held by a sparse component, registered by Enable, which keeps them off the archetypes
+// and the queries of the World. As a component, the names follow the entities in the snapshots, the clones,
+// the merges and the replication of the World.
+//
+// The World removes the entities without the package knowing: Names.Remove must be called for each entity removed,
+// e.g. with world.SetEntityRemovedFn(names.Remove), or from the callback of the application. Otherwise the children
+// of a removed entity keep a link to their parent, ignored by Parent, Children and Path, until its EntityId is named again.
+package naming
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/akmonengine/volt"
+)
+
+// SEPARATOR separates the names of the entities in a path.
+const SEPARATOR = "/"
+
+// COMPONENT_NAME is the name of the component holding the names, registered by Enable.
+const COMPONENT_NAME = "naming.Name"
+
+// nameComponent is the name of an entity, and its parent if it has one.
+type nameComponent struct {
+	name      string
+	parent    volt.EntityId
+	hasParent bool
+}
+
+func (component nameComponent) GetComponentId() volt.ComponentId {
+	return volt.AUTO_COMPONENT_ID
+}
+
+// AppendBinary encodes the parent, shifted by one so that zero means none, followed by the name.
+func (component *nameComponent) AppendBinary(buf []byte) ([]byte, error) {
+	var parent uint64
+	if component.hasParent {
+		parent = uint64(component.parent) + 1
+	}
+	buf = binary.AppendUvarint(buf, parent)
+
+	return append(buf, component.name...), nil
+}
+
+func (component *nameComponent) UnmarshalBinary(data []byte) error {
+	parent, n := binary.Uvarint(data)
+	if n <= 0 {
+		return fmt.Errorf("the parent of the name cannot be decoded")
+	}
+
+	component.hasParent = parent > 0
+	component.parent = 0
+	if component.hasParent {
+		component.parent = volt.EntityId(parent - 1)
+	}
+	component.name = string(data[n:])
+
+	return nil
+}
+
+// nameKey identifies a name among its siblings.
+type nameKey struct {
+	parent    volt.EntityId
+	hasParent bool
+	name      string
+}
+
+func (component nameComponent) key() nameKey {
+	return nameKey{parent: component.parent, hasParent: component.hasParent, name: component.name}
+}
+
+// Names holds the names of the entities of a World, see Enable.
+//
+// The names are unique among the children of an entity, and among the roots.
+// Removing an entity removes its name, and its children are detached with Remove, e.g. from the callback of SetEntityRemovedFn.
+type Names struct {
+	world *volt.World
+
+	// keys maps the name and the parent of each entity to the entity, children maps a parent to its children.
+	keys     *volt.Index[nameComponent, nameKey]
+	children *volt.Index[nameComponent, volt.EntityId]
+}
+
+// Enable enables the naming of the entities of world, and returns the Names to use.
+//
+// It registers the component COMPONENT_NAME, sparse and replicated, and indexes it.
+// It must be called once per World; on a World holding names already (e.g. a clone), Enable indexes them again.
+// It returns an error if the component cannot be registered.
+func Enable(world *volt.World) (*Names, error) {
+	err := volt.RegisterComponent[nameComponent](world, &volt.ComponentConfig[nameComponent]{
+		Name:       COMPONENT_NAME,
+		Storage:    volt.SPARSE_STORAGE,
+		Replicated: true,
+		RemapFn:    remapParent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := &Names{world: world}
+	if names.keys, err = volt.CreateIndex(world, nameComponent.key); err != nil {
+		return nil, err
+	}
+	if names.children, err = volt.CreateIndex(world, func(component nameComponent) volt.EntityId {
+		return component.parent
+	}); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// remapParent sets the parent to its new EntityId, or removes it if the parent is not remapped.
+func remapParent(component *nameComponent, entitiesIds map[volt.EntityId]volt.EntityId) {
+	if !component.hasParent {
+		return
+	}
+
+	parent, ok := entitiesIds[component.parent]
+	component.parent = parent
+	component.hasParent = ok
+}
+
+// SetName names the entity, keeping its parent.
+//
+// It returns an error if:
+//   - the entity does not exist
+//   - the name is empty, or holds SEPARATOR
+//   - the name is already used by a sibling of the entity
+func (names *Names) SetName(entityId volt.EntityId, name string) error {
+	if !names.world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
+	}
+	if name == "" || strings.Contains(name, SEPARATOR) {
+		return fmt.Errorf("the name %q is empty or holds %q", name, SEPARATOR)
+	}
+
+	component := nameComponent{name: name}
+	if current := volt.GetComponent[nameComponent](names.world, entityId); current != nil {
+		component = *current
+		component.name = name
+	}
+
+	return names.set(entityId, component)
+}
+
+// SetParent sets the parent of the named entity, so that its path is the path of parentId followed by its name.
+//
+// It returns an error if:
+//   - the entity or its parent has no name
+//   - the parent is the entity itself, or one of its descendants
+//   - the name of the entity is already used by a child of the parent
+func (names *Names) SetParent(entityId volt.EntityId, parentId volt.EntityId) error {
+	current := volt.GetComponent[nameComponent](names.world, entityId)
+	if current == nil {
+		return fmt.Errorf("the entity %d has no name", entityId)
+	}
+	if _, ok := names.Name(parentId); !ok {
+		return fmt.Errorf("the parent %d has no name", parentId)
+	}
+
+	for ancestorId, ok := parentId, true; ok; ancestorId, ok = names.Parent(ancestorId) {
+		if ancestorId == entityId {
+			return fmt.Errorf("the entity %d cannot be a descendant of itself", entityId)
+		}
+	}
+
+	component := *current
+	component.parent = parentId
+	component.hasParent = true
+
+	return names.set(entityId, component)
+}
+
+// RemoveParent makes the named entity a root: its path is then its name.
+//
+// It returns an error if the entity has no name, or if its name is already used by another root.
+func (names *Names) RemoveParent(entityId volt.EntityId) error {
+	current := volt.GetComponent[nameComponent](names.world, entityId)
+	if current == nil {
+		return fmt.Errorf("the entity %d has no name", entityId)
+	}
+
+	return names.set(entityId, nameComponent{name: current.name})
+}
+
+// RemoveName removes the name of the entity.
+//
+// It returns an error if the entity has no name, or if it has named children.
+func (names *Names) RemoveName(entityId volt.EntityId) error {
+	if len(names.Children(entityId)) > 0 {
+		return fmt.Errorf("the entity %d has children: they must be removed or moved beforehand", entityId)
+	}
+
+	return volt.RemoveComponent[nameComponent](names.world, entityId)
+}
+
+// Remove detaches the children of the entity, which become roots, e.g. from the callback of SetEntityRemovedFn.
+//
+// A child keeps its name even if a root has it already: Lookup then returns the one with the lowest EntityId.
+// Without it, the children of a removed entity have no parent for Parent, Children and Path,
+// and are detached once its EntityId is reused and named, so that they never move under an unrelated entity.
+func (names *Names) Remove(entityId volt.EntityId) {
+	for _, childId := range slices.Clone(names.children.Lookup(entityId)) {
+		component := volt.GetComponent[nameComponent](names.world, childId)
+		if component == nil || !component.hasParent || component.parent != entityId {
+			continue
+		}
+
+		// Roots share no unique Index: setting the component cannot fail.
+		_ = volt.SetComponent(names.world, childId, nameComponent{name: component.name})
+	}
+}
+
+func (names *Names) set(entityId volt.EntityId, component nameComponent) error {
+	for _, siblingId := range names.keys.Lookup(component.key()) {
+		if siblingId != entityId {
+			return fmt.Errorf("the name %q is already used by the entity %d", component.name, siblingId)
+		}
+	}
+
+	if volt.GetComponent[nameComponent](names.world, entityId) == nil {
+		// A named parent keeps its name as long as it has children: the ones found belong to a removed entity.
+		names.Remove(entityId)
+		return volt.AddComponent(names.world, entityId, component)
+	}
+
+	return volt.SetComponent(names.world, entityId, component)
+}
+
+// Name returns the name of the entity, or false if it has none.
+func (names *Names) Name(entityId volt.EntityId) (string, bool) {
+	component := volt.GetComponent[nameComponent](names.world, entityId)
+	if component == nil {
+		return "", false
+	}
+
+	return component.name, true
+}
+
+// Parent returns the parent of the entity, or false if it has none or if the parent is removed.
+func (names *Names) Parent(entityId volt.EntityId) (volt.EntityId, bool) {
+	component := volt.GetComponent[nameComponent](names.world, entityId)
+	if component == nil || !component.hasParent {
+		return 0, false
+	}
+	if volt.GetComponent[nameComponent](names.world, component.parent) == nil {
+		return 0, false
+	}
+
+	return component.parent, true
+}
+
+// Children returns the named children of the entity, in ascending order.
+func (names *Names) Children(entityId volt.EntityId) []volt.EntityId {
+	var children []volt.EntityId
+	for _, childId := range slices.Clone(names.children.Lookup(entityId)) {
+		if parentId, ok := names.Parent(childId); ok && parentId == entityId {
+			children = append(children, childId)
+		}
+	}
+	slices.Sort(children)
+
+	return children
+}
+
+// Path returns the names of the entity and its ancestors, from the root, joined by SEPARATOR.
+//
+// It returns false if the entity has no name.
+func (names *Names) Path(entityId volt.EntityId) (string, bool) {
+	var path []string
+	for ok := true; ok; entityId, ok = names.Parent(entityId) {
+		name, named := names.Name(entityId)
+		if !named {
+			break
+		}
+		path = append(path, name)
+	}
+	if len(path) == 0 {
+		return "", false
+	}
+	slices.Reverse(path)
+
+	return strings.Join(path, SEPARATOR), true
+}
+
+// Lookup returns the entity at the path, e.g. "Level/Room1/Door", resolved from the roots.
+//
+// A path without SEPARATOR is the name of a root. If several siblings have the same name,
+// e.g. once merged from another World, the one with the lowest EntityId is returned.
+// It returns false if no entity is at the path.
+func (names *Names) Lookup(path string) (volt.EntityId, bool) {
+	var key nameKey
+	var entityId volt.EntityId
+	for name := range strings.SplitSeq(path, SEPARATOR) {
+		key.name = name
+		entities := names.keys.Lookup(key)
+		if len(entities) == 0 {
+			return 0, false
+		}
+
+		entityId = slices.Min(entities)
+		key = nameKey{parent: entityId, hasParent: true}
+	}
+
+	return entityId, true
+}
@@ -0,0 +1,256 @@
+package naming
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/akmonengine/volt"
+	"github.com/akmonengine/volt/replication"
+)
+
+// createLevel names the entities Level, Level/Room1, Level/Room1/Door, Level/Room2 and Level/Room2/Door.
+func createLevel(t *testing.T, world *volt.World, names *Names) map[string]volt.EntityId {
+	entities := make(map[string]volt.EntityId)
+	for _, path := range []string{"Level", "Level/Room1", "Level/Room1/Door", "Level/Room2", "Level/Room2/Door"} {
+		entityId := world.CreateEntity()
+		i := strings.LastIndex(path, SEPARATOR)
+		if err := names.SetName(entityId, path[i+1:]); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		if i >= 0 {
+			if err := names.SetParent(entityId, entities[path[:i]]); err != nil {
+				t.Fatalf("%s", err.Error())
+			}
+		}
+		entities[path] = entityId
+	}
+
+	return entities
+}
+
+func enable(t *testing.T, world *volt.World) *Names {
+	names, err := Enable(world)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	return names
+}
+
+func assertPaths(t *testing.T, names *Names, entities map[string]volt.EntityId) {
+	t.Helper()
+
+	for path, entityId := range entities {
+		if got, ok := names.Path(entityId); !ok || got != path {
+			t.Errorf("the path of the entity %d should be %q, got %q", entityId, path, got)
+		}
+		if got, ok := names.Lookup(path); !ok || got != entityId {
+			t.Errorf("the path %q should refer to the entity %d, got %d %v", path, entityId, got, ok)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	world := volt.CreateWorld(16)
+	names := enable(t, world)
+	entities := createLevel(t, world, names)
+	assertPaths(t, names, entities)
+
+	level := entities["Level"]
+	if children := names.Children(level); !slices.Equal(children, []volt.EntityId{entities["Level/Room1"], entities["Level/Room2"]}) {
+		t.Errorf("the rooms should be the children of the level, got %v", children)
+	}
+	if _, ok := names.Lookup("Level/Room3"); ok {
+		t.Errorf("an unknown path should not be found")
+	}
+	if _, ok := names.Lookup("Door"); ok {
+		t.Errorf("a path should be resolved from the roots")
+	}
+
+	door := entities["Level/Room1/Door"]
+	if err := names.SetName(door, "Window/Left"); err == nil {
+		t.Errorf("SetName should reject a name holding the separator")
+	}
+	if err := names.SetName(entities["Level/Room2"], "Room1"); err == nil {
+		t.Errorf("SetName should reject the name of a sibling")
+	}
+	if err := names.SetParent(level, door); err == nil {
+		t.Errorf("SetParent should reject a cycle")
+	}
+	if err := names.SetParent(entities["Level/Room2/Door"], entities["Level/Room1"]); err == nil {
+		t.Errorf("SetParent should reject the name of a child of the new parent")
+	}
+	if err := names.RemoveName(level); err == nil {
+		t.Errorf("RemoveName should reject an entity with children")
+	}
+
+	if err := names.SetName(door, "Window"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := names.RemoveParent(door); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if path, _ := names.Path(door); path != "Window" {
+		t.Errorf("the entity should be a root once its parent removed, got %q", path)
+	}
+
+	// Removing an entity removes its name.
+	world.RemoveEntity(door)
+	if _, ok := names.Lookup("Window"); ok {
+		t.Errorf("the name of a removed entity should be removed")
+	}
+	entityId := world.CreateEntity()
+	if err := names.SetName(entityId, "Window"); err != nil {
+		t.Errorf("the name of a removed entity should be free, got %s", err.Error())
+	}
+	if _, ok := names.Name(world.CreateEntity()); ok {
+		t.Errorf("an entity should have no name by default")
+	}
+}
+
+func TestNames_RemoveParent(t *testing.T) {
+	world := volt.CreateWorld(16)
+	names := enable(t, world)
+	entities := createLevel(t, world, names)
+
+	// Without Remove, the children of a removed entity have no parent, and are detached once its EntityId is reused.
+	room1 := entities["Level/Room1"]
+	door := entities["Level/Room1/Door"]
+	world.RemoveEntity(room1)
+	if _, ok := names.Parent(door); ok {
+		t.Errorf("the parent of the door should be removed")
+	}
+	if path, _ := names.Path(door); path != "Door" {
+		t.Errorf("the path of the door should be its name, got %q", path)
+	}
+
+	inventory := world.CreateEntity()
+	if inventory != room1 {
+		t.Fatalf("the EntityId %d should be reused, got %d", room1, inventory)
+	}
+	if err := names.SetName(inventory, "Inventory"); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if children := names.Children(inventory); len(children) > 0 {
+		t.Errorf("the entity reusing the EntityId should have no child, got %v", children)
+	}
+	if _, ok := names.Lookup("Inventory/Door"); ok {
+		t.Errorf("the door should not move under the entity reusing the EntityId")
+	}
+	if entityId, ok := names.Lookup("Door"); !ok || entityId != door {
+		t.Errorf("the door should be a root, got %d", entityId)
+	}
+
+	// With Remove as the callback, the children are detached at once.
+	world.SetEntityRemovedFn(names.Remove)
+	world.RemoveEntity(entities["Level/Room2"])
+	if entityId, ok := names.Lookup("Door"); !ok || entityId != door {
+		t.Errorf("the lowest EntityId should be found among the roots of the same name, got %d", entityId)
+	}
+	if _, ok := names.Parent(entities["Level/Room2/Door"]); ok {
+		t.Errorf("the door of the removed room should be a root")
+	}
+	if count := len(names.keys.Lookup(nameKey{name: "Door"})); count != 2 {
+		t.Errorf("both doors should be roots, got %d", count)
+	}
+}
+
+func TestNames_Snapshot(t *testing.T) {
+	world := volt.CreateWorld(16)
+	names := enable(t, world)
+	entities := createLevel(t, world, names)
+
+	snapshot := world.Snapshot()
+	world.RemoveEntity(entities["Level/Room2/Door"])
+	names.SetName(entities["Level/Room1"], "Hall")
+	if err := world.Restore(snapshot); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	assertPaths(t, names, entities)
+
+	clone := world.Clone()
+	assertPaths(t, enable(t, clone), entities)
+}
+
+func TestNames_Merge(t *testing.T) {
+	scene := volt.CreateWorld(16)
+	sceneEntities := createLevel(t, scene, enable(t, scene))
+
+	world := volt.CreateWorld(16)
+	world.CreateEntity()
+	names := enable(t, world)
+
+	entitiesIds, err := world.Merge(scene)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	entities := make(map[string]volt.EntityId)
+	for path, entityId := range sceneEntities {
+		entities[path] = entitiesIds[entityId]
+	}
+	assertPaths(t, names, entities)
+}
+
+func TestNames_Replication(t *testing.T) {
+	serverWorld := volt.CreateWorld(16)
+	serverNames := enable(t, serverWorld)
+	serverEntities := createLevel(t, serverWorld, serverNames)
+
+	clientWorld := volt.CreateWorld(16)
+	clientWorld.CreateEntity()
+	clientNames := enable(t, clientWorld)
+
+	server := replication.NewServer(serverWorld, 4)
+	client := replication.NewClient(clientWorld)
+	spawned := 0
+	client.SetEntitySpawnedFn(func(serverEntityId volt.EntityId, entityId volt.EntityId) {
+		// The parent is remapped before the callback.
+		if _, ok := clientNames.Path(entityId); !ok {
+			t.Errorf("the entity %d should be named once spawned", entityId)
+		}
+		spawned++
+	})
+
+	replicate := func(tick uint64) {
+		if err := server.Capture(tick); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		data, err := server.Delta(client.Tick(), tick)
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		if err = client.Apply(data); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+	}
+
+	// The children are created before their parent, in the order of the server.
+	room := serverEntities["Level/Room2"]
+	serverNames.RemoveParent(room)
+	serverNames.SetParent(serverEntities["Level"], room)
+	serverNames.SetName(room, "Building")
+	replicate(1)
+
+	for path, serverEntityId := range map[string]volt.EntityId{
+		"Building/Level":            serverEntities["Level"],
+		"Building/Level/Room1/Door": serverEntities["Level/Room1/Door"],
+		"Building/Door":             serverEntities["Level/Room2/Door"],
+	} {
+		entityId, _ := client.EntityId(serverEntityId)
+		if got, ok := clientNames.Lookup(path); !ok || got != entityId {
+			t.Errorf("the path %q should refer to the entity %d on the client, got %d %v", path, entityId, got, ok)
+		}
+	}
+	if spawned != 5 {
+		t.Errorf("the 5 entities should be spawned, got %d", spawned)
+	}
+
+	serverNames.SetName(serverEntities["Level/Room1"], "Hall")
+	replicate(2)
+	entityId, _ := client.EntityId(serverEntities["Level/Room1/Door"])
+	if path, _ := clientNames.Path(entityId); path != "Building/Level/Hall/Door" {
+		t.Errorf("the renaming should be replicated, got %q", path)
+	}
+}
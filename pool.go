@@ -1,8 +1,50 @@
 package volt
 
-type pool struct {
+// EntityRange is a range of EntityId [First;Last] reserved in a World, see World.ReserveEntityRange.
+//
+// The ids of a range are never handed out by CreateEntity: they are created either explicitly
+// with CreateEntityWithId (e.g. the ids assigned by a remote authority), or with CreateEntityInRange
+// (e.g. the entities predicted locally, kept apart from the ids of the server).
+type EntityRange struct {
+	First EntityId
+	Last  EntityId
+
 	ids  []EntityId
-	next EntityId
+	used uint64
+}
+
+func (entityRange *EntityRange) contains(entityId EntityId) bool {
+	return entityId >= entityRange.First && entityId <= entityRange.Last
+}
+
+// get returns an id of the range: a recycled one, or the next one never handed out.
+// The boolean is false if the range is exhausted.
+func (entityRange *EntityRange) get() (EntityId, bool) {
+	if len(entityRange.ids) > 0 {
+		entityId := entityRange.ids[len(entityRange.ids)-1]
+		entityRange.ids = entityRange.ids[:len(entityRange.ids)-1]
+
+		return entityId, true
+	}
+
+	if entityRange.used > uint64(entityRange.Last-entityRange.First) {
+		return 0, false
+	}
+
+	entityId := entityRange.First + EntityId(entityRange.used)
+	entityRange.used++
+
+	return entityId, true
+}
+
+// pool hands out the EntityId.
+//
+// An id handed out may have been created meanwhile with World.CreateEntityWithId:
+// the World skips the ids already live, rather than searching them in the free lists.
+type pool struct {
+	ids    []EntityId
+	next   EntityId
+	ranges []*EntityRange
 }
 
 func (pool *pool) Get() EntityId {
@@ -11,6 +53,11 @@ func (pool *pool) Get() EntityId {
 		entityId = pool.ids[len(pool.ids)-1]
 		pool.ids = pool.ids[:len(pool.ids)-1]
 	} else {
+		// The ids of the reserved ranges are skipped.
+		for entityRange := pool.rangeOf(pool.next); entityRange != nil; entityRange = pool.rangeOf(pool.next) {
+			pool.next = entityRange.Last + 1
+		}
+
 		entityId = pool.next
 		pool.next++
 	}
@@ -19,9 +66,34 @@ func (pool *pool) Get() EntityId {
 }
 
 func (pool *pool) Recycle(id EntityId) {
+	if entityRange := pool.rangeOf(id); entityRange != nil {
+		entityRange.ids = append(entityRange.ids, id)
+		return
+	}
+
 	pool.ids = append(pool.ids, id)
 }
 
-func (pool *pool) Count() int {
-	return len(pool.ids)
+// rangeOf returns the reserved range containing entityId, or nil.
+func (pool *pool) rangeOf(entityId EntityId) *EntityRange {
+	for _, entityRange := range pool.ranges {
+		if entityRange.contains(entityId) {
+			return entityRange
+		}
+	}
+
+	return nil
+}
+
+// reserve adds the range to the pool, and withdraws its ids from the free list.
+func (pool *pool) reserve(entityRange *EntityRange) {
+	pool.ranges = append(pool.ranges, entityRange)
+
+	ids := pool.ids[:0]
+	for _, entityId := range pool.ids {
+		if !entityRange.contains(entityId) {
+			ids = append(ids, entityId)
+		}
+	}
+	pool.ids = ids
 }
@@ -0,0 +1,4004 @@
+// Code generated by voltgen. DO NOT EDIT.
+
+package volt
+
+import (
+	"iter"
+	"math"
+)
+
+// Query for 1 component type.
+type Query1[A ComponentInterface] struct {
+	World              *World
+	componentsIds      []ComponentId
+	queryConfiguration QueryConfiguration
+
+	cache filterCache
+	// sorted is the buffer of the results of ForeachSorted, reused from one call to the next.
+	sorted []QueryResult1[A]
+}
+
+// Result returned for Query1.
+type QueryResult1[A ComponentInterface] struct {
+	EntityId EntityId
+	A        *A
+}
+
+type queryResultChunk1[A ComponentInterface] struct {
+	EntityId []EntityId
+	A        []A
+}
+
+// CreateQuery1 returns a new Query1, with component A.
+func CreateQuery1[A ComponentInterface](world *World, queryConfiguration QueryConfiguration) Query1[A] {
+	componentsIds := []ComponentId{ComponentIdOf[A](world)}
+	return Query1[A]{
+		World:              world,
+		componentsIds:      componentsIds,
+		queryConfiguration: queryConfiguration,
+		cache:              newFilterCache(world, componentsIds, queryConfiguration),
+	}
+}
+
+func (query *Query1[A]) GetComponentsIds() []ComponentId {
+	return query.componentsIds
+}
+
+func (query *Query1[A]) filter() []archetypeId {
+	return query.cache.resolve(query.World)
+}
+
+// Count returns the total of entities fetched for Query1.
+func (query *Query1[A]) Count() int {
+	return query.cache.count(query.World)
+}
+
+// GetEntitiesIds returns a slice of all the EntityId fetched for Query1.
+func (query *Query1[A]) GetEntitiesIds() []EntityId {
+	return query.cache.entities(query.World)
+}
+
+// Matches reports whether the entity is fetched by Query1, e.g. to filter the entities found by another mean.
+func (query *Query1[A]) Matches(entityId EntityId) bool {
+	return query.cache.matches(query.World, entityId)
+}
+
+// Foreach returns an iterator of QueryResult1 for all the entities with component A
+// to which filterFn function returns true.
+func (query *Query1[A]) Foreach(filterFn func(QueryResult1[A]) bool) iter.Seq[QueryResult1[A]] {
+	return func(yield func(QueryResult1[A]) bool) {
+		storageA := getStorage[A](query.World)
+		archetypesIds := query.filter()
+
+		// endIteration is not deferred, and the sparse components are iterated apart,
+		// so that the loop over the columns can be inlined in the range over Foreach.
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		yield = guardYield(query.World, query.componentsIds, archetypesIds, yield)
+		if query.cache.sparseIds != nil || storageA.getSparse() != nil {
+			query.foreachSparse(archetypesIds, filterFn, yield)
+			query.World.endIteration(query.componentsIds, archetypesIds, false)
+			return
+		}
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			result := query.sharedResult(archetype.Id)
+			for i, entityId := range archetype.entities {
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					continue
+				}
+
+				if !yield(result) {
+					query.World.endIteration(query.componentsIds, archetypesIds, false)
+					return
+				}
+			}
+		}
+		query.World.endIteration(query.componentsIds, archetypesIds, false)
+	}
+}
+
+// foreachSparse yields the results of Foreach for a query over sparse components, looked up for each entity.
+func (query *Query1[A]) foreachSparse(archetypesIds []archetypeId, filterFn func(QueryResult1[A]) bool, yield func(QueryResult1[A]) bool) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	sparseIds := query.cache.sparseIds
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+
+		result := query.sharedResult(archetype.Id)
+		for i, entityId := range archetype.entities {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				continue
+			}
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				continue
+			}
+
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// sharedResult returns a QueryResult1 holding the values of the shared components of the archetype,
+// the same for all its entities.
+func (query *Query1[A]) sharedResult(archetypeId archetypeId) QueryResult1[A] {
+	var result QueryResult1[A]
+	result.A = getStorage[A](query.World).getShared().get(archetypeId)
+
+	return result
+}
+
+// ForeachSorted returns an iterator of QueryResult1 for all the entities with component A,
+// in the order defined by less, e.g. back to front for the rendering of transparent sprites.
+// The entities with an equal order keep the order of Foreach.
+//
+// The results are sorted in a buffer of the query reused from one call to the next: the components are not copied.
+func (query *Query1[A]) ForeachSorted(less func(a, b QueryResult1[A]) bool) iter.Seq[QueryResult1[A]] {
+	return func(yield func(QueryResult1[A]) bool) {
+		query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+		defer clear(query.sorted)
+
+		archetypesIds := query.filter()
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+		for _, result := range query.sorted {
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// SortArchetypes moves the rows of the archetypes fetched by Query1, so that Foreach iterates
+// the entities of each archetype in the order defined by less, without the cost of ForeachSorted.
+// The archetypes are still iterated in their order of creation.
+//
+// The order holds until the next structural change: adding or removing an entity or a component moves rows.
+// SortArchetypes must not be called while a query is iterated, and has no effect on a deterministic World, see SetDeterministic.
+func (query *Query1[A]) SortArchetypes(less func(a, b QueryResult1[A]) bool) {
+	query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+	defer clear(query.sorted)
+
+	sortRows(query.World, query.sorted, func(result QueryResult1[A]) EntityId {
+		return result.EntityId
+	})
+}
+
+// Task executes fn in parallel across workersCount goroutines for all entities matching the query.
+// Each entity's components are passed to fn through QueryResult1.
+// If filterFn is provided and returns false for an entity, that entity is skipped.
+//
+// The workersCount parameter determines the number of parallel workers.
+// Data is automatically partitioned across workers for optimal performance.
+func (query *Query1[A]) Task(workersCount int, filterFn func(QueryResult1[A]) bool, fn func(result QueryResult1[A])) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	sharedA := storageA.getShared()
+	sparseIds := query.cache.sparseIds
+	// Without sparse component, the components are read from the columns only, as in a tight loop.
+	tableOnly := sparseIds == nil && sparseA == nil
+	archetypesIds := query.filter()
+
+	query.World.beginIteration(query.componentsIds, archetypesIds, true)
+	defer query.World.endIteration(query.componentsIds, archetypesIds, true)
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+
+		// The shared components are the same for all the entities of the archetype.
+		var shared QueryResult1[A]
+		shared.A = sharedA.get(archetype.Id)
+
+		if tableOnly {
+			task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+				result := shared
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					return
+				}
+
+				fn(result)
+			})
+			continue
+		}
+
+		task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				return
+			}
+
+			result := shared
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				return
+			}
+
+			fn(result)
+		})
+	}
+}
+
+// ForeachChannel returns a channel of iterators of QueryResult1 for all the entities with component A
+// to which filterFn function returns true.
+// The parameter chunkSize defines the size of each iterators.
+//
+// Deprecated: ForeachChannel is deprecated and will be removed in a future version.
+// Use Task(workersCount, filterFn, fn) instead, which offers better performance
+// and a simpler API for parallel iteration.
+func (query *Query1[A]) ForeachChannel(chunkSize int, filterFn func(QueryResult1[A]) bool) <-chan iter.Seq[QueryResult1[A]] {
+	if chunkSize == 0 {
+		panic("chunk size must be greater than zero")
+	}
+
+	channelsCount := math.Ceil(float64(query.Count()) / float64(chunkSize))
+	channel := make(chan iter.Seq[QueryResult1[A]], int(channelsCount))
+
+	go func() {
+		defer close(channel)
+
+		storageA := getStorage[A](query.World)
+		sparseA := storageA.getSparse()
+		sharedA := storageA.getShared()
+		sparseIds := query.cache.sparseIds
+		// Without sparse component, the components are read from the columns only, as in a tight loop.
+		tableOnly := sparseIds == nil && sparseA == nil
+		archetypesIds := query.filter()
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			var shared QueryResult1[A]
+			shared.A = sharedA.get(archetype.Id)
+
+			for i := 0; i < len(archetype.entities); i += chunkSize {
+				result := queryResultChunk1[A]{}
+				end := min(chunkSize, len(archetype.entities[i:]))
+
+				// Set the capacity of each chunk so that appending to a chunk does
+				// not modify the original slice.
+				result.EntityId = archetype.entities[i : i+end : i+end]
+				if sliceA != nil {
+					result.A = sliceA[i : i+end : i+end]
+				}
+
+				channel <- func(yield func(QueryResult1[A]) bool) {
+					query.World.beginIteration(query.componentsIds, archetypesIds, false)
+					defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+					queryResult := shared
+					if tableOnly {
+						for k, entityId := range result.EntityId {
+							queryResult.EntityId = entityId
+							if result.A != nil {
+								queryResult.A = &result.A[k]
+							}
+
+							if filterFn != nil && !filterFn(queryResult) {
+								continue
+							}
+
+							if !yield(queryResult) {
+								return
+							}
+						}
+						return
+					}
+
+					for k, entityId := range result.EntityId {
+						if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+							continue
+						}
+
+						queryResult.EntityId = entityId
+
+						if result.A != nil {
+							queryResult.A = &result.A[k]
+						} else if sparseA != nil {
+							queryResult.A = sparseA.get(entityId)
+						}
+
+						if filterFn != nil && !filterFn(queryResult) {
+							continue
+						}
+
+						if !yield(queryResult) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	return channel
+}
+
+// Query for 2 components type.
+type Query2[A, B ComponentInterface] struct {
+	World              *World
+	componentsIds      []ComponentId
+	queryConfiguration QueryConfiguration
+
+	cache filterCache
+	// sorted is the buffer of the results of ForeachSorted, reused from one call to the next.
+	sorted []QueryResult2[A, B]
+}
+
+// Result returned for Query2.
+type QueryResult2[A, B ComponentInterface] struct {
+	EntityId EntityId
+	A        *A
+	B        *B
+}
+
+type queryResultChunk2[A, B ComponentInterface] struct {
+	EntityId []EntityId
+	A        []A
+	B        []B
+}
+
+// CreateQuery2 returns a new Query2, with components A, B.
+func CreateQuery2[A, B ComponentInterface](world *World, queryConfiguration QueryConfiguration) Query2[A, B] {
+	componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world)}
+	return Query2[A, B]{
+		World:              world,
+		componentsIds:      componentsIds,
+		queryConfiguration: queryConfiguration,
+		cache:              newFilterCache(world, componentsIds, queryConfiguration),
+	}
+}
+
+func (query *Query2[A, B]) GetComponentsIds() []ComponentId {
+	return query.componentsIds
+}
+
+func (query *Query2[A, B]) filter() []archetypeId {
+	return query.cache.resolve(query.World)
+}
+
+// Count returns the total of entities fetched for Query2.
+func (query *Query2[A, B]) Count() int {
+	return query.cache.count(query.World)
+}
+
+// GetEntitiesIds returns a slice of all the EntityId fetched for Query2.
+func (query *Query2[A, B]) GetEntitiesIds() []EntityId {
+	return query.cache.entities(query.World)
+}
+
+// Matches reports whether the entity is fetched by Query2, e.g. to filter the entities found by another mean.
+func (query *Query2[A, B]) Matches(entityId EntityId) bool {
+	return query.cache.matches(query.World, entityId)
+}
+
+// Foreach returns an iterator of QueryResult2 for all the entities with components A, B
+// to which filterFn function returns true.
+func (query *Query2[A, B]) Foreach(filterFn func(QueryResult2[A, B]) bool) iter.Seq[QueryResult2[A, B]] {
+	return func(yield func(QueryResult2[A, B]) bool) {
+		storageA := getStorage[A](query.World)
+		storageB := getStorage[B](query.World)
+		archetypesIds := query.filter()
+
+		// endIteration is not deferred, and the sparse components are iterated apart,
+		// so that the loop over the columns can be inlined in the range over Foreach.
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		yield = guardYield(query.World, query.componentsIds, archetypesIds, yield)
+		if query.cache.sparseIds != nil || storageA.getSparse() != nil || storageB.getSparse() != nil {
+			query.foreachSparse(archetypesIds, filterFn, yield)
+			query.World.endIteration(query.componentsIds, archetypesIds, false)
+			return
+		}
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			result := query.sharedResult(archetype.Id)
+			for i, entityId := range archetype.entities {
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					continue
+				}
+
+				if !yield(result) {
+					query.World.endIteration(query.componentsIds, archetypesIds, false)
+					return
+				}
+			}
+		}
+		query.World.endIteration(query.componentsIds, archetypesIds, false)
+	}
+}
+
+// foreachSparse yields the results of Foreach for a query over sparse components, looked up for each entity.
+func (query *Query2[A, B]) foreachSparse(archetypesIds []archetypeId, filterFn func(QueryResult2[A, B]) bool, yield func(QueryResult2[A, B]) bool) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	sparseIds := query.cache.sparseIds
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+
+		result := query.sharedResult(archetype.Id)
+		for i, entityId := range archetype.entities {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				continue
+			}
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				continue
+			}
+
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// sharedResult returns a QueryResult2 holding the values of the shared components of the archetype,
+// the same for all its entities.
+func (query *Query2[A, B]) sharedResult(archetypeId archetypeId) QueryResult2[A, B] {
+	var result QueryResult2[A, B]
+	result.A = getStorage[A](query.World).getShared().get(archetypeId)
+	result.B = getStorage[B](query.World).getShared().get(archetypeId)
+
+	return result
+}
+
+// ForeachSorted returns an iterator of QueryResult2 for all the entities with components A, B,
+// in the order defined by less, e.g. back to front for the rendering of transparent sprites.
+// The entities with an equal order keep the order of Foreach.
+//
+// The results are sorted in a buffer of the query reused from one call to the next: the components are not copied.
+func (query *Query2[A, B]) ForeachSorted(less func(a, b QueryResult2[A, B]) bool) iter.Seq[QueryResult2[A, B]] {
+	return func(yield func(QueryResult2[A, B]) bool) {
+		query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+		defer clear(query.sorted)
+
+		archetypesIds := query.filter()
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+		for _, result := range query.sorted {
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// SortArchetypes moves the rows of the archetypes fetched by Query2, so that Foreach iterates
+// the entities of each archetype in the order defined by less, without the cost of ForeachSorted.
+// The archetypes are still iterated in their order of creation.
+//
+// The order holds until the next structural change: adding or removing an entity or a component moves rows.
+// SortArchetypes must not be called while a query is iterated, and has no effect on a deterministic World, see SetDeterministic.
+func (query *Query2[A, B]) SortArchetypes(less func(a, b QueryResult2[A, B]) bool) {
+	query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+	defer clear(query.sorted)
+
+	sortRows(query.World, query.sorted, func(result QueryResult2[A, B]) EntityId {
+		return result.EntityId
+	})
+}
+
+// Task executes fn in parallel across workersCount goroutines for all entities matching the query.
+// Each entity's components are passed to fn through QueryResult2.
+// If filterFn is provided and returns false for an entity, that entity is skipped.
+//
+// The workersCount parameter determines the number of parallel workers.
+// Data is automatically partitioned across workers for optimal performance.
+func (query *Query2[A, B]) Task(workersCount int, filterFn func(QueryResult2[A, B]) bool, fn func(result QueryResult2[A, B])) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	sharedA := storageA.getShared()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	sharedB := storageB.getShared()
+	sparseIds := query.cache.sparseIds
+	// Without sparse component, the components are read from the columns only, as in a tight loop.
+	tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil
+	archetypesIds := query.filter()
+
+	query.World.beginIteration(query.componentsIds, archetypesIds, true)
+	defer query.World.endIteration(query.componentsIds, archetypesIds, true)
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+
+		// The shared components are the same for all the entities of the archetype.
+		var shared QueryResult2[A, B]
+		shared.A = sharedA.get(archetype.Id)
+		shared.B = sharedB.get(archetype.Id)
+
+		if tableOnly {
+			task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+				result := shared
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					return
+				}
+
+				fn(result)
+			})
+			continue
+		}
+
+		task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				return
+			}
+
+			result := shared
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				return
+			}
+
+			fn(result)
+		})
+	}
+}
+
+// ForeachChannel returns a channel of iterators of QueryResult2 for all the entities with components A, B
+// to which filterFn function returns true.
+// The parameter chunkSize defines the size of each iterators.
+//
+// Deprecated: ForeachChannel is deprecated and will be removed in a future version.
+// Use Task(workersCount, filterFn, fn) instead, which offers better performance
+// and a simpler API for parallel iteration.
+func (query *Query2[A, B]) ForeachChannel(chunkSize int, filterFn func(QueryResult2[A, B]) bool) <-chan iter.Seq[QueryResult2[A, B]] {
+	if chunkSize == 0 {
+		panic("chunk size must be greater than zero")
+	}
+
+	channelsCount := math.Ceil(float64(query.Count()) / float64(chunkSize))
+	channel := make(chan iter.Seq[QueryResult2[A, B]], int(channelsCount))
+
+	go func() {
+		defer close(channel)
+
+		storageA := getStorage[A](query.World)
+		sparseA := storageA.getSparse()
+		sharedA := storageA.getShared()
+		storageB := getStorage[B](query.World)
+		sparseB := storageB.getSparse()
+		sharedB := storageB.getShared()
+		sparseIds := query.cache.sparseIds
+		// Without sparse component, the components are read from the columns only, as in a tight loop.
+		tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil
+		archetypesIds := query.filter()
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			var shared QueryResult2[A, B]
+			shared.A = sharedA.get(archetype.Id)
+			shared.B = sharedB.get(archetype.Id)
+
+			for i := 0; i < len(archetype.entities); i += chunkSize {
+				result := queryResultChunk2[A, B]{}
+				end := min(chunkSize, len(archetype.entities[i:]))
+
+				// Set the capacity of each chunk so that appending to a chunk does
+				// not modify the original slice.
+				result.EntityId = archetype.entities[i : i+end : i+end]
+				if sliceA != nil {
+					result.A = sliceA[i : i+end : i+end]
+				}
+				if sliceB != nil {
+					result.B = sliceB[i : i+end : i+end]
+				}
+
+				channel <- func(yield func(QueryResult2[A, B]) bool) {
+					query.World.beginIteration(query.componentsIds, archetypesIds, false)
+					defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+					queryResult := shared
+					if tableOnly {
+						for k, entityId := range result.EntityId {
+							queryResult.EntityId = entityId
+							if result.A != nil {
+								queryResult.A = &result.A[k]
+							}
+							if result.B != nil {
+								queryResult.B = &result.B[k]
+							}
+
+							if filterFn != nil && !filterFn(queryResult) {
+								continue
+							}
+
+							if !yield(queryResult) {
+								return
+							}
+						}
+						return
+					}
+
+					for k, entityId := range result.EntityId {
+						if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+							continue
+						}
+
+						queryResult.EntityId = entityId
+
+						if result.A != nil {
+							queryResult.A = &result.A[k]
+						} else if sparseA != nil {
+							queryResult.A = sparseA.get(entityId)
+						}
+						if result.B != nil {
+							queryResult.B = &result.B[k]
+						} else if sparseB != nil {
+							queryResult.B = sparseB.get(entityId)
+						}
+
+						if filterFn != nil && !filterFn(queryResult) {
+							continue
+						}
+
+						if !yield(queryResult) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	return channel
+}
+
+// Query for 3 components type.
+type Query3[A, B, C ComponentInterface] struct {
+	World              *World
+	componentsIds      []ComponentId
+	queryConfiguration QueryConfiguration
+
+	cache filterCache
+	// sorted is the buffer of the results of ForeachSorted, reused from one call to the next.
+	sorted []QueryResult3[A, B, C]
+}
+
+// Result returned for Query3.
+type QueryResult3[A, B, C ComponentInterface] struct {
+	EntityId EntityId
+	A        *A
+	B        *B
+	C        *C
+}
+
+type queryResultChunk3[A, B, C ComponentInterface] struct {
+	EntityId []EntityId
+	A        []A
+	B        []B
+	C        []C
+}
+
+// CreateQuery3 returns a new Query3, with components A, B, C.
+func CreateQuery3[A, B, C ComponentInterface](world *World, queryConfiguration QueryConfiguration) Query3[A, B, C] {
+	componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world)}
+	return Query3[A, B, C]{
+		World:              world,
+		componentsIds:      componentsIds,
+		queryConfiguration: queryConfiguration,
+		cache:              newFilterCache(world, componentsIds, queryConfiguration),
+	}
+}
+
+func (query *Query3[A, B, C]) GetComponentsIds() []ComponentId {
+	return query.componentsIds
+}
+
+func (query *Query3[A, B, C]) filter() []archetypeId {
+	return query.cache.resolve(query.World)
+}
+
+// Count returns the total of entities fetched for Query3.
+func (query *Query3[A, B, C]) Count() int {
+	return query.cache.count(query.World)
+}
+
+// GetEntitiesIds returns a slice of all the EntityId fetched for Query3.
+func (query *Query3[A, B, C]) GetEntitiesIds() []EntityId {
+	return query.cache.entities(query.World)
+}
+
+// Matches reports whether the entity is fetched by Query3, e.g. to filter the entities found by another mean.
+func (query *Query3[A, B, C]) Matches(entityId EntityId) bool {
+	return query.cache.matches(query.World, entityId)
+}
+
+// Foreach returns an iterator of QueryResult3 for all the entities with components A, B, C
+// to which filterFn function returns true.
+func (query *Query3[A, B, C]) Foreach(filterFn func(QueryResult3[A, B, C]) bool) iter.Seq[QueryResult3[A, B, C]] {
+	return func(yield func(QueryResult3[A, B, C]) bool) {
+		storageA := getStorage[A](query.World)
+		storageB := getStorage[B](query.World)
+		storageC := getStorage[C](query.World)
+		archetypesIds := query.filter()
+
+		// endIteration is not deferred, and the sparse components are iterated apart,
+		// so that the loop over the columns can be inlined in the range over Foreach.
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		yield = guardYield(query.World, query.componentsIds, archetypesIds, yield)
+		if query.cache.sparseIds != nil || storageA.getSparse() != nil || storageB.getSparse() != nil || storageC.getSparse() != nil {
+			query.foreachSparse(archetypesIds, filterFn, yield)
+			query.World.endIteration(query.componentsIds, archetypesIds, false)
+			return
+		}
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			result := query.sharedResult(archetype.Id)
+			for i, entityId := range archetype.entities {
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					continue
+				}
+
+				if !yield(result) {
+					query.World.endIteration(query.componentsIds, archetypesIds, false)
+					return
+				}
+			}
+		}
+		query.World.endIteration(query.componentsIds, archetypesIds, false)
+	}
+}
+
+// foreachSparse yields the results of Foreach for a query over sparse components, looked up for each entity.
+func (query *Query3[A, B, C]) foreachSparse(archetypesIds []archetypeId, filterFn func(QueryResult3[A, B, C]) bool, yield func(QueryResult3[A, B, C]) bool) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	sparseIds := query.cache.sparseIds
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+
+		result := query.sharedResult(archetype.Id)
+		for i, entityId := range archetype.entities {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				continue
+			}
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				continue
+			}
+
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// sharedResult returns a QueryResult3 holding the values of the shared components of the archetype,
+// the same for all its entities.
+func (query *Query3[A, B, C]) sharedResult(archetypeId archetypeId) QueryResult3[A, B, C] {
+	var result QueryResult3[A, B, C]
+	result.A = getStorage[A](query.World).getShared().get(archetypeId)
+	result.B = getStorage[B](query.World).getShared().get(archetypeId)
+	result.C = getStorage[C](query.World).getShared().get(archetypeId)
+
+	return result
+}
+
+// ForeachSorted returns an iterator of QueryResult3 for all the entities with components A, B, C,
+// in the order defined by less, e.g. back to front for the rendering of transparent sprites.
+// The entities with an equal order keep the order of Foreach.
+//
+// The results are sorted in a buffer of the query reused from one call to the next: the components are not copied.
+func (query *Query3[A, B, C]) ForeachSorted(less func(a, b QueryResult3[A, B, C]) bool) iter.Seq[QueryResult3[A, B, C]] {
+	return func(yield func(QueryResult3[A, B, C]) bool) {
+		query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+		defer clear(query.sorted)
+
+		archetypesIds := query.filter()
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+		for _, result := range query.sorted {
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// SortArchetypes moves the rows of the archetypes fetched by Query3, so that Foreach iterates
+// the entities of each archetype in the order defined by less, without the cost of ForeachSorted.
+// The archetypes are still iterated in their order of creation.
+//
+// The order holds until the next structural change: adding or removing an entity or a component moves rows.
+// SortArchetypes must not be called while a query is iterated, and has no effect on a deterministic World, see SetDeterministic.
+func (query *Query3[A, B, C]) SortArchetypes(less func(a, b QueryResult3[A, B, C]) bool) {
+	query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+	defer clear(query.sorted)
+
+	sortRows(query.World, query.sorted, func(result QueryResult3[A, B, C]) EntityId {
+		return result.EntityId
+	})
+}
+
+// Task executes fn in parallel across workersCount goroutines for all entities matching the query.
+// Each entity's components are passed to fn through QueryResult3.
+// If filterFn is provided and returns false for an entity, that entity is skipped.
+//
+// The workersCount parameter determines the number of parallel workers.
+// Data is automatically partitioned across workers for optimal performance.
+func (query *Query3[A, B, C]) Task(workersCount int, filterFn func(QueryResult3[A, B, C]) bool, fn func(result QueryResult3[A, B, C])) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	sharedA := storageA.getShared()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	sharedB := storageB.getShared()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	sharedC := storageC.getShared()
+	sparseIds := query.cache.sparseIds
+	// Without sparse component, the components are read from the columns only, as in a tight loop.
+	tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil
+	archetypesIds := query.filter()
+
+	query.World.beginIteration(query.componentsIds, archetypesIds, true)
+	defer query.World.endIteration(query.componentsIds, archetypesIds, true)
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+
+		// The shared components are the same for all the entities of the archetype.
+		var shared QueryResult3[A, B, C]
+		shared.A = sharedA.get(archetype.Id)
+		shared.B = sharedB.get(archetype.Id)
+		shared.C = sharedC.get(archetype.Id)
+
+		if tableOnly {
+			task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+				result := shared
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					return
+				}
+
+				fn(result)
+			})
+			continue
+		}
+
+		task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				return
+			}
+
+			result := shared
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				return
+			}
+
+			fn(result)
+		})
+	}
+}
+
+// ForeachChannel returns a channel of iterators of QueryResult3 for all the entities with components A, B, C
+// to which filterFn function returns true.
+// The parameter chunkSize defines the size of each iterators.
+//
+// Deprecated: ForeachChannel is deprecated and will be removed in a future version.
+// Use Task(workersCount, filterFn, fn) instead, which offers better performance
+// and a simpler API for parallel iteration.
+func (query *Query3[A, B, C]) ForeachChannel(chunkSize int, filterFn func(QueryResult3[A, B, C]) bool) <-chan iter.Seq[QueryResult3[A, B, C]] {
+	if chunkSize == 0 {
+		panic("chunk size must be greater than zero")
+	}
+
+	channelsCount := math.Ceil(float64(query.Count()) / float64(chunkSize))
+	channel := make(chan iter.Seq[QueryResult3[A, B, C]], int(channelsCount))
+
+	go func() {
+		defer close(channel)
+
+		storageA := getStorage[A](query.World)
+		sparseA := storageA.getSparse()
+		sharedA := storageA.getShared()
+		storageB := getStorage[B](query.World)
+		sparseB := storageB.getSparse()
+		sharedB := storageB.getShared()
+		storageC := getStorage[C](query.World)
+		sparseC := storageC.getSparse()
+		sharedC := storageC.getShared()
+		sparseIds := query.cache.sparseIds
+		// Without sparse component, the components are read from the columns only, as in a tight loop.
+		tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil
+		archetypesIds := query.filter()
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			var shared QueryResult3[A, B, C]
+			shared.A = sharedA.get(archetype.Id)
+			shared.B = sharedB.get(archetype.Id)
+			shared.C = sharedC.get(archetype.Id)
+
+			for i := 0; i < len(archetype.entities); i += chunkSize {
+				result := queryResultChunk3[A, B, C]{}
+				end := min(chunkSize, len(archetype.entities[i:]))
+
+				// Set the capacity of each chunk so that appending to a chunk does
+				// not modify the original slice.
+				result.EntityId = archetype.entities[i : i+end : i+end]
+				if sliceA != nil {
+					result.A = sliceA[i : i+end : i+end]
+				}
+				if sliceB != nil {
+					result.B = sliceB[i : i+end : i+end]
+				}
+				if sliceC != nil {
+					result.C = sliceC[i : i+end : i+end]
+				}
+
+				channel <- func(yield func(QueryResult3[A, B, C]) bool) {
+					query.World.beginIteration(query.componentsIds, archetypesIds, false)
+					defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+					queryResult := shared
+					if tableOnly {
+						for k, entityId := range result.EntityId {
+							queryResult.EntityId = entityId
+							if result.A != nil {
+								queryResult.A = &result.A[k]
+							}
+							if result.B != nil {
+								queryResult.B = &result.B[k]
+							}
+							if result.C != nil {
+								queryResult.C = &result.C[k]
+							}
+
+							if filterFn != nil && !filterFn(queryResult) {
+								continue
+							}
+
+							if !yield(queryResult) {
+								return
+							}
+						}
+						return
+					}
+
+					for k, entityId := range result.EntityId {
+						if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+							continue
+						}
+
+						queryResult.EntityId = entityId
+
+						if result.A != nil {
+							queryResult.A = &result.A[k]
+						} else if sparseA != nil {
+							queryResult.A = sparseA.get(entityId)
+						}
+						if result.B != nil {
+							queryResult.B = &result.B[k]
+						} else if sparseB != nil {
+							queryResult.B = sparseB.get(entityId)
+						}
+						if result.C != nil {
+							queryResult.C = &result.C[k]
+						} else if sparseC != nil {
+							queryResult.C = sparseC.get(entityId)
+						}
+
+						if filterFn != nil && !filterFn(queryResult) {
+							continue
+						}
+
+						if !yield(queryResult) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	return channel
+}
+
+// Query for 4 components type.
+type Query4[A, B, C, D ComponentInterface] struct {
+	World              *World
+	componentsIds      []ComponentId
+	queryConfiguration QueryConfiguration
+
+	cache filterCache
+	// sorted is the buffer of the results of ForeachSorted, reused from one call to the next.
+	sorted []QueryResult4[A, B, C, D]
+}
+
+// Result returned for Query4.
+type QueryResult4[A, B, C, D ComponentInterface] struct {
+	EntityId EntityId
+	A        *A
+	B        *B
+	C        *C
+	D        *D
+}
+
+type queryResultChunk4[A, B, C, D ComponentInterface] struct {
+	EntityId []EntityId
+	A        []A
+	B        []B
+	C        []C
+	D        []D
+}
+
+// CreateQuery4 returns a new Query4, with components A, B, C, D.
+func CreateQuery4[A, B, C, D ComponentInterface](world *World, queryConfiguration QueryConfiguration) Query4[A, B, C, D] {
+	componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world), ComponentIdOf[D](world)}
+	return Query4[A, B, C, D]{
+		World:              world,
+		componentsIds:      componentsIds,
+		queryConfiguration: queryConfiguration,
+		cache:              newFilterCache(world, componentsIds, queryConfiguration),
+	}
+}
+
+func (query *Query4[A, B, C, D]) GetComponentsIds() []ComponentId {
+	return query.componentsIds
+}
+
+func (query *Query4[A, B, C, D]) filter() []archetypeId {
+	return query.cache.resolve(query.World)
+}
+
+// Count returns the total of entities fetched for Query4.
+func (query *Query4[A, B, C, D]) Count() int {
+	return query.cache.count(query.World)
+}
+
+// GetEntitiesIds returns a slice of all the EntityId fetched for Query4.
+func (query *Query4[A, B, C, D]) GetEntitiesIds() []EntityId {
+	return query.cache.entities(query.World)
+}
+
+// Matches reports whether the entity is fetched by Query4, e.g. to filter the entities found by another mean.
+func (query *Query4[A, B, C, D]) Matches(entityId EntityId) bool {
+	return query.cache.matches(query.World, entityId)
+}
+
+// Foreach returns an iterator of QueryResult4 for all the entities with components A, B, C, D
+// to which filterFn function returns true.
+func (query *Query4[A, B, C, D]) Foreach(filterFn func(QueryResult4[A, B, C, D]) bool) iter.Seq[QueryResult4[A, B, C, D]] {
+	return func(yield func(QueryResult4[A, B, C, D]) bool) {
+		storageA := getStorage[A](query.World)
+		storageB := getStorage[B](query.World)
+		storageC := getStorage[C](query.World)
+		storageD := getStorage[D](query.World)
+		archetypesIds := query.filter()
+
+		// endIteration is not deferred, and the sparse components are iterated apart,
+		// so that the loop over the columns can be inlined in the range over Foreach.
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		yield = guardYield(query.World, query.componentsIds, archetypesIds, yield)
+		if query.cache.sparseIds != nil || storageA.getSparse() != nil || storageB.getSparse() != nil || storageC.getSparse() != nil || storageD.getSparse() != nil {
+			query.foreachSparse(archetypesIds, filterFn, yield)
+			query.World.endIteration(query.componentsIds, archetypesIds, false)
+			return
+		}
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+			sliceD := storageD.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			result := query.sharedResult(archetype.Id)
+			for i, entityId := range archetype.entities {
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				if sliceD != nil {
+					result.D = &sliceD[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					continue
+				}
+
+				if !yield(result) {
+					query.World.endIteration(query.componentsIds, archetypesIds, false)
+					return
+				}
+			}
+		}
+		query.World.endIteration(query.componentsIds, archetypesIds, false)
+	}
+}
+
+// foreachSparse yields the results of Foreach for a query over sparse components, looked up for each entity.
+func (query *Query4[A, B, C, D]) foreachSparse(archetypesIds []archetypeId, filterFn func(QueryResult4[A, B, C, D]) bool, yield func(QueryResult4[A, B, C, D]) bool) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	storageD := getStorage[D](query.World)
+	sparseD := storageD.getSparse()
+	sparseIds := query.cache.sparseIds
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+		sliceD := storageD.getColumn(archetype.Id)
+
+		result := query.sharedResult(archetype.Id)
+		for i, entityId := range archetype.entities {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				continue
+			}
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			if sliceD != nil {
+				result.D = &sliceD[i]
+			} else if sparseD != nil {
+				result.D = sparseD.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				continue
+			}
+
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// sharedResult returns a QueryResult4 holding the values of the shared components of the archetype,
+// the same for all its entities.
+func (query *Query4[A, B, C, D]) sharedResult(archetypeId archetypeId) QueryResult4[A, B, C, D] {
+	var result QueryResult4[A, B, C, D]
+	result.A = getStorage[A](query.World).getShared().get(archetypeId)
+	result.B = getStorage[B](query.World).getShared().get(archetypeId)
+	result.C = getStorage[C](query.World).getShared().get(archetypeId)
+	result.D = getStorage[D](query.World).getShared().get(archetypeId)
+
+	return result
+}
+
+// ForeachSorted returns an iterator of QueryResult4 for all the entities with components A, B, C, D,
+// in the order defined by less, e.g. back to front for the rendering of transparent sprites.
+// The entities with an equal order keep the order of Foreach.
+//
+// The results are sorted in a buffer of the query reused from one call to the next: the components are not copied.
+func (query *Query4[A, B, C, D]) ForeachSorted(less func(a, b QueryResult4[A, B, C, D]) bool) iter.Seq[QueryResult4[A, B, C, D]] {
+	return func(yield func(QueryResult4[A, B, C, D]) bool) {
+		query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+		defer clear(query.sorted)
+
+		archetypesIds := query.filter()
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+		for _, result := range query.sorted {
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// SortArchetypes moves the rows of the archetypes fetched by Query4, so that Foreach iterates
+// the entities of each archetype in the order defined by less, without the cost of ForeachSorted.
+// The archetypes are still iterated in their order of creation.
+//
+// The order holds until the next structural change: adding or removing an entity or a component moves rows.
+// SortArchetypes must not be called while a query is iterated, and has no effect on a deterministic World, see SetDeterministic.
+func (query *Query4[A, B, C, D]) SortArchetypes(less func(a, b QueryResult4[A, B, C, D]) bool) {
+	query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+	defer clear(query.sorted)
+
+	sortRows(query.World, query.sorted, func(result QueryResult4[A, B, C, D]) EntityId {
+		return result.EntityId
+	})
+}
+
+// Task executes fn in parallel across workersCount goroutines for all entities matching the query.
+// Each entity's components are passed to fn through QueryResult4.
+// If filterFn is provided and returns false for an entity, that entity is skipped.
+//
+// The workersCount parameter determines the number of parallel workers.
+// Data is automatically partitioned across workers for optimal performance.
+func (query *Query4[A, B, C, D]) Task(workersCount int, filterFn func(QueryResult4[A, B, C, D]) bool, fn func(result QueryResult4[A, B, C, D])) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	sharedA := storageA.getShared()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	sharedB := storageB.getShared()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	sharedC := storageC.getShared()
+	storageD := getStorage[D](query.World)
+	sparseD := storageD.getSparse()
+	sharedD := storageD.getShared()
+	sparseIds := query.cache.sparseIds
+	// Without sparse component, the components are read from the columns only, as in a tight loop.
+	tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil && sparseD == nil
+	archetypesIds := query.filter()
+
+	query.World.beginIteration(query.componentsIds, archetypesIds, true)
+	defer query.World.endIteration(query.componentsIds, archetypesIds, true)
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+		sliceD := storageD.getColumn(archetype.Id)
+
+		// The shared components are the same for all the entities of the archetype.
+		var shared QueryResult4[A, B, C, D]
+		shared.A = sharedA.get(archetype.Id)
+		shared.B = sharedB.get(archetype.Id)
+		shared.C = sharedC.get(archetype.Id)
+		shared.D = sharedD.get(archetype.Id)
+
+		if tableOnly {
+			task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+				result := shared
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				if sliceD != nil {
+					result.D = &sliceD[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					return
+				}
+
+				fn(result)
+			})
+			continue
+		}
+
+		task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				return
+			}
+
+			result := shared
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			if sliceD != nil {
+				result.D = &sliceD[i]
+			} else if sparseD != nil {
+				result.D = sparseD.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				return
+			}
+
+			fn(result)
+		})
+	}
+}
+
+// ForeachChannel returns a channel of iterators of QueryResult4 for all the entities with components A, B, C, D
+// to which filterFn function returns true.
+// The parameter chunkSize defines the size of each iterators.
+//
+// Deprecated: ForeachChannel is deprecated and will be removed in a future version.
+// Use Task(workersCount, filterFn, fn) instead, which offers better performance
+// and a simpler API for parallel iteration.
+func (query *Query4[A, B, C, D]) ForeachChannel(chunkSize int, filterFn func(QueryResult4[A, B, C, D]) bool) <-chan iter.Seq[QueryResult4[A, B, C, D]] {
+	if chunkSize == 0 {
+		panic("chunk size must be greater than zero")
+	}
+
+	channelsCount := math.Ceil(float64(query.Count()) / float64(chunkSize))
+	channel := make(chan iter.Seq[QueryResult4[A, B, C, D]], int(channelsCount))
+
+	go func() {
+		defer close(channel)
+
+		storageA := getStorage[A](query.World)
+		sparseA := storageA.getSparse()
+		sharedA := storageA.getShared()
+		storageB := getStorage[B](query.World)
+		sparseB := storageB.getSparse()
+		sharedB := storageB.getShared()
+		storageC := getStorage[C](query.World)
+		sparseC := storageC.getSparse()
+		sharedC := storageC.getShared()
+		storageD := getStorage[D](query.World)
+		sparseD := storageD.getSparse()
+		sharedD := storageD.getShared()
+		sparseIds := query.cache.sparseIds
+		// Without sparse component, the components are read from the columns only, as in a tight loop.
+		tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil && sparseD == nil
+		archetypesIds := query.filter()
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+			sliceD := storageD.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			var shared QueryResult4[A, B, C, D]
+			shared.A = sharedA.get(archetype.Id)
+			shared.B = sharedB.get(archetype.Id)
+			shared.C = sharedC.get(archetype.Id)
+			shared.D = sharedD.get(archetype.Id)
+
+			for i := 0; i < len(archetype.entities); i += chunkSize {
+				result := queryResultChunk4[A, B, C, D]{}
+				end := min(chunkSize, len(archetype.entities[i:]))
+
+				// Set the capacity of each chunk so that appending to a chunk does
+				// not modify the original slice.
+				result.EntityId = archetype.entities[i : i+end : i+end]
+				if sliceA != nil {
+					result.A = sliceA[i : i+end : i+end]
+				}
+				if sliceB != nil {
+					result.B = sliceB[i : i+end : i+end]
+				}
+				if sliceC != nil {
+					result.C = sliceC[i : i+end : i+end]
+				}
+				if sliceD != nil {
+					result.D = sliceD[i : i+end : i+end]
+				}
+
+				channel <- func(yield func(QueryResult4[A, B, C, D]) bool) {
+					query.World.beginIteration(query.componentsIds, archetypesIds, false)
+					defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+					queryResult := shared
+					if tableOnly {
+						for k, entityId := range result.EntityId {
+							queryResult.EntityId = entityId
+							if result.A != nil {
+								queryResult.A = &result.A[k]
+							}
+							if result.B != nil {
+								queryResult.B = &result.B[k]
+							}
+							if result.C != nil {
+								queryResult.C = &result.C[k]
+							}
+							if result.D != nil {
+								queryResult.D = &result.D[k]
+							}
+
+							if filterFn != nil && !filterFn(queryResult) {
+								continue
+							}
+
+							if !yield(queryResult) {
+								return
+							}
+						}
+						return
+					}
+
+					for k, entityId := range result.EntityId {
+						if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+							continue
+						}
+
+						queryResult.EntityId = entityId
+
+						if result.A != nil {
+							queryResult.A = &result.A[k]
+						} else if sparseA != nil {
+							queryResult.A = sparseA.get(entityId)
+						}
+						if result.B != nil {
+							queryResult.B = &result.B[k]
+						} else if sparseB != nil {
+							queryResult.B = sparseB.get(entityId)
+						}
+						if result.C != nil {
+							queryResult.C = &result.C[k]
+						} else if sparseC != nil {
+							queryResult.C = sparseC.get(entityId)
+						}
+						if result.D != nil {
+							queryResult.D = &result.D[k]
+						} else if sparseD != nil {
+							queryResult.D = sparseD.get(entityId)
+						}
+
+						if filterFn != nil && !filterFn(queryResult) {
+							continue
+						}
+
+						if !yield(queryResult) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	return channel
+}
+
+// Query for 5 components type.
+type Query5[A, B, C, D, E ComponentInterface] struct {
+	World              *World
+	componentsIds      []ComponentId
+	queryConfiguration QueryConfiguration
+
+	cache filterCache
+	// sorted is the buffer of the results of ForeachSorted, reused from one call to the next.
+	sorted []QueryResult5[A, B, C, D, E]
+}
+
+// Result returned for Query5.
+type QueryResult5[A, B, C, D, E ComponentInterface] struct {
+	EntityId EntityId
+	A        *A
+	B        *B
+	C        *C
+	D        *D
+	E        *E
+}
+
+type queryResultChunk5[A, B, C, D, E ComponentInterface] struct {
+	EntityId []EntityId
+	A        []A
+	B        []B
+	C        []C
+	D        []D
+	E        []E
+}
+
+// CreateQuery5 returns a new Query5, with components A, B, C, D, E.
+func CreateQuery5[A, B, C, D, E ComponentInterface](world *World, queryConfiguration QueryConfiguration) Query5[A, B, C, D, E] {
+	componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world), ComponentIdOf[D](world), ComponentIdOf[E](world)}
+	return Query5[A, B, C, D, E]{
+		World:              world,
+		componentsIds:      componentsIds,
+		queryConfiguration: queryConfiguration,
+		cache:              newFilterCache(world, componentsIds, queryConfiguration),
+	}
+}
+
+func (query *Query5[A, B, C, D, E]) GetComponentsIds() []ComponentId {
+	return query.componentsIds
+}
+
+func (query *Query5[A, B, C, D, E]) filter() []archetypeId {
+	return query.cache.resolve(query.World)
+}
+
+// Count returns the total of entities fetched for Query5.
+func (query *Query5[A, B, C, D, E]) Count() int {
+	return query.cache.count(query.World)
+}
+
+// GetEntitiesIds returns a slice of all the EntityId fetched for Query5.
+func (query *Query5[A, B, C, D, E]) GetEntitiesIds() []EntityId {
+	return query.cache.entities(query.World)
+}
+
+// Matches reports whether the entity is fetched by Query5, e.g. to filter the entities found by another mean.
+func (query *Query5[A, B, C, D, E]) Matches(entityId EntityId) bool {
+	return query.cache.matches(query.World, entityId)
+}
+
+// Foreach returns an iterator of QueryResult5 for all the entities with components A, B, C, D, E
+// to which filterFn function returns true.
+func (query *Query5[A, B, C, D, E]) Foreach(filterFn func(QueryResult5[A, B, C, D, E]) bool) iter.Seq[QueryResult5[A, B, C, D, E]] {
+	return func(yield func(QueryResult5[A, B, C, D, E]) bool) {
+		storageA := getStorage[A](query.World)
+		storageB := getStorage[B](query.World)
+		storageC := getStorage[C](query.World)
+		storageD := getStorage[D](query.World)
+		storageE := getStorage[E](query.World)
+		archetypesIds := query.filter()
+
+		// endIteration is not deferred, and the sparse components are iterated apart,
+		// so that the loop over the columns can be inlined in the range over Foreach.
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		yield = guardYield(query.World, query.componentsIds, archetypesIds, yield)
+		if query.cache.sparseIds != nil || storageA.getSparse() != nil || storageB.getSparse() != nil || storageC.getSparse() != nil || storageD.getSparse() != nil || storageE.getSparse() != nil {
+			query.foreachSparse(archetypesIds, filterFn, yield)
+			query.World.endIteration(query.componentsIds, archetypesIds, false)
+			return
+		}
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+			sliceD := storageD.getColumn(archetype.Id)
+			sliceE := storageE.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			result := query.sharedResult(archetype.Id)
+			for i, entityId := range archetype.entities {
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				if sliceD != nil {
+					result.D = &sliceD[i]
+				}
+				if sliceE != nil {
+					result.E = &sliceE[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					continue
+				}
+
+				if !yield(result) {
+					query.World.endIteration(query.componentsIds, archetypesIds, false)
+					return
+				}
+			}
+		}
+		query.World.endIteration(query.componentsIds, archetypesIds, false)
+	}
+}
+
+// foreachSparse yields the results of Foreach for a query over sparse components, looked up for each entity.
+func (query *Query5[A, B, C, D, E]) foreachSparse(archetypesIds []archetypeId, filterFn func(QueryResult5[A, B, C, D, E]) bool, yield func(QueryResult5[A, B, C, D, E]) bool) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	storageD := getStorage[D](query.World)
+	sparseD := storageD.getSparse()
+	storageE := getStorage[E](query.World)
+	sparseE := storageE.getSparse()
+	sparseIds := query.cache.sparseIds
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+		sliceD := storageD.getColumn(archetype.Id)
+		sliceE := storageE.getColumn(archetype.Id)
+
+		result := query.sharedResult(archetype.Id)
+		for i, entityId := range archetype.entities {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				continue
+			}
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			if sliceD != nil {
+				result.D = &sliceD[i]
+			} else if sparseD != nil {
+				result.D = sparseD.get(entityId)
+			}
+			if sliceE != nil {
+				result.E = &sliceE[i]
+			} else if sparseE != nil {
+				result.E = sparseE.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				continue
+			}
+
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// sharedResult returns a QueryResult5 holding the values of the shared components of the archetype,
+// the same for all its entities.
+func (query *Query5[A, B, C, D, E]) sharedResult(archetypeId archetypeId) QueryResult5[A, B, C, D, E] {
+	var result QueryResult5[A, B, C, D, E]
+	result.A = getStorage[A](query.World).getShared().get(archetypeId)
+	result.B = getStorage[B](query.World).getShared().get(archetypeId)
+	result.C = getStorage[C](query.World).getShared().get(archetypeId)
+	result.D = getStorage[D](query.World).getShared().get(archetypeId)
+	result.E = getStorage[E](query.World).getShared().get(archetypeId)
+
+	return result
+}
+
+// ForeachSorted returns an iterator of QueryResult5 for all the entities with components A, B, C, D, E,
+// in the order defined by less, e.g. back to front for the rendering of transparent sprites.
+// The entities with an equal order keep the order of Foreach.
+//
+// The results are sorted in a buffer of the query reused from one call to the next: the components are not copied.
+func (query *Query5[A, B, C, D, E]) ForeachSorted(less func(a, b QueryResult5[A, B, C, D, E]) bool) iter.Seq[QueryResult5[A, B, C, D, E]] {
+	return func(yield func(QueryResult5[A, B, C, D, E]) bool) {
+		query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+		defer clear(query.sorted)
+
+		archetypesIds := query.filter()
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+		for _, result := range query.sorted {
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// SortArchetypes moves the rows of the archetypes fetched by Query5, so that Foreach iterates
+// the entities of each archetype in the order defined by less, without the cost of ForeachSorted.
+// The archetypes are still iterated in their order of creation.
+//
+// The order holds until the next structural change: adding or removing an entity or a component moves rows.
+// SortArchetypes must not be called while a query is iterated, and has no effect on a deterministic World, see SetDeterministic.
+func (query *Query5[A, B, C, D, E]) SortArchetypes(less func(a, b QueryResult5[A, B, C, D, E]) bool) {
+	query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+	defer clear(query.sorted)
+
+	sortRows(query.World, query.sorted, func(result QueryResult5[A, B, C, D, E]) EntityId {
+		return result.EntityId
+	})
+}
+
+// Task executes fn in parallel across workersCount goroutines for all entities matching the query.
+// Each entity's components are passed to fn through QueryResult5.
+// If filterFn is provided and returns false for an entity, that entity is skipped.
+//
+// The workersCount parameter determines the number of parallel workers.
+// Data is automatically partitioned across workers for optimal performance.
+func (query *Query5[A, B, C, D, E]) Task(workersCount int, filterFn func(QueryResult5[A, B, C, D, E]) bool, fn func(result QueryResult5[A, B, C, D, E])) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	sharedA := storageA.getShared()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	sharedB := storageB.getShared()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	sharedC := storageC.getShared()
+	storageD := getStorage[D](query.World)
+	sparseD := storageD.getSparse()
+	sharedD := storageD.getShared()
+	storageE := getStorage[E](query.World)
+	sparseE := storageE.getSparse()
+	sharedE := storageE.getShared()
+	sparseIds := query.cache.sparseIds
+	// Without sparse component, the components are read from the columns only, as in a tight loop.
+	tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil && sparseD == nil && sparseE == nil
+	archetypesIds := query.filter()
+
+	query.World.beginIteration(query.componentsIds, archetypesIds, true)
+	defer query.World.endIteration(query.componentsIds, archetypesIds, true)
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+		sliceD := storageD.getColumn(archetype.Id)
+		sliceE := storageE.getColumn(archetype.Id)
+
+		// The shared components are the same for all the entities of the archetype.
+		var shared QueryResult5[A, B, C, D, E]
+		shared.A = sharedA.get(archetype.Id)
+		shared.B = sharedB.get(archetype.Id)
+		shared.C = sharedC.get(archetype.Id)
+		shared.D = sharedD.get(archetype.Id)
+		shared.E = sharedE.get(archetype.Id)
+
+		if tableOnly {
+			task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+				result := shared
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				if sliceD != nil {
+					result.D = &sliceD[i]
+				}
+				if sliceE != nil {
+					result.E = &sliceE[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					return
+				}
+
+				fn(result)
+			})
+			continue
+		}
+
+		task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				return
+			}
+
+			result := shared
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			if sliceD != nil {
+				result.D = &sliceD[i]
+			} else if sparseD != nil {
+				result.D = sparseD.get(entityId)
+			}
+			if sliceE != nil {
+				result.E = &sliceE[i]
+			} else if sparseE != nil {
+				result.E = sparseE.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				return
+			}
+
+			fn(result)
+		})
+	}
+}
+
+// ForeachChannel returns a channel of iterators of QueryResult5 for all the entities with components A, B, C, D, E
+// to which filterFn function returns true.
+// The parameter chunkSize defines the size of each iterators.
+//
+// Deprecated: ForeachChannel is deprecated and will be removed in a future version.
+// Use Task(workersCount, filterFn, fn) instead, which offers better performance
+// and a simpler API for parallel iteration.
+func (query *Query5[A, B, C, D, E]) ForeachChannel(chunkSize int, filterFn func(QueryResult5[A, B, C, D, E]) bool) <-chan iter.Seq[QueryResult5[A, B, C, D, E]] {
+	if chunkSize == 0 {
+		panic("chunk size must be greater than zero")
+	}
+
+	channelsCount := math.Ceil(float64(query.Count()) / float64(chunkSize))
+	channel := make(chan iter.Seq[QueryResult5[A, B, C, D, E]], int(channelsCount))
+
+	go func() {
+		defer close(channel)
+
+		storageA := getStorage[A](query.World)
+		sparseA := storageA.getSparse()
+		sharedA := storageA.getShared()
+		storageB := getStorage[B](query.World)
+		sparseB := storageB.getSparse()
+		sharedB := storageB.getShared()
+		storageC := getStorage[C](query.World)
+		sparseC := storageC.getSparse()
+		sharedC := storageC.getShared()
+		storageD := getStorage[D](query.World)
+		sparseD := storageD.getSparse()
+		sharedD := storageD.getShared()
+		storageE := getStorage[E](query.World)
+		sparseE := storageE.getSparse()
+		sharedE := storageE.getShared()
+		sparseIds := query.cache.sparseIds
+		// Without sparse component, the components are read from the columns only, as in a tight loop.
+		tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil && sparseD == nil && sparseE == nil
+		archetypesIds := query.filter()
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+			sliceD := storageD.getColumn(archetype.Id)
+			sliceE := storageE.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			var shared QueryResult5[A, B, C, D, E]
+			shared.A = sharedA.get(archetype.Id)
+			shared.B = sharedB.get(archetype.Id)
+			shared.C = sharedC.get(archetype.Id)
+			shared.D = sharedD.get(archetype.Id)
+			shared.E = sharedE.get(archetype.Id)
+
+			for i := 0; i < len(archetype.entities); i += chunkSize {
+				result := queryResultChunk5[A, B, C, D, E]{}
+				end := min(chunkSize, len(archetype.entities[i:]))
+
+				// Set the capacity of each chunk so that appending to a chunk does
+				// not modify the original slice.
+				result.EntityId = archetype.entities[i : i+end : i+end]
+				if sliceA != nil {
+					result.A = sliceA[i : i+end : i+end]
+				}
+				if sliceB != nil {
+					result.B = sliceB[i : i+end : i+end]
+				}
+				if sliceC != nil {
+					result.C = sliceC[i : i+end : i+end]
+				}
+				if sliceD != nil {
+					result.D = sliceD[i : i+end : i+end]
+				}
+				if sliceE != nil {
+					result.E = sliceE[i : i+end : i+end]
+				}
+
+				channel <- func(yield func(QueryResult5[A, B, C, D, E]) bool) {
+					query.World.beginIteration(query.componentsIds, archetypesIds, false)
+					defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+					queryResult := shared
+					if tableOnly {
+						for k, entityId := range result.EntityId {
+							queryResult.EntityId = entityId
+							if result.A != nil {
+								queryResult.A = &result.A[k]
+							}
+							if result.B != nil {
+								queryResult.B = &result.B[k]
+							}
+							if result.C != nil {
+								queryResult.C = &result.C[k]
+							}
+							if result.D != nil {
+								queryResult.D = &result.D[k]
+							}
+							if result.E != nil {
+								queryResult.E = &result.E[k]
+							}
+
+							if filterFn != nil && !filterFn(queryResult) {
+								continue
+							}
+
+							if !yield(queryResult) {
+								return
+							}
+						}
+						return
+					}
+
+					for k, entityId := range result.EntityId {
+						if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+							continue
+						}
+
+						queryResult.EntityId = entityId
+
+						if result.A != nil {
+							queryResult.A = &result.A[k]
+						} else if sparseA != nil {
+							queryResult.A = sparseA.get(entityId)
+						}
+						if result.B != nil {
+							queryResult.B = &result.B[k]
+						} else if sparseB != nil {
+							queryResult.B = sparseB.get(entityId)
+						}
+						if result.C != nil {
+							queryResult.C = &result.C[k]
+						} else if sparseC != nil {
+							queryResult.C = sparseC.get(entityId)
+						}
+						if result.D != nil {
+							queryResult.D = &result.D[k]
+						} else if sparseD != nil {
+							queryResult.D = sparseD.get(entityId)
+						}
+						if result.E != nil {
+							queryResult.E = &result.E[k]
+						} else if sparseE != nil {
+							queryResult.E = sparseE.get(entityId)
+						}
+
+						if filterFn != nil && !filterFn(queryResult) {
+							continue
+						}
+
+						if !yield(queryResult) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	return channel
+}
+
+// Query for 6 components type.
+type Query6[A, B, C, D, E, F ComponentInterface] struct {
+	World              *World
+	componentsIds      []ComponentId
+	queryConfiguration QueryConfiguration
+
+	cache filterCache
+	// sorted is the buffer of the results of ForeachSorted, reused from one call to the next.
+	sorted []QueryResult6[A, B, C, D, E, F]
+}
+
+// Result returned for Query6.
+type QueryResult6[A, B, C, D, E, F ComponentInterface] struct {
+	EntityId EntityId
+	A        *A
+	B        *B
+	C        *C
+	D        *D
+	E        *E
+	F        *F
+}
+
+type queryResultChunk6[A, B, C, D, E, F ComponentInterface] struct {
+	EntityId []EntityId
+	A        []A
+	B        []B
+	C        []C
+	D        []D
+	E        []E
+	F        []F
+}
+
+// CreateQuery6 returns a new Query6, with components A, B, C, D, E, F.
+func CreateQuery6[A, B, C, D, E, F ComponentInterface](world *World, queryConfiguration QueryConfiguration) Query6[A, B, C, D, E, F] {
+	componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world), ComponentIdOf[D](world), ComponentIdOf[E](world), ComponentIdOf[F](world)}
+	return Query6[A, B, C, D, E, F]{
+		World:              world,
+		componentsIds:      componentsIds,
+		queryConfiguration: queryConfiguration,
+		cache:              newFilterCache(world, componentsIds, queryConfiguration),
+	}
+}
+
+func (query *Query6[A, B, C, D, E, F]) GetComponentsIds() []ComponentId {
+	return query.componentsIds
+}
+
+func (query *Query6[A, B, C, D, E, F]) filter() []archetypeId {
+	return query.cache.resolve(query.World)
+}
+
+// Count returns the total of entities fetched for Query6.
+func (query *Query6[A, B, C, D, E, F]) Count() int {
+	return query.cache.count(query.World)
+}
+
+// GetEntitiesIds returns a slice of all the EntityId fetched for Query6.
+func (query *Query6[A, B, C, D, E, F]) GetEntitiesIds() []EntityId {
+	return query.cache.entities(query.World)
+}
+
+// Matches reports whether the entity is fetched by Query6, e.g. to filter the entities found by another mean.
+func (query *Query6[A, B, C, D, E, F]) Matches(entityId EntityId) bool {
+	return query.cache.matches(query.World, entityId)
+}
+
+// Foreach returns an iterator of QueryResult6 for all the entities with components A, B, C, D, E, F
+// to which filterFn function returns true.
+func (query *Query6[A, B, C, D, E, F]) Foreach(filterFn func(QueryResult6[A, B, C, D, E, F]) bool) iter.Seq[QueryResult6[A, B, C, D, E, F]] {
+	return func(yield func(QueryResult6[A, B, C, D, E, F]) bool) {
+		storageA := getStorage[A](query.World)
+		storageB := getStorage[B](query.World)
+		storageC := getStorage[C](query.World)
+		storageD := getStorage[D](query.World)
+		storageE := getStorage[E](query.World)
+		storageF := getStorage[F](query.World)
+		archetypesIds := query.filter()
+
+		// endIteration is not deferred, and the sparse components are iterated apart,
+		// so that the loop over the columns can be inlined in the range over Foreach.
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		yield = guardYield(query.World, query.componentsIds, archetypesIds, yield)
+		if query.cache.sparseIds != nil || storageA.getSparse() != nil || storageB.getSparse() != nil || storageC.getSparse() != nil || storageD.getSparse() != nil || storageE.getSparse() != nil || storageF.getSparse() != nil {
+			query.foreachSparse(archetypesIds, filterFn, yield)
+			query.World.endIteration(query.componentsIds, archetypesIds, false)
+			return
+		}
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+			sliceD := storageD.getColumn(archetype.Id)
+			sliceE := storageE.getColumn(archetype.Id)
+			sliceF := storageF.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			result := query.sharedResult(archetype.Id)
+			for i, entityId := range archetype.entities {
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				if sliceD != nil {
+					result.D = &sliceD[i]
+				}
+				if sliceE != nil {
+					result.E = &sliceE[i]
+				}
+				if sliceF != nil {
+					result.F = &sliceF[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					continue
+				}
+
+				if !yield(result) {
+					query.World.endIteration(query.componentsIds, archetypesIds, false)
+					return
+				}
+			}
+		}
+		query.World.endIteration(query.componentsIds, archetypesIds, false)
+	}
+}
+
+// foreachSparse yields the results of Foreach for a query over sparse components, looked up for each entity.
+func (query *Query6[A, B, C, D, E, F]) foreachSparse(archetypesIds []archetypeId, filterFn func(QueryResult6[A, B, C, D, E, F]) bool, yield func(QueryResult6[A, B, C, D, E, F]) bool) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	storageD := getStorage[D](query.World)
+	sparseD := storageD.getSparse()
+	storageE := getStorage[E](query.World)
+	sparseE := storageE.getSparse()
+	storageF := getStorage[F](query.World)
+	sparseF := storageF.getSparse()
+	sparseIds := query.cache.sparseIds
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+		sliceD := storageD.getColumn(archetype.Id)
+		sliceE := storageE.getColumn(archetype.Id)
+		sliceF := storageF.getColumn(archetype.Id)
+
+		result := query.sharedResult(archetype.Id)
+		for i, entityId := range archetype.entities {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				continue
+			}
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			if sliceD != nil {
+				result.D = &sliceD[i]
+			} else if sparseD != nil {
+				result.D = sparseD.get(entityId)
+			}
+			if sliceE != nil {
+				result.E = &sliceE[i]
+			} else if sparseE != nil {
+				result.E = sparseE.get(entityId)
+			}
+			if sliceF != nil {
+				result.F = &sliceF[i]
+			} else if sparseF != nil {
+				result.F = sparseF.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				continue
+			}
+
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// sharedResult returns a QueryResult6 holding the values of the shared components of the archetype,
+// the same for all its entities.
+func (query *Query6[A, B, C, D, E, F]) sharedResult(archetypeId archetypeId) QueryResult6[A, B, C, D, E, F] {
+	var result QueryResult6[A, B, C, D, E, F]
+	result.A = getStorage[A](query.World).getShared().get(archetypeId)
+	result.B = getStorage[B](query.World).getShared().get(archetypeId)
+	result.C = getStorage[C](query.World).getShared().get(archetypeId)
+	result.D = getStorage[D](query.World).getShared().get(archetypeId)
+	result.E = getStorage[E](query.World).getShared().get(archetypeId)
+	result.F = getStorage[F](query.World).getShared().get(archetypeId)
+
+	return result
+}
+
+// ForeachSorted returns an iterator of QueryResult6 for all the entities with components A, B, C, D, E, F,
+// in the order defined by less, e.g. back to front for the rendering of transparent sprites.
+// The entities with an equal order keep the order of Foreach.
+//
+// The results are sorted in a buffer of the query reused from one call to the next: the components are not copied.
+func (query *Query6[A, B, C, D, E, F]) ForeachSorted(less func(a, b QueryResult6[A, B, C, D, E, F]) bool) iter.Seq[QueryResult6[A, B, C, D, E, F]] {
+	return func(yield func(QueryResult6[A, B, C, D, E, F]) bool) {
+		query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+		defer clear(query.sorted)
+
+		archetypesIds := query.filter()
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+		for _, result := range query.sorted {
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// SortArchetypes moves the rows of the archetypes fetched by Query6, so that Foreach iterates
+// the entities of each archetype in the order defined by less, without the cost of ForeachSorted.
+// The archetypes are still iterated in their order of creation.
+//
+// The order holds until the next structural change: adding or removing an entity or a component moves rows.
+// SortArchetypes must not be called while a query is iterated, and has no effect on a deterministic World, see SetDeterministic.
+func (query *Query6[A, B, C, D, E, F]) SortArchetypes(less func(a, b QueryResult6[A, B, C, D, E, F]) bool) {
+	query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+	defer clear(query.sorted)
+
+	sortRows(query.World, query.sorted, func(result QueryResult6[A, B, C, D, E, F]) EntityId {
+		return result.EntityId
+	})
+}
+
+// Task executes fn in parallel across workersCount goroutines for all entities matching the query.
+// Each entity's components are passed to fn through QueryResult6.
+// If filterFn is provided and returns false for an entity, that entity is skipped.
+//
+// The workersCount parameter determines the number of parallel workers.
+// Data is automatically partitioned across workers for optimal performance.
+func (query *Query6[A, B, C, D, E, F]) Task(workersCount int, filterFn func(QueryResult6[A, B, C, D, E, F]) bool, fn func(result QueryResult6[A, B, C, D, E, F])) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	sharedA := storageA.getShared()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	sharedB := storageB.getShared()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	sharedC := storageC.getShared()
+	storageD := getStorage[D](query.World)
+	sparseD := storageD.getSparse()
+	sharedD := storageD.getShared()
+	storageE := getStorage[E](query.World)
+	sparseE := storageE.getSparse()
+	sharedE := storageE.getShared()
+	storageF := getStorage[F](query.World)
+	sparseF := storageF.getSparse()
+	sharedF := storageF.getShared()
+	sparseIds := query.cache.sparseIds
+	// Without sparse component, the components are read from the columns only, as in a tight loop.
+	tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil && sparseD == nil && sparseE == nil && sparseF == nil
+	archetypesIds := query.filter()
+
+	query.World.beginIteration(query.componentsIds, archetypesIds, true)
+	defer query.World.endIteration(query.componentsIds, archetypesIds, true)
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+		sliceD := storageD.getColumn(archetype.Id)
+		sliceE := storageE.getColumn(archetype.Id)
+		sliceF := storageF.getColumn(archetype.Id)
+
+		// The shared components are the same for all the entities of the archetype.
+		var shared QueryResult6[A, B, C, D, E, F]
+		shared.A = sharedA.get(archetype.Id)
+		shared.B = sharedB.get(archetype.Id)
+		shared.C = sharedC.get(archetype.Id)
+		shared.D = sharedD.get(archetype.Id)
+		shared.E = sharedE.get(archetype.Id)
+		shared.F = sharedF.get(archetype.Id)
+
+		if tableOnly {
+			task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+				result := shared
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				if sliceD != nil {
+					result.D = &sliceD[i]
+				}
+				if sliceE != nil {
+					result.E = &sliceE[i]
+				}
+				if sliceF != nil {
+					result.F = &sliceF[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					return
+				}
+
+				fn(result)
+			})
+			continue
+		}
+
+		task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				return
+			}
+
+			result := shared
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			if sliceD != nil {
+				result.D = &sliceD[i]
+			} else if sparseD != nil {
+				result.D = sparseD.get(entityId)
+			}
+			if sliceE != nil {
+				result.E = &sliceE[i]
+			} else if sparseE != nil {
+				result.E = sparseE.get(entityId)
+			}
+			if sliceF != nil {
+				result.F = &sliceF[i]
+			} else if sparseF != nil {
+				result.F = sparseF.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				return
+			}
+
+			fn(result)
+		})
+	}
+}
+
+// ForeachChannel returns a channel of iterators of QueryResult6 for all the entities with components A, B, C, D, E, F
+// to which filterFn function returns true.
+// The parameter chunkSize defines the size of each iterators.
+//
+// Deprecated: ForeachChannel is deprecated and will be removed in a future version.
+// Use Task(workersCount, filterFn, fn) instead, which offers better performance
+// and a simpler API for parallel iteration.
+func (query *Query6[A, B, C, D, E, F]) ForeachChannel(chunkSize int, filterFn func(QueryResult6[A, B, C, D, E, F]) bool) <-chan iter.Seq[QueryResult6[A, B, C, D, E, F]] {
+	if chunkSize == 0 {
+		panic("chunk size must be greater than zero")
+	}
+
+	channelsCount := math.Ceil(float64(query.Count()) / float64(chunkSize))
+	channel := make(chan iter.Seq[QueryResult6[A, B, C, D, E, F]], int(channelsCount))
+
+	go func() {
+		defer close(channel)
+
+		storageA := getStorage[A](query.World)
+		sparseA := storageA.getSparse()
+		sharedA := storageA.getShared()
+		storageB := getStorage[B](query.World)
+		sparseB := storageB.getSparse()
+		sharedB := storageB.getShared()
+		storageC := getStorage[C](query.World)
+		sparseC := storageC.getSparse()
+		sharedC := storageC.getShared()
+		storageD := getStorage[D](query.World)
+		sparseD := storageD.getSparse()
+		sharedD := storageD.getShared()
+		storageE := getStorage[E](query.World)
+		sparseE := storageE.getSparse()
+		sharedE := storageE.getShared()
+		storageF := getStorage[F](query.World)
+		sparseF := storageF.getSparse()
+		sharedF := storageF.getShared()
+		sparseIds := query.cache.sparseIds
+		// Without sparse component, the components are read from the columns only, as in a tight loop.
+		tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil && sparseD == nil && sparseE == nil && sparseF == nil
+		archetypesIds := query.filter()
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+			sliceD := storageD.getColumn(archetype.Id)
+			sliceE := storageE.getColumn(archetype.Id)
+			sliceF := storageF.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			var shared QueryResult6[A, B, C, D, E, F]
+			shared.A = sharedA.get(archetype.Id)
+			shared.B = sharedB.get(archetype.Id)
+			shared.C = sharedC.get(archetype.Id)
+			shared.D = sharedD.get(archetype.Id)
+			shared.E = sharedE.get(archetype.Id)
+			shared.F = sharedF.get(archetype.Id)
+
+			for i := 0; i < len(archetype.entities); i += chunkSize {
+				result := queryResultChunk6[A, B, C, D, E, F]{}
+				end := min(chunkSize, len(archetype.entities[i:]))
+
+				// Set the capacity of each chunk so that appending to a chunk does
+				// not modify the original slice.
+				result.EntityId = archetype.entities[i : i+end : i+end]
+				if sliceA != nil {
+					result.A = sliceA[i : i+end : i+end]
+				}
+				if sliceB != nil {
+					result.B = sliceB[i : i+end : i+end]
+				}
+				if sliceC != nil {
+					result.C = sliceC[i : i+end : i+end]
+				}
+				if sliceD != nil {
+					result.D = sliceD[i : i+end : i+end]
+				}
+				if sliceE != nil {
+					result.E = sliceE[i : i+end : i+end]
+				}
+				if sliceF != nil {
+					result.F = sliceF[i : i+end : i+end]
+				}
+
+				channel <- func(yield func(QueryResult6[A, B, C, D, E, F]) bool) {
+					query.World.beginIteration(query.componentsIds, archetypesIds, false)
+					defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+					queryResult := shared
+					if tableOnly {
+						for k, entityId := range result.EntityId {
+							queryResult.EntityId = entityId
+							if result.A != nil {
+								queryResult.A = &result.A[k]
+							}
+							if result.B != nil {
+								queryResult.B = &result.B[k]
+							}
+							if result.C != nil {
+								queryResult.C = &result.C[k]
+							}
+							if result.D != nil {
+								queryResult.D = &result.D[k]
+							}
+							if result.E != nil {
+								queryResult.E = &result.E[k]
+							}
+							if result.F != nil {
+								queryResult.F = &result.F[k]
+							}
+
+							if filterFn != nil && !filterFn(queryResult) {
+								continue
+							}
+
+							if !yield(queryResult) {
+								return
+							}
+						}
+						return
+					}
+
+					for k, entityId := range result.EntityId {
+						if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+							continue
+						}
+
+						queryResult.EntityId = entityId
+
+						if result.A != nil {
+							queryResult.A = &result.A[k]
+						} else if sparseA != nil {
+							queryResult.A = sparseA.get(entityId)
+						}
+						if result.B != nil {
+							queryResult.B = &result.B[k]
+						} else if sparseB != nil {
+							queryResult.B = sparseB.get(entityId)
+						}
+						if result.C != nil {
+							queryResult.C = &result.C[k]
+						} else if sparseC != nil {
+							queryResult.C = sparseC.get(entityId)
+						}
+						if result.D != nil {
+							queryResult.D = &result.D[k]
+						} else if sparseD != nil {
+							queryResult.D = sparseD.get(entityId)
+						}
+						if result.E != nil {
+							queryResult.E = &result.E[k]
+						} else if sparseE != nil {
+							queryResult.E = sparseE.get(entityId)
+						}
+						if result.F != nil {
+							queryResult.F = &result.F[k]
+						} else if sparseF != nil {
+							queryResult.F = sparseF.get(entityId)
+						}
+
+						if filterFn != nil && !filterFn(queryResult) {
+							continue
+						}
+
+						if !yield(queryResult) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	return channel
+}
+
+// Query for 7 components type.
+type Query7[A, B, C, D, E, F, G ComponentInterface] struct {
+	World              *World
+	componentsIds      []ComponentId
+	queryConfiguration QueryConfiguration
+
+	cache filterCache
+	// sorted is the buffer of the results of ForeachSorted, reused from one call to the next.
+	sorted []QueryResult7[A, B, C, D, E, F, G]
+}
+
+// Result returned for Query7.
+type QueryResult7[A, B, C, D, E, F, G ComponentInterface] struct {
+	EntityId EntityId
+	A        *A
+	B        *B
+	C        *C
+	D        *D
+	E        *E
+	F        *F
+	G        *G
+}
+
+type queryResultChunk7[A, B, C, D, E, F, G ComponentInterface] struct {
+	EntityId []EntityId
+	A        []A
+	B        []B
+	C        []C
+	D        []D
+	E        []E
+	F        []F
+	G        []G
+}
+
+// CreateQuery7 returns a new Query7, with components A, B, C, D, E, F, G.
+func CreateQuery7[A, B, C, D, E, F, G ComponentInterface](world *World, queryConfiguration QueryConfiguration) Query7[A, B, C, D, E, F, G] {
+	componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world), ComponentIdOf[D](world), ComponentIdOf[E](world), ComponentIdOf[F](world), ComponentIdOf[G](world)}
+	return Query7[A, B, C, D, E, F, G]{
+		World:              world,
+		componentsIds:      componentsIds,
+		queryConfiguration: queryConfiguration,
+		cache:              newFilterCache(world, componentsIds, queryConfiguration),
+	}
+}
+
+func (query *Query7[A, B, C, D, E, F, G]) GetComponentsIds() []ComponentId {
+	return query.componentsIds
+}
+
+func (query *Query7[A, B, C, D, E, F, G]) filter() []archetypeId {
+	return query.cache.resolve(query.World)
+}
+
+// Count returns the total of entities fetched for Query7.
+func (query *Query7[A, B, C, D, E, F, G]) Count() int {
+	return query.cache.count(query.World)
+}
+
+// GetEntitiesIds returns a slice of all the EntityId fetched for Query7.
+func (query *Query7[A, B, C, D, E, F, G]) GetEntitiesIds() []EntityId {
+	return query.cache.entities(query.World)
+}
+
+// Matches reports whether the entity is fetched by Query7, e.g. to filter the entities found by another mean.
+func (query *Query7[A, B, C, D, E, F, G]) Matches(entityId EntityId) bool {
+	return query.cache.matches(query.World, entityId)
+}
+
+// Foreach returns an iterator of QueryResult7 for all the entities with components A, B, C, D, E, F, G
+// to which filterFn function returns true.
+func (query *Query7[A, B, C, D, E, F, G]) Foreach(filterFn func(QueryResult7[A, B, C, D, E, F, G]) bool) iter.Seq[QueryResult7[A, B, C, D, E, F, G]] {
+	return func(yield func(QueryResult7[A, B, C, D, E, F, G]) bool) {
+		storageA := getStorage[A](query.World)
+		storageB := getStorage[B](query.World)
+		storageC := getStorage[C](query.World)
+		storageD := getStorage[D](query.World)
+		storageE := getStorage[E](query.World)
+		storageF := getStorage[F](query.World)
+		storageG := getStorage[G](query.World)
+		archetypesIds := query.filter()
+
+		// endIteration is not deferred, and the sparse components are iterated apart,
+		// so that the loop over the columns can be inlined in the range over Foreach.
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		yield = guardYield(query.World, query.componentsIds, archetypesIds, yield)
+		if query.cache.sparseIds != nil || storageA.getSparse() != nil || storageB.getSparse() != nil || storageC.getSparse() != nil || storageD.getSparse() != nil || storageE.getSparse() != nil || storageF.getSparse() != nil || storageG.getSparse() != nil {
+			query.foreachSparse(archetypesIds, filterFn, yield)
+			query.World.endIteration(query.componentsIds, archetypesIds, false)
+			return
+		}
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+			sliceD := storageD.getColumn(archetype.Id)
+			sliceE := storageE.getColumn(archetype.Id)
+			sliceF := storageF.getColumn(archetype.Id)
+			sliceG := storageG.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			result := query.sharedResult(archetype.Id)
+			for i, entityId := range archetype.entities {
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				if sliceD != nil {
+					result.D = &sliceD[i]
+				}
+				if sliceE != nil {
+					result.E = &sliceE[i]
+				}
+				if sliceF != nil {
+					result.F = &sliceF[i]
+				}
+				if sliceG != nil {
+					result.G = &sliceG[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					continue
+				}
+
+				if !yield(result) {
+					query.World.endIteration(query.componentsIds, archetypesIds, false)
+					return
+				}
+			}
+		}
+		query.World.endIteration(query.componentsIds, archetypesIds, false)
+	}
+}
+
+// foreachSparse yields the results of Foreach for a query over sparse components, looked up for each entity.
+func (query *Query7[A, B, C, D, E, F, G]) foreachSparse(archetypesIds []archetypeId, filterFn func(QueryResult7[A, B, C, D, E, F, G]) bool, yield func(QueryResult7[A, B, C, D, E, F, G]) bool) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	storageD := getStorage[D](query.World)
+	sparseD := storageD.getSparse()
+	storageE := getStorage[E](query.World)
+	sparseE := storageE.getSparse()
+	storageF := getStorage[F](query.World)
+	sparseF := storageF.getSparse()
+	storageG := getStorage[G](query.World)
+	sparseG := storageG.getSparse()
+	sparseIds := query.cache.sparseIds
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+		sliceD := storageD.getColumn(archetype.Id)
+		sliceE := storageE.getColumn(archetype.Id)
+		sliceF := storageF.getColumn(archetype.Id)
+		sliceG := storageG.getColumn(archetype.Id)
+
+		result := query.sharedResult(archetype.Id)
+		for i, entityId := range archetype.entities {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				continue
+			}
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			if sliceD != nil {
+				result.D = &sliceD[i]
+			} else if sparseD != nil {
+				result.D = sparseD.get(entityId)
+			}
+			if sliceE != nil {
+				result.E = &sliceE[i]
+			} else if sparseE != nil {
+				result.E = sparseE.get(entityId)
+			}
+			if sliceF != nil {
+				result.F = &sliceF[i]
+			} else if sparseF != nil {
+				result.F = sparseF.get(entityId)
+			}
+			if sliceG != nil {
+				result.G = &sliceG[i]
+			} else if sparseG != nil {
+				result.G = sparseG.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				continue
+			}
+
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// sharedResult returns a QueryResult7 holding the values of the shared components of the archetype,
+// the same for all its entities.
+func (query *Query7[A, B, C, D, E, F, G]) sharedResult(archetypeId archetypeId) QueryResult7[A, B, C, D, E, F, G] {
+	var result QueryResult7[A, B, C, D, E, F, G]
+	result.A = getStorage[A](query.World).getShared().get(archetypeId)
+	result.B = getStorage[B](query.World).getShared().get(archetypeId)
+	result.C = getStorage[C](query.World).getShared().get(archetypeId)
+	result.D = getStorage[D](query.World).getShared().get(archetypeId)
+	result.E = getStorage[E](query.World).getShared().get(archetypeId)
+	result.F = getStorage[F](query.World).getShared().get(archetypeId)
+	result.G = getStorage[G](query.World).getShared().get(archetypeId)
+
+	return result
+}
+
+// ForeachSorted returns an iterator of QueryResult7 for all the entities with components A, B, C, D, E, F, G,
+// in the order defined by less, e.g. back to front for the rendering of transparent sprites.
+// The entities with an equal order keep the order of Foreach.
+//
+// The results are sorted in a buffer of the query reused from one call to the next: the components are not copied.
+func (query *Query7[A, B, C, D, E, F, G]) ForeachSorted(less func(a, b QueryResult7[A, B, C, D, E, F, G]) bool) iter.Seq[QueryResult7[A, B, C, D, E, F, G]] {
+	return func(yield func(QueryResult7[A, B, C, D, E, F, G]) bool) {
+		query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+		defer clear(query.sorted)
+
+		archetypesIds := query.filter()
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+		for _, result := range query.sorted {
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// SortArchetypes moves the rows of the archetypes fetched by Query7, so that Foreach iterates
+// the entities of each archetype in the order defined by less, without the cost of ForeachSorted.
+// The archetypes are still iterated in their order of creation.
+//
+// The order holds until the next structural change: adding or removing an entity or a component moves rows.
+// SortArchetypes must not be called while a query is iterated, and has no effect on a deterministic World, see SetDeterministic.
+func (query *Query7[A, B, C, D, E, F, G]) SortArchetypes(less func(a, b QueryResult7[A, B, C, D, E, F, G]) bool) {
+	query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+	defer clear(query.sorted)
+
+	sortRows(query.World, query.sorted, func(result QueryResult7[A, B, C, D, E, F, G]) EntityId {
+		return result.EntityId
+	})
+}
+
+// Task executes fn in parallel across workersCount goroutines for all entities matching the query.
+// Each entity's components are passed to fn through QueryResult7.
+// If filterFn is provided and returns false for an entity, that entity is skipped.
+//
+// The workersCount parameter determines the number of parallel workers.
+// Data is automatically partitioned across workers for optimal performance.
+func (query *Query7[A, B, C, D, E, F, G]) Task(workersCount int, filterFn func(QueryResult7[A, B, C, D, E, F, G]) bool, fn func(result QueryResult7[A, B, C, D, E, F, G])) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	sharedA := storageA.getShared()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	sharedB := storageB.getShared()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	sharedC := storageC.getShared()
+	storageD := getStorage[D](query.World)
+	sparseD := storageD.getSparse()
+	sharedD := storageD.getShared()
+	storageE := getStorage[E](query.World)
+	sparseE := storageE.getSparse()
+	sharedE := storageE.getShared()
+	storageF := getStorage[F](query.World)
+	sparseF := storageF.getSparse()
+	sharedF := storageF.getShared()
+	storageG := getStorage[G](query.World)
+	sparseG := storageG.getSparse()
+	sharedG := storageG.getShared()
+	sparseIds := query.cache.sparseIds
+	// Without sparse component, the components are read from the columns only, as in a tight loop.
+	tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil && sparseD == nil && sparseE == nil && sparseF == nil && sparseG == nil
+	archetypesIds := query.filter()
+
+	query.World.beginIteration(query.componentsIds, archetypesIds, true)
+	defer query.World.endIteration(query.componentsIds, archetypesIds, true)
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+		sliceD := storageD.getColumn(archetype.Id)
+		sliceE := storageE.getColumn(archetype.Id)
+		sliceF := storageF.getColumn(archetype.Id)
+		sliceG := storageG.getColumn(archetype.Id)
+
+		// The shared components are the same for all the entities of the archetype.
+		var shared QueryResult7[A, B, C, D, E, F, G]
+		shared.A = sharedA.get(archetype.Id)
+		shared.B = sharedB.get(archetype.Id)
+		shared.C = sharedC.get(archetype.Id)
+		shared.D = sharedD.get(archetype.Id)
+		shared.E = sharedE.get(archetype.Id)
+		shared.F = sharedF.get(archetype.Id)
+		shared.G = sharedG.get(archetype.Id)
+
+		if tableOnly {
+			task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+				result := shared
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				if sliceD != nil {
+					result.D = &sliceD[i]
+				}
+				if sliceE != nil {
+					result.E = &sliceE[i]
+				}
+				if sliceF != nil {
+					result.F = &sliceF[i]
+				}
+				if sliceG != nil {
+					result.G = &sliceG[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					return
+				}
+
+				fn(result)
+			})
+			continue
+		}
+
+		task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				return
+			}
+
+			result := shared
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			if sliceD != nil {
+				result.D = &sliceD[i]
+			} else if sparseD != nil {
+				result.D = sparseD.get(entityId)
+			}
+			if sliceE != nil {
+				result.E = &sliceE[i]
+			} else if sparseE != nil {
+				result.E = sparseE.get(entityId)
+			}
+			if sliceF != nil {
+				result.F = &sliceF[i]
+			} else if sparseF != nil {
+				result.F = sparseF.get(entityId)
+			}
+			if sliceG != nil {
+				result.G = &sliceG[i]
+			} else if sparseG != nil {
+				result.G = sparseG.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				return
+			}
+
+			fn(result)
+		})
+	}
+}
+
+// ForeachChannel returns a channel of iterators of QueryResult7 for all the entities with components A, B, C, D, E, F, G
+// to which filterFn function returns true.
+// The parameter chunkSize defines the size of each iterators.
+//
+// Deprecated: ForeachChannel is deprecated and will be removed in a future version.
+// Use Task(workersCount, filterFn, fn) instead, which offers better performance
+// and a simpler API for parallel iteration.
+func (query *Query7[A, B, C, D, E, F, G]) ForeachChannel(chunkSize int, filterFn func(QueryResult7[A, B, C, D, E, F, G]) bool) <-chan iter.Seq[QueryResult7[A, B, C, D, E, F, G]] {
+	if chunkSize == 0 {
+		panic("chunk size must be greater than zero")
+	}
+
+	channelsCount := math.Ceil(float64(query.Count()) / float64(chunkSize))
+	channel := make(chan iter.Seq[QueryResult7[A, B, C, D, E, F, G]], int(channelsCount))
+
+	go func() {
+		defer close(channel)
+
+		storageA := getStorage[A](query.World)
+		sparseA := storageA.getSparse()
+		sharedA := storageA.getShared()
+		storageB := getStorage[B](query.World)
+		sparseB := storageB.getSparse()
+		sharedB := storageB.getShared()
+		storageC := getStorage[C](query.World)
+		sparseC := storageC.getSparse()
+		sharedC := storageC.getShared()
+		storageD := getStorage[D](query.World)
+		sparseD := storageD.getSparse()
+		sharedD := storageD.getShared()
+		storageE := getStorage[E](query.World)
+		sparseE := storageE.getSparse()
+		sharedE := storageE.getShared()
+		storageF := getStorage[F](query.World)
+		sparseF := storageF.getSparse()
+		sharedF := storageF.getShared()
+		storageG := getStorage[G](query.World)
+		sparseG := storageG.getSparse()
+		sharedG := storageG.getShared()
+		sparseIds := query.cache.sparseIds
+		// Without sparse component, the components are read from the columns only, as in a tight loop.
+		tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil && sparseD == nil && sparseE == nil && sparseF == nil && sparseG == nil
+		archetypesIds := query.filter()
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+			sliceD := storageD.getColumn(archetype.Id)
+			sliceE := storageE.getColumn(archetype.Id)
+			sliceF := storageF.getColumn(archetype.Id)
+			sliceG := storageG.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			var shared QueryResult7[A, B, C, D, E, F, G]
+			shared.A = sharedA.get(archetype.Id)
+			shared.B = sharedB.get(archetype.Id)
+			shared.C = sharedC.get(archetype.Id)
+			shared.D = sharedD.get(archetype.Id)
+			shared.E = sharedE.get(archetype.Id)
+			shared.F = sharedF.get(archetype.Id)
+			shared.G = sharedG.get(archetype.Id)
+
+			for i := 0; i < len(archetype.entities); i += chunkSize {
+				result := queryResultChunk7[A, B, C, D, E, F, G]{}
+				end := min(chunkSize, len(archetype.entities[i:]))
+
+				// Set the capacity of each chunk so that appending to a chunk does
+				// not modify the original slice.
+				result.EntityId = archetype.entities[i : i+end : i+end]
+				if sliceA != nil {
+					result.A = sliceA[i : i+end : i+end]
+				}
+				if sliceB != nil {
+					result.B = sliceB[i : i+end : i+end]
+				}
+				if sliceC != nil {
+					result.C = sliceC[i : i+end : i+end]
+				}
+				if sliceD != nil {
+					result.D = sliceD[i : i+end : i+end]
+				}
+				if sliceE != nil {
+					result.E = sliceE[i : i+end : i+end]
+				}
+				if sliceF != nil {
+					result.F = sliceF[i : i+end : i+end]
+				}
+				if sliceG != nil {
+					result.G = sliceG[i : i+end : i+end]
+				}
+
+				channel <- func(yield func(QueryResult7[A, B, C, D, E, F, G]) bool) {
+					query.World.beginIteration(query.componentsIds, archetypesIds, false)
+					defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+					queryResult := shared
+					if tableOnly {
+						for k, entityId := range result.EntityId {
+							queryResult.EntityId = entityId
+							if result.A != nil {
+								queryResult.A = &result.A[k]
+							}
+							if result.B != nil {
+								queryResult.B = &result.B[k]
+							}
+							if result.C != nil {
+								queryResult.C = &result.C[k]
+							}
+							if result.D != nil {
+								queryResult.D = &result.D[k]
+							}
+							if result.E != nil {
+								queryResult.E = &result.E[k]
+							}
+							if result.F != nil {
+								queryResult.F = &result.F[k]
+							}
+							if result.G != nil {
+								queryResult.G = &result.G[k]
+							}
+
+							if filterFn != nil && !filterFn(queryResult) {
+								continue
+							}
+
+							if !yield(queryResult) {
+								return
+							}
+						}
+						return
+					}
+
+					for k, entityId := range result.EntityId {
+						if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+							continue
+						}
+
+						queryResult.EntityId = entityId
+
+						if result.A != nil {
+							queryResult.A = &result.A[k]
+						} else if sparseA != nil {
+							queryResult.A = sparseA.get(entityId)
+						}
+						if result.B != nil {
+							queryResult.B = &result.B[k]
+						} else if sparseB != nil {
+							queryResult.B = sparseB.get(entityId)
+						}
+						if result.C != nil {
+							queryResult.C = &result.C[k]
+						} else if sparseC != nil {
+							queryResult.C = sparseC.get(entityId)
+						}
+						if result.D != nil {
+							queryResult.D = &result.D[k]
+						} else if sparseD != nil {
+							queryResult.D = sparseD.get(entityId)
+						}
+						if result.E != nil {
+							queryResult.E = &result.E[k]
+						} else if sparseE != nil {
+							queryResult.E = sparseE.get(entityId)
+						}
+						if result.F != nil {
+							queryResult.F = &result.F[k]
+						} else if sparseF != nil {
+							queryResult.F = sparseF.get(entityId)
+						}
+						if result.G != nil {
+							queryResult.G = &result.G[k]
+						} else if sparseG != nil {
+							queryResult.G = sparseG.get(entityId)
+						}
+
+						if filterFn != nil && !filterFn(queryResult) {
+							continue
+						}
+
+						if !yield(queryResult) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	return channel
+}
+
+// Query for 8 components type.
+type Query8[A, B, C, D, E, F, G, H ComponentInterface] struct {
+	World              *World
+	componentsIds      []ComponentId
+	queryConfiguration QueryConfiguration
+
+	cache filterCache
+	// sorted is the buffer of the results of ForeachSorted, reused from one call to the next.
+	sorted []QueryResult8[A, B, C, D, E, F, G, H]
+}
+
+// Result returned for Query8.
+type QueryResult8[A, B, C, D, E, F, G, H ComponentInterface] struct {
+	EntityId EntityId
+	A        *A
+	B        *B
+	C        *C
+	D        *D
+	E        *E
+	F        *F
+	G        *G
+	H        *H
+}
+
+type queryResultChunk8[A, B, C, D, E, F, G, H ComponentInterface] struct {
+	EntityId []EntityId
+	A        []A
+	B        []B
+	C        []C
+	D        []D
+	E        []E
+	F        []F
+	G        []G
+	H        []H
+}
+
+// CreateQuery8 returns a new Query8, with components A, B, C, D, E, F, G, H.
+func CreateQuery8[A, B, C, D, E, F, G, H ComponentInterface](world *World, queryConfiguration QueryConfiguration) Query8[A, B, C, D, E, F, G, H] {
+	componentsIds := []ComponentId{ComponentIdOf[A](world), ComponentIdOf[B](world), ComponentIdOf[C](world), ComponentIdOf[D](world), ComponentIdOf[E](world), ComponentIdOf[F](world), ComponentIdOf[G](world), ComponentIdOf[H](world)}
+	return Query8[A, B, C, D, E, F, G, H]{
+		World:              world,
+		componentsIds:      componentsIds,
+		queryConfiguration: queryConfiguration,
+		cache:              newFilterCache(world, componentsIds, queryConfiguration),
+	}
+}
+
+func (query *Query8[A, B, C, D, E, F, G, H]) GetComponentsIds() []ComponentId {
+	return query.componentsIds
+}
+
+func (query *Query8[A, B, C, D, E, F, G, H]) filter() []archetypeId {
+	return query.cache.resolve(query.World)
+}
+
+// Count returns the total of entities fetched for Query8.
+func (query *Query8[A, B, C, D, E, F, G, H]) Count() int {
+	return query.cache.count(query.World)
+}
+
+// GetEntitiesIds returns a slice of all the EntityId fetched for Query8.
+func (query *Query8[A, B, C, D, E, F, G, H]) GetEntitiesIds() []EntityId {
+	return query.cache.entities(query.World)
+}
+
+// Matches reports whether the entity is fetched by Query8, e.g. to filter the entities found by another mean.
+func (query *Query8[A, B, C, D, E, F, G, H]) Matches(entityId EntityId) bool {
+	return query.cache.matches(query.World, entityId)
+}
+
+// Foreach returns an iterator of QueryResult8 for all the entities with components A, B, C, D, E, F, G, H
+// to which filterFn function returns true.
+func (query *Query8[A, B, C, D, E, F, G, H]) Foreach(filterFn func(QueryResult8[A, B, C, D, E, F, G, H]) bool) iter.Seq[QueryResult8[A, B, C, D, E, F, G, H]] {
+	return func(yield func(QueryResult8[A, B, C, D, E, F, G, H]) bool) {
+		storageA := getStorage[A](query.World)
+		storageB := getStorage[B](query.World)
+		storageC := getStorage[C](query.World)
+		storageD := getStorage[D](query.World)
+		storageE := getStorage[E](query.World)
+		storageF := getStorage[F](query.World)
+		storageG := getStorage[G](query.World)
+		storageH := getStorage[H](query.World)
+		archetypesIds := query.filter()
+
+		// endIteration is not deferred, and the sparse components are iterated apart,
+		// so that the loop over the columns can be inlined in the range over Foreach.
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		yield = guardYield(query.World, query.componentsIds, archetypesIds, yield)
+		if query.cache.sparseIds != nil || storageA.getSparse() != nil || storageB.getSparse() != nil || storageC.getSparse() != nil || storageD.getSparse() != nil || storageE.getSparse() != nil || storageF.getSparse() != nil || storageG.getSparse() != nil || storageH.getSparse() != nil {
+			query.foreachSparse(archetypesIds, filterFn, yield)
+			query.World.endIteration(query.componentsIds, archetypesIds, false)
+			return
+		}
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+			sliceD := storageD.getColumn(archetype.Id)
+			sliceE := storageE.getColumn(archetype.Id)
+			sliceF := storageF.getColumn(archetype.Id)
+			sliceG := storageG.getColumn(archetype.Id)
+			sliceH := storageH.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			result := query.sharedResult(archetype.Id)
+			for i, entityId := range archetype.entities {
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				if sliceD != nil {
+					result.D = &sliceD[i]
+				}
+				if sliceE != nil {
+					result.E = &sliceE[i]
+				}
+				if sliceF != nil {
+					result.F = &sliceF[i]
+				}
+				if sliceG != nil {
+					result.G = &sliceG[i]
+				}
+				if sliceH != nil {
+					result.H = &sliceH[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					continue
+				}
+
+				if !yield(result) {
+					query.World.endIteration(query.componentsIds, archetypesIds, false)
+					return
+				}
+			}
+		}
+		query.World.endIteration(query.componentsIds, archetypesIds, false)
+	}
+}
+
+// foreachSparse yields the results of Foreach for a query over sparse components, looked up for each entity.
+func (query *Query8[A, B, C, D, E, F, G, H]) foreachSparse(archetypesIds []archetypeId, filterFn func(QueryResult8[A, B, C, D, E, F, G, H]) bool, yield func(QueryResult8[A, B, C, D, E, F, G, H]) bool) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	storageD := getStorage[D](query.World)
+	sparseD := storageD.getSparse()
+	storageE := getStorage[E](query.World)
+	sparseE := storageE.getSparse()
+	storageF := getStorage[F](query.World)
+	sparseF := storageF.getSparse()
+	storageG := getStorage[G](query.World)
+	sparseG := storageG.getSparse()
+	storageH := getStorage[H](query.World)
+	sparseH := storageH.getSparse()
+	sparseIds := query.cache.sparseIds
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+		sliceD := storageD.getColumn(archetype.Id)
+		sliceE := storageE.getColumn(archetype.Id)
+		sliceF := storageF.getColumn(archetype.Id)
+		sliceG := storageG.getColumn(archetype.Id)
+		sliceH := storageH.getColumn(archetype.Id)
+
+		result := query.sharedResult(archetype.Id)
+		for i, entityId := range archetype.entities {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				continue
+			}
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			if sliceD != nil {
+				result.D = &sliceD[i]
+			} else if sparseD != nil {
+				result.D = sparseD.get(entityId)
+			}
+			if sliceE != nil {
+				result.E = &sliceE[i]
+			} else if sparseE != nil {
+				result.E = sparseE.get(entityId)
+			}
+			if sliceF != nil {
+				result.F = &sliceF[i]
+			} else if sparseF != nil {
+				result.F = sparseF.get(entityId)
+			}
+			if sliceG != nil {
+				result.G = &sliceG[i]
+			} else if sparseG != nil {
+				result.G = sparseG.get(entityId)
+			}
+			if sliceH != nil {
+				result.H = &sliceH[i]
+			} else if sparseH != nil {
+				result.H = sparseH.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				continue
+			}
+
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// sharedResult returns a QueryResult8 holding the values of the shared components of the archetype,
+// the same for all its entities.
+func (query *Query8[A, B, C, D, E, F, G, H]) sharedResult(archetypeId archetypeId) QueryResult8[A, B, C, D, E, F, G, H] {
+	var result QueryResult8[A, B, C, D, E, F, G, H]
+	result.A = getStorage[A](query.World).getShared().get(archetypeId)
+	result.B = getStorage[B](query.World).getShared().get(archetypeId)
+	result.C = getStorage[C](query.World).getShared().get(archetypeId)
+	result.D = getStorage[D](query.World).getShared().get(archetypeId)
+	result.E = getStorage[E](query.World).getShared().get(archetypeId)
+	result.F = getStorage[F](query.World).getShared().get(archetypeId)
+	result.G = getStorage[G](query.World).getShared().get(archetypeId)
+	result.H = getStorage[H](query.World).getShared().get(archetypeId)
+
+	return result
+}
+
+// ForeachSorted returns an iterator of QueryResult8 for all the entities with components A, B, C, D, E, F, G, H,
+// in the order defined by less, e.g. back to front for the rendering of transparent sprites.
+// The entities with an equal order keep the order of Foreach.
+//
+// The results are sorted in a buffer of the query reused from one call to the next: the components are not copied.
+func (query *Query8[A, B, C, D, E, F, G, H]) ForeachSorted(less func(a, b QueryResult8[A, B, C, D, E, F, G, H]) bool) iter.Seq[QueryResult8[A, B, C, D, E, F, G, H]] {
+	return func(yield func(QueryResult8[A, B, C, D, E, F, G, H]) bool) {
+		query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+		defer clear(query.sorted)
+
+		archetypesIds := query.filter()
+		query.World.beginIteration(query.componentsIds, archetypesIds, false)
+		defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+		for _, result := range query.sorted {
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// SortArchetypes moves the rows of the archetypes fetched by Query8, so that Foreach iterates
+// the entities of each archetype in the order defined by less, without the cost of ForeachSorted.
+// The archetypes are still iterated in their order of creation.
+//
+// The order holds until the next structural change: adding or removing an entity or a component moves rows.
+// SortArchetypes must not be called while a query is iterated, and has no effect on a deterministic World, see SetDeterministic.
+func (query *Query8[A, B, C, D, E, F, G, H]) SortArchetypes(less func(a, b QueryResult8[A, B, C, D, E, F, G, H]) bool) {
+	query.sorted = sortResults(query.sorted[:0], query.Foreach(nil), less)
+	defer clear(query.sorted)
+
+	sortRows(query.World, query.sorted, func(result QueryResult8[A, B, C, D, E, F, G, H]) EntityId {
+		return result.EntityId
+	})
+}
+
+// Task executes fn in parallel across workersCount goroutines for all entities matching the query.
+// Each entity's components are passed to fn through QueryResult8.
+// If filterFn is provided and returns false for an entity, that entity is skipped.
+//
+// The workersCount parameter determines the number of parallel workers.
+// Data is automatically partitioned across workers for optimal performance.
+func (query *Query8[A, B, C, D, E, F, G, H]) Task(workersCount int, filterFn func(QueryResult8[A, B, C, D, E, F, G, H]) bool, fn func(result QueryResult8[A, B, C, D, E, F, G, H])) {
+	storageA := getStorage[A](query.World)
+	sparseA := storageA.getSparse()
+	sharedA := storageA.getShared()
+	storageB := getStorage[B](query.World)
+	sparseB := storageB.getSparse()
+	sharedB := storageB.getShared()
+	storageC := getStorage[C](query.World)
+	sparseC := storageC.getSparse()
+	sharedC := storageC.getShared()
+	storageD := getStorage[D](query.World)
+	sparseD := storageD.getSparse()
+	sharedD := storageD.getShared()
+	storageE := getStorage[E](query.World)
+	sparseE := storageE.getSparse()
+	sharedE := storageE.getShared()
+	storageF := getStorage[F](query.World)
+	sparseF := storageF.getSparse()
+	sharedF := storageF.getShared()
+	storageG := getStorage[G](query.World)
+	sparseG := storageG.getSparse()
+	sharedG := storageG.getShared()
+	storageH := getStorage[H](query.World)
+	sparseH := storageH.getSparse()
+	sharedH := storageH.getShared()
+	sparseIds := query.cache.sparseIds
+	// Without sparse component, the components are read from the columns only, as in a tight loop.
+	tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil && sparseD == nil && sparseE == nil && sparseF == nil && sparseG == nil && sparseH == nil
+	archetypesIds := query.filter()
+
+	query.World.beginIteration(query.componentsIds, archetypesIds, true)
+	defer query.World.endIteration(query.componentsIds, archetypesIds, true)
+
+	for _, archetypeId := range archetypesIds {
+		archetype := &query.World.archetypes[archetypeId]
+		sliceA := storageA.getColumn(archetype.Id)
+		sliceB := storageB.getColumn(archetype.Id)
+		sliceC := storageC.getColumn(archetype.Id)
+		sliceD := storageD.getColumn(archetype.Id)
+		sliceE := storageE.getColumn(archetype.Id)
+		sliceF := storageF.getColumn(archetype.Id)
+		sliceG := storageG.getColumn(archetype.Id)
+		sliceH := storageH.getColumn(archetype.Id)
+
+		// The shared components are the same for all the entities of the archetype.
+		var shared QueryResult8[A, B, C, D, E, F, G, H]
+		shared.A = sharedA.get(archetype.Id)
+		shared.B = sharedB.get(archetype.Id)
+		shared.C = sharedC.get(archetype.Id)
+		shared.D = sharedD.get(archetype.Id)
+		shared.E = sharedE.get(archetype.Id)
+		shared.F = sharedF.get(archetype.Id)
+		shared.G = sharedG.get(archetype.Id)
+		shared.H = sharedH.get(archetype.Id)
+
+		if tableOnly {
+			task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+				result := shared
+				if sliceA != nil {
+					result.A = &sliceA[i]
+				}
+				if sliceB != nil {
+					result.B = &sliceB[i]
+				}
+				if sliceC != nil {
+					result.C = &sliceC[i]
+				}
+				if sliceD != nil {
+					result.D = &sliceD[i]
+				}
+				if sliceE != nil {
+					result.E = &sliceE[i]
+				}
+				if sliceF != nil {
+					result.F = &sliceF[i]
+				}
+				if sliceG != nil {
+					result.G = &sliceG[i]
+				}
+				if sliceH != nil {
+					result.H = &sliceH[i]
+				}
+				result.EntityId = entityId
+
+				if filterFn != nil && !filterFn(result) {
+					return
+				}
+
+				fn(result)
+			})
+			continue
+		}
+
+		task(workersCount, archetype.entities, func(i int, entityId EntityId) {
+			if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+				return
+			}
+
+			result := shared
+
+			if sliceA != nil {
+				result.A = &sliceA[i]
+			} else if sparseA != nil {
+				result.A = sparseA.get(entityId)
+			}
+			if sliceB != nil {
+				result.B = &sliceB[i]
+			} else if sparseB != nil {
+				result.B = sparseB.get(entityId)
+			}
+			if sliceC != nil {
+				result.C = &sliceC[i]
+			} else if sparseC != nil {
+				result.C = sparseC.get(entityId)
+			}
+			if sliceD != nil {
+				result.D = &sliceD[i]
+			} else if sparseD != nil {
+				result.D = sparseD.get(entityId)
+			}
+			if sliceE != nil {
+				result.E = &sliceE[i]
+			} else if sparseE != nil {
+				result.E = sparseE.get(entityId)
+			}
+			if sliceF != nil {
+				result.F = &sliceF[i]
+			} else if sparseF != nil {
+				result.F = sparseF.get(entityId)
+			}
+			if sliceG != nil {
+				result.G = &sliceG[i]
+			} else if sparseG != nil {
+				result.G = sparseG.get(entityId)
+			}
+			if sliceH != nil {
+				result.H = &sliceH[i]
+			} else if sparseH != nil {
+				result.H = sparseH.get(entityId)
+			}
+			result.EntityId = entityId
+
+			if filterFn != nil && !filterFn(result) {
+				return
+			}
+
+			fn(result)
+		})
+	}
+}
+
+// ForeachChannel returns a channel of iterators of QueryResult8 for all the entities with components A, B, C, D, E, F, G, H
+// to which filterFn function returns true.
+// The parameter chunkSize defines the size of each iterators.
+//
+// Deprecated: ForeachChannel is deprecated and will be removed in a future version.
+// Use Task(workersCount, filterFn, fn) instead, which offers better performance
+// and a simpler API for parallel iteration.
+func (query *Query8[A, B, C, D, E, F, G, H]) ForeachChannel(chunkSize int, filterFn func(QueryResult8[A, B, C, D, E, F, G, H]) bool) <-chan iter.Seq[QueryResult8[A, B, C, D, E, F, G, H]] {
+	if chunkSize == 0 {
+		panic("chunk size must be greater than zero")
+	}
+
+	channelsCount := math.Ceil(float64(query.Count()) / float64(chunkSize))
+	channel := make(chan iter.Seq[QueryResult8[A, B, C, D, E, F, G, H]], int(channelsCount))
+
+	go func() {
+		defer close(channel)
+
+		storageA := getStorage[A](query.World)
+		sparseA := storageA.getSparse()
+		sharedA := storageA.getShared()
+		storageB := getStorage[B](query.World)
+		sparseB := storageB.getSparse()
+		sharedB := storageB.getShared()
+		storageC := getStorage[C](query.World)
+		sparseC := storageC.getSparse()
+		sharedC := storageC.getShared()
+		storageD := getStorage[D](query.World)
+		sparseD := storageD.getSparse()
+		sharedD := storageD.getShared()
+		storageE := getStorage[E](query.World)
+		sparseE := storageE.getSparse()
+		sharedE := storageE.getShared()
+		storageF := getStorage[F](query.World)
+		sparseF := storageF.getSparse()
+		sharedF := storageF.getShared()
+		storageG := getStorage[G](query.World)
+		sparseG := storageG.getSparse()
+		sharedG := storageG.getShared()
+		storageH := getStorage[H](query.World)
+		sparseH := storageH.getSparse()
+		sharedH := storageH.getShared()
+		sparseIds := query.cache.sparseIds
+		// Without sparse component, the components are read from the columns only, as in a tight loop.
+		tableOnly := sparseIds == nil && sparseA == nil && sparseB == nil && sparseC == nil && sparseD == nil && sparseE == nil && sparseF == nil && sparseG == nil && sparseH == nil
+		archetypesIds := query.filter()
+
+		for _, archetypeId := range archetypesIds {
+			archetype := &query.World.archetypes[archetypeId]
+			sliceA := storageA.getColumn(archetype.Id)
+			sliceB := storageB.getColumn(archetype.Id)
+			sliceC := storageC.getColumn(archetype.Id)
+			sliceD := storageD.getColumn(archetype.Id)
+			sliceE := storageE.getColumn(archetype.Id)
+			sliceF := storageF.getColumn(archetype.Id)
+			sliceG := storageG.getColumn(archetype.Id)
+			sliceH := storageH.getColumn(archetype.Id)
+
+			// The shared components are the same for all the entities of the archetype.
+			var shared QueryResult8[A, B, C, D, E, F, G, H]
+			shared.A = sharedA.get(archetype.Id)
+			shared.B = sharedB.get(archetype.Id)
+			shared.C = sharedC.get(archetype.Id)
+			shared.D = sharedD.get(archetype.Id)
+			shared.E = sharedE.get(archetype.Id)
+			shared.F = sharedF.get(archetype.Id)
+			shared.G = sharedG.get(archetype.Id)
+			shared.H = sharedH.get(archetype.Id)
+
+			for i := 0; i < len(archetype.entities); i += chunkSize {
+				result := queryResultChunk8[A, B, C, D, E, F, G, H]{}
+				end := min(chunkSize, len(archetype.entities[i:]))
+
+				// Set the capacity of each chunk so that appending to a chunk does
+				// not modify the original slice.
+				result.EntityId = archetype.entities[i : i+end : i+end]
+				if sliceA != nil {
+					result.A = sliceA[i : i+end : i+end]
+				}
+				if sliceB != nil {
+					result.B = sliceB[i : i+end : i+end]
+				}
+				if sliceC != nil {
+					result.C = sliceC[i : i+end : i+end]
+				}
+				if sliceD != nil {
+					result.D = sliceD[i : i+end : i+end]
+				}
+				if sliceE != nil {
+					result.E = sliceE[i : i+end : i+end]
+				}
+				if sliceF != nil {
+					result.F = sliceF[i : i+end : i+end]
+				}
+				if sliceG != nil {
+					result.G = sliceG[i : i+end : i+end]
+				}
+				if sliceH != nil {
+					result.H = sliceH[i : i+end : i+end]
+				}
+
+				channel <- func(yield func(QueryResult8[A, B, C, D, E, F, G, H]) bool) {
+					query.World.beginIteration(query.componentsIds, archetypesIds, false)
+					defer query.World.endIteration(query.componentsIds, archetypesIds, false)
+
+					queryResult := shared
+					if tableOnly {
+						for k, entityId := range result.EntityId {
+							queryResult.EntityId = entityId
+							if result.A != nil {
+								queryResult.A = &result.A[k]
+							}
+							if result.B != nil {
+								queryResult.B = &result.B[k]
+							}
+							if result.C != nil {
+								queryResult.C = &result.C[k]
+							}
+							if result.D != nil {
+								queryResult.D = &result.D[k]
+							}
+							if result.E != nil {
+								queryResult.E = &result.E[k]
+							}
+							if result.F != nil {
+								queryResult.F = &result.F[k]
+							}
+							if result.G != nil {
+								queryResult.G = &result.G[k]
+							}
+							if result.H != nil {
+								queryResult.H = &result.H[k]
+							}
+
+							if filterFn != nil && !filterFn(queryResult) {
+								continue
+							}
+
+							if !yield(queryResult) {
+								return
+							}
+						}
+						return
+					}
+
+					for k, entityId := range result.EntityId {
+						if sparseIds != nil && !query.World.hasSparseComponents(entityId, sparseIds) {
+							continue
+						}
+
+						queryResult.EntityId = entityId
+
+						if result.A != nil {
+							queryResult.A = &result.A[k]
+						} else if sparseA != nil {
+							queryResult.A = sparseA.get(entityId)
+						}
+						if result.B != nil {
+							queryResult.B = &result.B[k]
+						} else if sparseB != nil {
+							queryResult.B = sparseB.get(entityId)
+						}
+						if result.C != nil {
+							queryResult.C = &result.C[k]
+						} else if sparseC != nil {
+							queryResult.C = sparseC.get(entityId)
+						}
+						if result.D != nil {
+							queryResult.D = &result.D[k]
+						} else if sparseD != nil {
+							queryResult.D = sparseD.get(entityId)
+						}
+						if result.E != nil {
+							queryResult.E = &result.E[k]
+						} else if sparseE != nil {
+							queryResult.E = sparseE.get(entityId)
+						}
+						if result.F != nil {
+							queryResult.F = &result.F[k]
+						} else if sparseF != nil {
+							queryResult.F = sparseF.get(entityId)
+						}
+						if result.G != nil {
+							queryResult.G = &result.G[k]
+						} else if sparseG != nil {
+							queryResult.G = sparseG.get(entityId)
+						}
+						if result.H != nil {
+							queryResult.H = &result.H[k]
+						} else if sparseH != nil {
+							queryResult.H = sparseH.get(entityId)
+						}
+
+						if filterFn != nil && !filterFn(queryResult) {
+							continue
+						}
+
+						if !yield(queryResult) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	return channel
+}
@@ -241,6 +241,40 @@ func TestQuery2_Count(t *testing.T) {
 	}
 }
 
+func TestQuery2_Matches(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testComponent2](world, &ComponentConfig[testComponent2]{})
+	RegisterComponent[testSparseComponent](world, &ComponentConfig[testSparseComponent]{Storage: SPARSE_STORAGE})
+
+	query := CreateQuery2[testComponent1, testSparseComponent](world, QueryConfiguration{Tags: []TagId{TAG_1}})
+	optionalQuery := CreateQuery2[testComponent1, testComponent2](world, QueryConfiguration{OptionalComponents: []OptionalComponent{testComponent2Id}})
+
+	entityId := world.CreateEntity()
+	AddComponent(world, entityId, testComponent1{})
+	if query.Matches(entityId) || !optionalQuery.Matches(entityId) {
+		t.Errorf("only the query with an optional component should match the entity")
+	}
+
+	AddComponent(world, entityId, testSparseComponent{})
+	if query.Matches(entityId) {
+		t.Errorf("the query should not match an entity without its tag")
+	}
+	world.AddTag(TAG_1, entityId)
+	if !query.Matches(entityId) {
+		t.Errorf("the query should match the entity")
+	}
+
+	RemoveComponent[testSparseComponent](world, entityId)
+	if query.Matches(entityId) {
+		t.Errorf("the query should not match an entity without its sparse component")
+	}
+	world.RemoveEntity(entityId)
+	if optionalQuery.Matches(entityId) {
+		t.Errorf("the query should not match a removed entity")
+	}
+}
+
 func TestQuery2_Foreach(t *testing.T) {
 	var entities []EntityId
 	world := CreateWorld(TEST_ENTITY_NUMBER)
@@ -1630,3 +1664,73 @@ func TestQueryCacheInvalidation(t *testing.T) {
 		t.Fatalf("after removal: expected 1, got %d", got)
 	}
 }
+
+func TestQuerySparseComponent(t *testing.T) {
+	world := CreateWorld(64)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testSparseComponent](world, &ComponentConfig[testSparseComponent]{Storage: SPARSE_STORAGE})
+	sparseId := ComponentIdOf[testSparseComponent](world)
+
+	for i := range 20 {
+		entityId := world.CreateEntity()
+		AddComponent(world, entityId, testComponent1{testComponent{x: i}})
+		if i%2 == 0 {
+			AddComponent(world, entityId, testSparseComponent{testComponent{x: i}})
+		}
+	}
+
+	query := CreateQuery2[testComponent1, testSparseComponent](world, QueryConfiguration{})
+	if query.Count() != 10 || len(query.GetEntitiesIds()) != 10 {
+		t.Errorf("query should return 10 entities, got %d", query.Count())
+	}
+
+	count := 0
+	for result := range query.Foreach(nil) {
+		if result.B == nil || result.A.x != result.B.x {
+			t.Errorf("unexpected result %v", result)
+		}
+		count++
+	}
+	if count != 10 {
+		t.Errorf("Foreach should iterate over 10 entities, got %d", count)
+	}
+
+	var mutex sync.Mutex
+	count = 0
+	query.Task(4, nil, func(result QueryResult2[testComponent1, testSparseComponent]) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if result.B == nil || result.A.x != result.B.x {
+			t.Errorf("unexpected result %v", result)
+		}
+		count++
+	})
+	if count != 10 {
+		t.Errorf("Task should iterate over 10 entities, got %d", count)
+	}
+
+	count = 0
+	for results := range query.ForeachChannel(3, nil) {
+		for result := range results {
+			if result.B == nil || result.A.x != result.B.x {
+				t.Errorf("unexpected result %v", result)
+			}
+			count++
+		}
+	}
+	if count != 10 {
+		t.Errorf("ForeachChannel should iterate over 10 entities, got %d", count)
+	}
+
+	optional := CreateQuery2[testComponent1, testSparseComponent](world, QueryConfiguration{OptionalComponents: []OptionalComponent{OptionalComponent(sparseId)}})
+	count = 0
+	for result := range optional.Foreach(nil) {
+		if (result.A.x%2 == 0) != (result.B != nil) {
+			t.Errorf("the optional sparse component is not consistent for the entity %d", result.EntityId)
+		}
+		count++
+	}
+	if count != 20 {
+		t.Errorf("Foreach should iterate over 20 entities, got %d", count)
+	}
+}
@@ -1,7 +1,36 @@
 package volt
 
 import (
+	"encoding"
 	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"unsafe"
+)
+
+// AUTO_COMPONENT_ID is returned by GetComponentId for components whose id is
+// allocated by the World at registration, instead of a hand-picked constant.
+//
+// The real identifier is then obtained with ComponentIdOf.
+const AUTO_COMPONENT_ID ComponentId = math.MaxUint16
+
+// StorageStrategy defines where the components of a type are stored.
+type StorageStrategy uint8
+
+const (
+	// TABLE_STORAGE stores the components in the columns of the archetypes.
+	// It is the default strategy, and the fastest to iterate.
+	TABLE_STORAGE StorageStrategy = iota
+	// SPARSE_STORAGE stores the components in a sparse set keyed by EntityId.
+	// Adding or removing them does not move the entity across archetypes,
+	// which suits the components frequently toggled, at the cost of a lookup per entity in queries.
+	SPARSE_STORAGE
+	// SHARED_STORAGE stores one value per group of entities, e.g. a material used by thousands of entities.
+	// The value is set with SetSharedComponent, which moves the entity to the group of an equal value:
+	// the groups are compared with EqualFn, or as map keys for the comparable types without interface field.
+	SHARED_STORAGE
 )
 
 // ComponentConfigInterface is the interface
@@ -9,37 +38,204 @@ import (
 type ComponentConfigInterface interface {
 	builderFn(component any, configuration any)
 	getComponentId() ComponentId
-	setComponent(component any)
+	getType() reflect.Type
+	getInfo() ComponentInfo
+	getName() string
+	getStorageStrategy() StorageStrategy
+	isReplicated() bool
+	setComponent(component any, componentId ComponentId)
 	addComponent(world *World, entityId EntityId, configuration any) error
+	addComponentValue(world *World, entityId EntityId, component any, clone bool) error
+	remapComponent(world *World, entityId EntityId, entitiesIds map[EntityId]EntityId) error
+	equalComponents(a any, b any) bool
+	appendBinary(world *World, entityId EntityId, buf []byte) ([]byte, error)
+	setBinary(world *World, entityId EntityId, data []byte) error
 }
 
 // Configuration for a component T.
 //
 // BuilderFn defines the function called to set a new component.
+// Name identifies the component in the World, it defaults to the name of the Go type.
+// Storage defines the StorageStrategy of the component, it defaults to TABLE_STORAGE.
+// Replicated marks the component to be sent over the network, see the package replication.
+// CloneFn returns a deep copy of a component, for World.Clone; it is required for the components
+// holding pointers, maps or slices that must not be shared between the clones.
+// RemapFn rewrites the EntityId held by a component, once merged into another World with their new ids, see World.Merge.
+// EqualFn compares two components for Diff, which uses reflect.DeepEqual by default.
 type ComponentConfig[T ComponentInterface] struct {
-	id        ComponentId
-	BuilderFn ComponentBuilder
-	component T
+	id         ComponentId
+	BuilderFn  ComponentBuilder
+	Name       string
+	Storage    StorageStrategy
+	Replicated bool
+	CloneFn    func(component T) T
+	RemapFn    func(component *T, entitiesIds map[EntityId]EntityId)
+	EqualFn    func(a T, b T) bool
+	component  T
+	info       ComponentInfo
+	// layout are the bytes of the fields of T, encoded by appendBinary without the padding in between.
+	layout []memoryRange
 }
 
 func (componentConfig *ComponentConfig[T]) getComponentId() ComponentId {
 	return componentConfig.id
 }
 
-func (componentConfig *ComponentConfig[T]) setComponent(component any) {
+func (componentConfig *ComponentConfig[T]) getType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (componentConfig *ComponentConfig[T]) getInfo() ComponentInfo {
+	return componentConfig.info
+}
+
+func (componentConfig *ComponentConfig[T]) getName() string {
+	return componentConfig.Name
+}
+
+func (componentConfig *ComponentConfig[T]) getStorageStrategy() StorageStrategy {
+	return componentConfig.Storage
+}
+
+func (componentConfig *ComponentConfig[T]) isReplicated() bool {
+	return componentConfig.Replicated
+}
+
+func (componentConfig *ComponentConfig[T]) setComponent(component any, componentId ComponentId) {
 	componentConfig.component = component.(T)
-	componentConfig.id = component.(T).GetComponentId()
+	componentConfig.id = componentId
+	componentConfig.info = newComponentInfo(componentId, componentConfig.Name, reflect.TypeFor[T]())
+	componentConfig.layout = memoryLayout(reflect.TypeFor[T](), 0, nil)
 }
 
 func (componentConfig *ComponentConfig[T]) addComponent(world *World, entityId EntityId, configuration any) error {
+	if componentConfig.Storage == SHARED_STORAGE {
+		return fmt.Errorf("the component %s is shared, it is set with SetSharedComponent", world.componentName(componentConfig.id))
+	}
+
 	var t T
 	componentConfig.builderFn(&t, configuration)
+	if err := checkIndexes(world, entityId, componentConfig.id, t); err != nil {
+		return err
+	}
 
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 	archetype := world.getNextArchetype(entityRecord, componentConfig.id)
-	err := addComponentsToArchetype1[T](world, entityRecord, archetype, t)
+	if err := addComponentsToArchetype1[T](world, entityRecord, archetype, t); err != nil {
+		return err
+	}
+	world.updateIndexes(entityId, componentConfig.id)
+
+	return nil
+}
+
+// addComponentValue adds to the entity the component pointed by component, a *T, copied with CloneFn if clone is true.
+// A shared component is set with SetSharedComponent.
+func (componentConfig *ComponentConfig[T]) addComponentValue(world *World, entityId EntityId, component any, clone bool) error {
+	t := *component.(*T)
+	if clone && componentConfig.CloneFn != nil {
+		t = componentConfig.CloneFn(t)
+	}
+
+	if componentConfig.Storage == SHARED_STORAGE {
+		return SetSharedComponent(world, entityId, t)
+	}
+
+	return AddComponent(world, entityId, t)
+}
+
+// remapComponent calls RemapFn, if set, with the component T of the entity.
+// It returns an error if the value remapped of a shared component cannot be set, see SetSharedComponent.
+func (componentConfig *ComponentConfig[T]) remapComponent(world *World, entityId EntityId, entitiesIds map[EntityId]EntityId) error {
+	if componentConfig.RemapFn == nil {
+		return nil
+	}
+
+	component := GetComponent[T](world, entityId)
+	if component == nil {
+		return nil
+	}
+
+	// The value of a group is not modified in place: the entity moves to the group of the value remapped.
+	if componentConfig.Storage == SHARED_STORAGE {
+		t := *component
+		componentConfig.RemapFn(&t, entitiesIds)
+		return setSharedComponent(world, entityId, componentConfig.id, t)
+	}
+
+	componentConfig.RemapFn(component, entitiesIds)
+	world.updateIndexes(entityId, componentConfig.id)
+
+	return nil
+}
+
+// equalComponents compares the components a and b, *T returned by World.GetComponent, with EqualFn if set.
+func (componentConfig *ComponentConfig[T]) equalComponents(a any, b any) bool {
+	componentA, okA := a.(*T)
+	componentB, okB := b.(*T)
+	if !okA || !okB {
+		return false
+	}
+
+	if componentConfig.EqualFn != nil {
+		return componentConfig.EqualFn(*componentA, *componentB)
+	}
+
+	return reflect.DeepEqual(*componentA, *componentB)
+}
+
+// appendBinary appends the encoding of the component T owned by the entity to buf.
+//
+// The component is encoded with its AppendBinary or MarshalBinary method if it has one,
+// or else copied from memory in the native byte order, field by field: the padding bytes between the fields,
+// whose value is undefined, are skipped so that two equal components have the same encoding.
+func (componentConfig *ComponentConfig[T]) appendBinary(world *World, entityId EntityId, buf []byte) ([]byte, error) {
+	component := GetComponent[T](world, entityId)
+	if component == nil {
+		return buf, fmt.Errorf("the entity %d doesn't own the component %s", entityId, world.componentName(componentConfig.id))
+	}
+
+	switch marshaler := any(component).(type) {
+	case encoding.BinaryAppender:
+		return marshaler.AppendBinary(buf)
+	case encoding.BinaryMarshaler:
+		data, err := marshaler.MarshalBinary()
+		return append(buf, data...), err
+	}
+
+	data := unsafe.Slice((*byte)(unsafe.Pointer(component)), unsafe.Sizeof(*component))
+	for _, r := range componentConfig.layout {
+		buf = append(buf, data[r.offset:r.offset+r.size]...)
+	}
 
-	return err
+	return buf, nil
+}
+
+// setBinary decodes data into the component T of the entity, added if the entity does not own it yet.
+func (componentConfig *ComponentConfig[T]) setBinary(world *World, entityId EntityId, data []byte) error {
+	var t T
+	if unmarshaler, ok := any(&t).(encoding.BinaryUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("the component %s cannot be decoded: %w", world.componentName(componentConfig.id), err)
+		}
+	} else {
+		if size := layoutSize(componentConfig.layout); uintptr(len(data)) != size {
+			return fmt.Errorf("the component %s has a size of %d bytes, got %d", world.componentName(componentConfig.id), size, len(data))
+		}
+		memory := unsafe.Slice((*byte)(unsafe.Pointer(&t)), unsafe.Sizeof(t))
+		for _, r := range componentConfig.layout {
+			data = data[copy(memory[r.offset:r.offset+r.size], data):]
+		}
+	}
+
+	if world.HasComponents(entityId, componentConfig.id) {
+		return SetComponent(world, entityId, t)
+	}
+	if componentConfig.Storage == SHARED_STORAGE {
+		return SetSharedComponent(world, entityId, t)
+	}
+
+	return AddComponent(world, entityId, t)
 }
 
 func (componentConfig *ComponentConfig[T]) builderFn(component any, configuration any) {
@@ -58,7 +254,16 @@ type ComponentBuilder func(component any, configuration any)
 // RegisterComponent adds a component T to the registry of the given World.
 //
 // Once the component is registered, it can be added to an entity.
-func RegisterComponent[T ComponentInterface](world *World, config ComponentConfigInterface) {
+// If T.GetComponentId returns AUTO_COMPONENT_ID, a free ComponentId is allocated by the World.
+//
+// It returns an error if:
+//   - the ComponentId is out of the range [COMPONENTS_INDICES;TAGS_INDICES)
+//   - the ComponentId is already used by another component type
+//   - the name is already used by another component
+//   - the StorageStrategy differs from a previous registration
+//   - the component is replicated, but cannot be encoded
+//   - no ComponentId is left to allocate
+func RegisterComponent[T ComponentInterface](world *World, config ComponentConfigInterface) error {
 	var t T
 	if world.componentsRegistry == nil {
 		world.componentsRegistry = make(ComponentsRegister, TAGS_INDICES)
@@ -67,15 +272,351 @@ func RegisterComponent[T ComponentInterface](world *World, config ComponentConfi
 		}
 	}
 
-	config.setComponent(t)
-	world.componentsRegistry[t.GetComponentId()] = config
-	getStorage[T](world)
+	componentType := reflect.TypeFor[T]()
+	componentId, err := world.resolveComponentId(componentType, t.GetComponentId())
+	if err != nil {
+		return err
+	}
+
+	if config.isReplicated() && !isBinaryEncodable(componentType) {
+		return fmt.Errorf("the component %v cannot be replicated: it holds pointers, and does not implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler", componentType)
+	}
+
+	name := newComponentInfo(componentId, config.getName(), componentType).Name
+	if registeredId, ok := world.componentsByName[name]; ok && registeredId != componentId {
+		return fmt.Errorf("the name %q of %v is already used by the component %d", name, componentType, registeredId)
+	}
+
+	previous := world.componentsRegistry[componentId]
+	if previous != nil && previous.getStorageStrategy() != config.getStorageStrategy() {
+		return fmt.Errorf("the storage strategy of the component %s cannot be changed once registered", world.componentName(componentId))
+	}
+
+	// The configuration is only set once all the checks passed, as it may be shared with another World.
+	config.setComponent(t, componentId)
+	if previous != nil {
+		delete(world.componentsByName, previous.getInfo().Name)
+	}
+	world.componentsRegistry[componentId] = config
+	world.registeredTypes[componentType] = componentId
+	world.componentsByName[name] = componentId
+
+	s := getStorage[T](world)
+	if config.getStorageStrategy() == SPARSE_STORAGE && s.sparse == nil {
+		s.sparse = &sparseSet[T]{}
+		world.sparseComponentsIds = append(world.sparseComponentsIds, componentId)
+	}
+	if config.getStorageStrategy() == SHARED_STORAGE && s.shared == nil {
+		var equalFn func(a T, b T) bool
+		if config, ok := config.(*ComponentConfig[T]); ok {
+			equalFn = config.EqualFn
+		}
+		s.shared = newSharedSet(equalFn)
+	}
+
+	return nil
+}
+
+// resolveComponentId returns the ComponentId to register componentType with,
+// allocating a free one when the component asks for AUTO_COMPONENT_ID.
+func (world *World) resolveComponentId(componentType reflect.Type, componentId ComponentId) (ComponentId, error) {
+	if componentId == AUTO_COMPONENT_ID {
+		if registeredId, ok := world.registeredTypes[componentType]; ok {
+			return registeredId, nil
+		}
+
+		return world.allocateComponentId(componentType.String())
+	}
+
+	if componentId >= TAGS_INDICES {
+		return 0, fmt.Errorf("the componentId %d of %v overflows the range allowed [%d;%d)", componentId, componentType, COMPONENTS_INDICES, TAGS_INDICES)
+	}
+
+	if registered := world.componentsRegistry[componentId]; registered != nil && registered.getType() != componentType {
+		return 0, fmt.Errorf("the componentId %d of %v is already registered by %v", componentId, componentType, registered.getType())
+	}
+
+	return componentId, nil
+}
+
+// allocateComponentId returns the highest free ComponentId of the World.
+//
+// Automatic ids are allocated downward from GLOBAL_COMPONENTS_INDICES-1, so that they stay
+// clear of hand-picked ids, which usually start from 0 with iota, and of the ids of GlobalComponentId.
+func (world *World) allocateComponentId(name string) (ComponentId, error) {
+	for i := GLOBAL_COMPONENTS_INDICES - 1; i >= COMPONENTS_INDICES; i-- {
+		if world.componentsRegistry[i] == nil {
+			return ComponentId(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("no ComponentId left to allocate for %s", name)
+}
+
+// ComponentIdOf returns the ComponentId of the component T in the World.
+//
+// It is the id returned by T.GetComponentId, or the id allocated at
+// registration if T uses AUTO_COMPONENT_ID. In that case, it returns
+// AUTO_COMPONENT_ID if T is not registered in the World.
+func ComponentIdOf[T ComponentInterface](world *World) ComponentId {
+	var t T
+	componentId := t.GetComponentId()
+	if componentId != AUTO_COMPONENT_ID {
+		return componentId
+	}
+
+	if registeredId, ok := world.registeredTypes[reflect.TypeFor[T]()]; ok {
+		return registeredId
+	}
+
+	return AUTO_COMPONENT_ID
+}
+
+// ReplicatedComponentsIds returns the ComponentId of the components registered as Replicated, in ascending order.
+func (world *World) ReplicatedComponentsIds() []ComponentId {
+	var componentsIds []ComponentId
+	for componentId, config := range world.componentsRegistry {
+		if config != nil && config.isReplicated() {
+			componentsIds = append(componentsIds, ComponentId(componentId))
+		}
+	}
+
+	return componentsIds
+}
+
+// isBinaryEncodable reports whether the components of componentType can be encoded by appendBinary.
+//
+// Their memory is copied as is, unless they implement their own encoding:
+// it is only meaningful on another process if it holds no pointer.
+func isBinaryEncodable(componentType reflect.Type) bool {
+	pointerType := reflect.PointerTo(componentType)
+	if pointerType.Implements(reflect.TypeFor[encoding.BinaryUnmarshaler]()) &&
+		(pointerType.Implements(reflect.TypeFor[encoding.BinaryMarshaler]()) || pointerType.Implements(reflect.TypeFor[encoding.BinaryAppender]())) {
+		return true
+	}
+
+	return !holdsPointers(componentType)
+}
+
+func holdsPointers(componentType reflect.Type) bool {
+	switch componentType.Kind() {
+	case reflect.Array:
+		return componentType.Len() > 0 && holdsPointers(componentType.Elem())
+	case reflect.Struct:
+		for i := range componentType.NumField() {
+			if holdsPointers(componentType.Field(i).Type) {
+				return true
+			}
+		}
+
+		return false
+	case reflect.Pointer, reflect.UnsafePointer, reflect.String, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+// memoryRange is the range of bytes [offset;offset+size) of a component in memory.
+type memoryRange struct {
+	offset uintptr
+	size   uintptr
+}
+
+// memoryLayout appends to ranges the bytes of the fields of componentType, from offset,
+// merged when contiguous: the padding bytes in between are left out.
+func memoryLayout(componentType reflect.Type, offset uintptr, ranges []memoryRange) []memoryRange {
+	switch componentType.Kind() {
+	case reflect.Struct:
+		for i := range componentType.NumField() {
+			field := componentType.Field(i)
+			ranges = memoryLayout(field.Type, offset+field.Offset, ranges)
+		}
+
+		return ranges
+	case reflect.Array:
+		elem := componentType.Elem()
+		for i := range componentType.Len() {
+			ranges = memoryLayout(elem, offset+uintptr(i)*elem.Size(), ranges)
+		}
+
+		return ranges
+	}
+
+	if componentType.Size() == 0 {
+		return ranges
+	}
+	if last := len(ranges) - 1; last >= 0 && ranges[last].offset+ranges[last].size == offset {
+		ranges[last].size += componentType.Size()
+		return ranges
+	}
+
+	return append(ranges, memoryRange{offset: offset, size: componentType.Size()})
+}
+
+// layoutSize returns the number of bytes of the ranges.
+func layoutSize(ranges []memoryRange) uintptr {
+	var size uintptr
+	for _, r := range ranges {
+		size += r.size
+	}
+
+	return size
+}
+
+// componentIdAllocator assigns a ComponentId to each Go type, shared by all the Worlds.
+type componentIdAllocator struct {
+	mu   sync.Mutex
+	ids  sync.Map
+	next int
+}
+
+// GLOBAL_COMPONENTS_INDICES is the first ComponentId of the range [GLOBAL_COMPONENTS_INDICES;TAGS_INDICES)
+// allocated by GlobalComponentId. The ids allocated per World for AUTO_COMPONENT_ID are below it,
+// so that a global id never collides with the id allocated by a World.
+const GLOBAL_COMPONENTS_INDICES = 1536
+
+var globalComponentIds = &componentIdAllocator{next: TAGS_INDICES}
+
+func (allocator *componentIdAllocator) get(componentType reflect.Type) ComponentId {
+	if componentId, ok := allocator.ids.Load(componentType); ok {
+		return componentId.(ComponentId)
+	}
+
+	allocator.mu.Lock()
+	defer allocator.mu.Unlock()
+
+	if componentId, ok := allocator.ids.Load(componentType); ok {
+		return componentId.(ComponentId)
+	}
+	if allocator.next <= GLOBAL_COMPONENTS_INDICES {
+		panic(fmt.Sprintf("no ComponentId left to allocate for %v", componentType))
+	}
+
+	allocator.next--
+	componentId := ComponentId(allocator.next)
+	allocator.ids.Store(componentType, componentId)
+
+	return componentId
+}
+
+// GlobalComponentId returns a ComponentId allocated once for the Go type T,
+// and shared by every World of the program.
+//
+// It is meant to implement GetComponentId without choosing a constant:
+//
+//	func (t transformComponent) GetComponentId() volt.ComponentId {
+//		return volt.GlobalComponentId[transformComponent]()
+//	}
+//
+// The ids are allocated downward from TAGS_INDICES-1, down to GLOBAL_COMPONENTS_INDICES:
+// they never collide with the ids allocated per World for AUTO_COMPONENT_ID.
+// A collision with a hand-picked id is reported by RegisterComponent.
+func GlobalComponentId[T any]() ComponentId {
+	return globalComponentIds.get(reflect.TypeFor[T]())
 }
 
 func (world *World) getConfigByComponentId(componentId ComponentId) (ComponentConfigInterface, error) {
-	if world.componentsRegistry[componentId] == nil {
+	if int(componentId) >= len(world.componentsRegistry) || world.componentsRegistry[componentId] == nil {
 		return nil, fmt.Errorf("componentConfiguration not found for %d", componentId)
 	}
 
 	return world.componentsRegistry[componentId], nil
 }
+
+// ComponentField describes a field of a component structure.
+type ComponentField struct {
+	Name   string
+	Type   reflect.Type
+	Offset uintptr
+	Size   uintptr
+}
+
+// ComponentInfo describes a registered component: its name, Go type, size in memory and fields layout.
+//
+// Fields is empty if the component is not a structure.
+type ComponentInfo struct {
+	Id     ComponentId
+	Name   string
+	Type   reflect.Type
+	Size   uintptr
+	Fields []ComponentField
+}
+
+// newComponentInfo returns the metadata of componentType. The name defaults to the name of the type
+// qualified by its package path, so that the types of the same name from two packages do not collide.
+func newComponentInfo(componentId ComponentId, name string, componentType reflect.Type) ComponentInfo {
+	if name == "" && componentType.Name() != "" && componentType.PkgPath() != "" {
+		name = componentType.PkgPath() + "." + componentType.Name()
+	}
+	if name == "" {
+		name = componentType.String()
+	}
+
+	info := ComponentInfo{
+		Id:   componentId,
+		Name: name,
+		Type: componentType,
+		Size: componentType.Size(),
+	}
+
+	if componentType.Kind() == reflect.Struct {
+		info.Fields = make([]ComponentField, componentType.NumField())
+		for i := range componentType.NumField() {
+			field := componentType.Field(i)
+			info.Fields[i] = ComponentField{
+				Name:   field.Name,
+				Type:   field.Type,
+				Offset: field.Offset,
+				Size:   field.Type.Size(),
+			}
+		}
+	}
+
+	return info
+}
+
+// ComponentInfo returns the metadata of the component registered with componentId.
+//
+// It returns an error if no component is registered with componentId.
+func (world *World) ComponentInfo(componentId ComponentId) (ComponentInfo, error) {
+	config, err := world.getConfigByComponentId(componentId)
+	if err != nil {
+		return ComponentInfo{}, err
+	}
+
+	return config.getInfo(), nil
+}
+
+// ComponentIdByName returns the ComponentId of the component registered with name.
+//
+// It returns an error if no component is registered with this name.
+func (world *World) ComponentIdByName(name string) (ComponentId, error) {
+	componentId, ok := world.componentsByName[name]
+	if !ok {
+		return 0, fmt.Errorf("no component registered with the name %q", name)
+	}
+
+	return componentId, nil
+}
+
+// componentName returns a readable reference to componentId for the error messages, e.g. "transform(0)".
+//
+// It falls back to the bare id for the components not registered.
+func (world *World) componentName(componentId ComponentId) string {
+	if int(componentId) < len(world.componentsRegistry) && world.componentsRegistry[componentId] != nil {
+		return fmt.Sprintf("%s(%d)", world.componentsRegistry[componentId].getInfo().Name, componentId)
+	}
+
+	return strconv.Itoa(int(componentId))
+}
+
+// componentsNames returns the readable references to componentsIds, see componentName.
+func (world *World) componentsNames(componentsIds ...ComponentId) []string {
+	names := make([]string, len(componentsIds))
+	for i, componentId := range componentsIds {
+		names[i] = world.componentName(componentId)
+	}
+
+	return names
+}
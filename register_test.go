@@ -1,6 +1,9 @@
 package volt
 
 import (
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -57,10 +60,265 @@ func TestComponentConfig_setComponent(t *testing.T) {
 
 }
 
+type testDuplicateComponent struct{}
+
+func (t testDuplicateComponent) GetComponentId() ComponentId {
+	return testComponent1Id
+}
+
+type testAutoComponent1 struct {
+	value int
+}
+
+func (t testAutoComponent1) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+type testAutoComponent2 struct {
+	value int
+}
+
+func (t testAutoComponent2) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+type testGlobalComponent struct {
+	value int
+}
+
+func (t testGlobalComponent) GetComponentId() ComponentId {
+	return GlobalComponentId[testGlobalComponent]()
+}
+
+// testLateGlobalComponent asks for its global ComponentId only once a World has allocated its own ids.
+type testLateGlobalComponent struct{}
+
+func (t testLateGlobalComponent) GetComponentId() ComponentId {
+	return GlobalComponentId[testLateGlobalComponent]()
+}
+
+type testOverflowComponent struct{}
+
+func (t testOverflowComponent) GetComponentId() ComponentId {
+	return TAGS_INDICES
+}
+
 func TestRegisterComponent(t *testing.T) {
+	world := CreateWorld(16)
+
+	err := RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	// Registering the same type again only replaces its configuration.
+	err = RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+
+	err = RegisterComponent[testDuplicateComponent](world, &ComponentConfig[testDuplicateComponent]{})
+	if err == nil {
+		t.Errorf("RegisterComponent should reject a ComponentId already used by another type")
+	}
+	if world.componentsRegistry[testComponent1Id].getType() != reflect.TypeFor[testComponent1]() {
+		t.Errorf("the duplicated registration should not overwrite the registry")
+	}
 
+	err = RegisterComponent[testOverflowComponent](world, &ComponentConfig[testOverflowComponent]{})
+	if err == nil {
+		t.Errorf("RegisterComponent should reject a ComponentId out of range")
+	}
+}
+
+type testPointerComponent struct {
+	name string
+}
+
+func (t testPointerComponent) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+func TestRegisterComponent_Replicated(t *testing.T) {
+	world := CreateWorld(16)
+
+	err := RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{Replicated: true})
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	err = RegisterComponent[testComponent2](world, &ComponentConfig[testComponent2]{})
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	err = RegisterComponent[testPointerComponent](world, &ComponentConfig[testPointerComponent]{Replicated: true})
+	if err == nil {
+		t.Errorf("RegisterComponent should reject a component replicated holding pointers, without binary encoding")
+	}
+
+	if componentsIds := world.ReplicatedComponentsIds(); !slices.Equal(componentsIds, []ComponentId{testComponent1Id}) {
+		t.Errorf("the components replicated should be %v, got %v", []ComponentId{testComponent1Id}, componentsIds)
+	}
+}
+
+func TestRegisterComponent_AutoComponentId(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+
+	if ComponentIdOf[testAutoComponent1](world) != AUTO_COMPONENT_ID {
+		t.Errorf("ComponentIdOf should return AUTO_COMPONENT_ID for a type not registered")
+	}
+
+	err := RegisterComponent[testAutoComponent1](world, &ComponentConfig[testAutoComponent1]{})
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	err = RegisterComponent[testAutoComponent2](world, &ComponentConfig[testAutoComponent2]{})
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+
+	id1 := ComponentIdOf[testAutoComponent1](world)
+	id2 := ComponentIdOf[testAutoComponent2](world)
+	if id1 >= TAGS_INDICES || id2 >= TAGS_INDICES || id1 == id2 || id1 == testComponent1Id || id2 == testComponent1Id {
+		t.Errorf("invalid automatic ComponentIds %d and %d", id1, id2)
+	}
+
+	entityId := world.CreateEntity()
+	err = AddComponents2(world, entityId, testAutoComponent1{value: 1}, testAutoComponent2{value: 2})
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if !world.HasComponents(entityId, id1, id2) {
+		t.Errorf("the entity %d should own the components %d and %d", entityId, id1, id2)
+	}
+	if component := GetComponent[testAutoComponent2](world, entityId); component == nil || component.value != 2 {
+		t.Errorf("GetComponent did not return the automatic component")
+	}
+
+	query := CreateQuery1[testAutoComponent1](world, QueryConfiguration{})
+	if query.Count() != 1 {
+		t.Errorf("query should return 1 entity, got %d", query.Count())
+	}
+
+	err = RemoveComponent[testAutoComponent1](world, entityId)
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if world.HasComponents(entityId, id1) {
+		t.Errorf("the component %d should be removed", id1)
+	}
+}
+
+func TestGlobalComponentId(t *testing.T) {
+	id := GlobalComponentId[testGlobalComponent]()
+	if id != GlobalComponentId[testGlobalComponent]() {
+		t.Errorf("GlobalComponentId should be stable for a given type")
+	}
+	if id >= TAGS_INDICES {
+		t.Errorf("GlobalComponentId returned %d, out of the components range", id)
+	}
+
+	world1 := CreateWorld(16)
+	world2 := CreateWorld(16)
+	RegisterComponent[testAutoComponent1](world1, &ComponentConfig[testAutoComponent1]{})
+	RegisterComponent[testGlobalComponent](world1, &ComponentConfig[testGlobalComponent]{})
+	RegisterComponent[testGlobalComponent](world2, &ComponentConfig[testGlobalComponent]{})
+
+	if ComponentIdOf[testGlobalComponent](world1) != id || ComponentIdOf[testGlobalComponent](world2) != id {
+		t.Errorf("the global ComponentId should be shared by all the worlds")
+	}
+	if ComponentIdOf[testAutoComponent1](world1) == id {
+		t.Errorf("the world allocation should not reuse a global ComponentId")
+	}
+
+	// A global ComponentId allocated after the ids of a World never collides with them.
+	world3 := CreateWorld(16)
+	if err := RegisterComponent[testAutoComponent2](world3, &ComponentConfig[testAutoComponent2]{}); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if err := RegisterComponent[testLateGlobalComponent](world3, &ComponentConfig[testLateGlobalComponent]{}); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if autoId := ComponentIdOf[testAutoComponent2](world3); autoId >= GLOBAL_COMPONENTS_INDICES {
+		t.Errorf("the world should allocate its ids below %d, got %d", GLOBAL_COMPONENTS_INDICES, autoId)
+	}
+	if lateId := GlobalComponentId[testLateGlobalComponent](); lateId < GLOBAL_COMPONENTS_INDICES || lateId >= TAGS_INDICES {
+		t.Errorf("the global ComponentId should be in [%d;%d), got %d", GLOBAL_COMPONENTS_INDICES, TAGS_INDICES, lateId)
+	}
 }
 
 func TestWorld_getConfigByComponentId(t *testing.T) {
 
 }
+
+func TestWorld_ComponentInfo(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testComponent2](world, &ComponentConfig[testComponent2]{Name: "transform"})
+
+	info, err := world.ComponentInfo(testComponent1Id)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if info.Id != testComponent1Id || info.Name != "github.com/akmonengine/volt.testComponent1" || info.Type != reflect.TypeFor[testComponent1]() {
+		t.Errorf("unexpected ComponentInfo %+v", info)
+	}
+	if info.Size != reflect.TypeFor[testComponent1]().Size() {
+		t.Errorf("unexpected size %d", info.Size)
+	}
+	if len(info.Fields) != 1 || info.Fields[0].Name != "testComponent" || info.Fields[0].Size != info.Size {
+		t.Errorf("unexpected fields %+v", info.Fields)
+	}
+
+	info, err = world.ComponentInfo(testComponent2Id)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if info.Name != "transform" {
+		t.Errorf("the name set in the configuration should be used, got %q", info.Name)
+	}
+
+	if _, err = world.ComponentInfo(testComponent3Id); err == nil {
+		t.Errorf("ComponentInfo should return an error for a component not registered")
+	}
+}
+
+func TestWorld_ComponentIdByName(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testComponent2](world, &ComponentConfig[testComponent2]{Name: "transform"})
+
+	componentId, err := world.ComponentIdByName("transform")
+	if err != nil || componentId != testComponent2Id {
+		t.Errorf("ComponentIdByName returned %d, %v", componentId, err)
+	}
+	componentId, err = world.ComponentIdByName("github.com/akmonengine/volt.testComponent1")
+	if err != nil || componentId != testComponent1Id {
+		t.Errorf("ComponentIdByName returned %d, %v", componentId, err)
+	}
+	if _, err = world.ComponentIdByName("unknown"); err == nil {
+		t.Errorf("ComponentIdByName should return an error for an unknown name")
+	}
+
+	config := &ComponentConfig[testComponent3]{Name: "transform"}
+	err = RegisterComponent[testComponent3](world, config)
+	if err == nil {
+		t.Errorf("RegisterComponent should reject a name already used")
+	}
+	if config.getInfo().Name != "" {
+		t.Errorf("the configuration rejected should not be modified, got %+v", config.getInfo())
+	}
+}
+
+func TestWorld_componentName(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+
+	entityId := world.CreateEntity()
+	err := RemoveComponent[testComponent1](world, entityId)
+	if err == nil || !strings.Contains(err.Error(), "testComponent1(0)") {
+		t.Errorf("the error should name the component, got %v", err)
+	}
+	if world.componentName(testComponent2Id) != "1" {
+		t.Errorf("a component not registered should be named by its id")
+	}
+}
@@ -0,0 +1,218 @@
+package replication
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/akmonengine/volt"
+)
+
+// Client applies the deltas of a Server to a World.
+//
+// The entities of the Server are created in the World with CreateEntity: their EntityId differ,
+// and are mapped by the Client. The World may hold other entities, e.g. predicted locally.
+// The components holding the EntityId of other entities are remapped with the RemapFn of their ComponentConfig,
+// once all the entities of a delta are created.
+type Client struct {
+	world    *volt.World
+	tick     uint64
+	entities map[volt.EntityId]volt.EntityId
+
+	// set are the components set by the delta being applied, to remap; spawned are the entities it created.
+	set     []componentRef
+	spawned []volt.EntityId
+
+	entitySpawnedFn   func(serverEntityId volt.EntityId, entityId volt.EntityId)
+	entityDespawnedFn func(serverEntityId volt.EntityId, entityId volt.EntityId)
+}
+
+// NewClient returns a Client applying the deltas to world.
+func NewClient(world *volt.World) *Client {
+	return &Client{
+		world:             world,
+		entities:          make(map[volt.EntityId]volt.EntityId),
+		entitySpawnedFn:   func(serverEntityId volt.EntityId, entityId volt.EntityId) {},
+		entityDespawnedFn: func(serverEntityId volt.EntityId, entityId volt.EntityId) {},
+	}
+}
+
+// componentRef refers to the component of an entity in the World of the Client.
+type componentRef struct {
+	entityId    volt.EntityId
+	componentId volt.ComponentId
+}
+
+// SetEntitySpawnedFn sets a callback for when an entity of the Server is created in the World.
+//
+// It is called for each entity created, once the whole delta is applied and its components remapped,
+// so that the callback sees the references to the other entities of the delta. It is not called if Apply fails.
+func (client *Client) SetEntitySpawnedFn(entitySpawnedFn func(serverEntityId volt.EntityId, entityId volt.EntityId)) {
+	client.entitySpawnedFn = entitySpawnedFn
+}
+
+// SetEntityDespawnedFn sets a callback for when an entity of the Server is removed from the World,
+// because it was removed on the Server or is not relevant anymore to the Observer.
+//
+// It is called beforehand, so that the callback still has access to the data.
+func (client *Client) SetEntityDespawnedFn(entityDespawnedFn func(serverEntityId volt.EntityId, entityId volt.EntityId)) {
+	client.entityDespawnedFn = entityDespawnedFn
+}
+
+// Tick returns the tick of the last delta applied, or NO_TICK.
+//
+// It is the tick to acknowledge to the Server, from which the next delta is computed.
+func (client *Client) Tick() uint64 {
+	return client.tick
+}
+
+// EntityId returns the EntityId in the World of the Client, of the entity serverEntityId of the Server.
+//
+// The boolean is false if the entity is not replicated.
+func (client *Client) EntityId(serverEntityId volt.EntityId) (volt.EntityId, bool) {
+	entityId, ok := client.entities[serverEntityId]
+
+	return entityId, ok
+}
+
+// Count returns the number of entities replicated.
+func (client *Client) Count() int {
+	return len(client.entities)
+}
+
+// Apply applies the delta data, encoded by Server.Delta, to the World.
+//
+// A full delta replaces all the entities replicated so far: those it holds are updated, and the others despawned.
+// It returns an error if:
+//   - data is malformed
+//   - the delta does not start at the tick of the Client
+//   - a component of the delta is not registered in the World
+//   - a change does not match the entities replicated, or a component cannot be decoded;
+//     the World may then be partially updated, and a full delta should be requested
+func (client *Client) Apply(data []byte) error {
+	d, err := decodeDelta(data)
+	if err != nil {
+		return err
+	}
+	if !d.full && d.from != client.tick {
+		return fmt.Errorf("the delta starts at the tick %d, but the client is at the tick %d", d.from, client.tick)
+	}
+
+	componentsIds := make(map[volt.ComponentId]volt.ComponentId, len(d.componentsNames))
+	for serverComponentId, name := range d.componentsNames {
+		componentsIds[serverComponentId], err = client.world.ComponentIdByName(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.full {
+		client.reset(&d, componentsIds)
+	}
+
+	for _, serverEntityId := range d.removed {
+		if _, ok := client.entities[serverEntityId]; !ok {
+			return fmt.Errorf("the entity %d of the server is not replicated", serverEntityId)
+		}
+
+		client.despawn(serverEntityId)
+	}
+
+	client.set = client.set[:0]
+	client.spawned = client.spawned[:0]
+	for _, change := range d.changes {
+		if err = client.applyChange(change, componentsIds); err != nil {
+			return err
+		}
+	}
+
+	// The components may refer to entities created after them in the delta.
+	for _, component := range client.set {
+		if err = client.world.RemapComponent(component.entityId, component.componentId, client.entities); err != nil {
+			return err
+		}
+	}
+	for _, serverEntityId := range client.spawned {
+		client.entitySpawnedFn(serverEntityId, client.entities[serverEntityId])
+	}
+
+	client.tick = d.to
+
+	return nil
+}
+
+// reset prepares the Client to a full delta: the entities replicated are despawned if the delta does not hold them,
+// or else updated, with their components absent from the delta removed.
+func (client *Client) reset(d *delta, componentsIds map[volt.ComponentId]volt.ComponentId) {
+	replicatedIds := client.world.ReplicatedComponentsIds()
+	kept := make(map[volt.EntityId]bool, len(d.changes))
+	for i, change := range d.changes {
+		entityId, ok := client.entities[change.id]
+		if !ok {
+			continue
+		}
+		kept[change.id] = true
+		d.changes[i].created = false
+
+		for _, componentId := range replicatedIds {
+			if client.world.HasComponents(entityId, componentId) && !slices.ContainsFunc(change.set, func(component componentValue) bool {
+				return componentsIds[component.id] == componentId
+			}) {
+				client.world.RemoveComponent(entityId, componentId)
+			}
+		}
+	}
+
+	for serverEntityId := range client.entities {
+		if !kept[serverEntityId] {
+			client.despawn(serverEntityId)
+		}
+	}
+}
+
+func (client *Client) despawn(serverEntityId volt.EntityId) {
+	entityId := client.entities[serverEntityId]
+	client.entityDespawnedFn(serverEntityId, entityId)
+
+	client.world.RemoveEntity(entityId)
+	delete(client.entities, serverEntityId)
+}
+
+func (client *Client) applyChange(change entityChange, componentsIds map[volt.ComponentId]volt.ComponentId) error {
+	entityId, ok := client.entities[change.id]
+	switch {
+	case change.created && ok:
+		return fmt.Errorf("the entity %d of the server is already replicated", change.id)
+	case change.created:
+		entityId = client.world.CreateEntity()
+		client.entities[change.id] = entityId
+	case !ok:
+		return fmt.Errorf("the entity %d of the server is not replicated", change.id)
+	}
+
+	for _, serverComponentId := range change.removed {
+		componentId, ok := componentsIds[serverComponentId]
+		if !ok {
+			return fmt.Errorf("the component %d of the server is not named in the delta", serverComponentId)
+		}
+		if err := client.world.RemoveComponent(entityId, componentId); err != nil {
+			return err
+		}
+	}
+
+	for _, component := range change.set {
+		componentId, ok := componentsIds[component.id]
+		if !ok {
+			return fmt.Errorf("the component %d of the server is not named in the delta", component.id)
+		}
+		if err := client.world.SetComponentBinary(entityId, componentId, component.data); err != nil {
+			return err
+		}
+		client.set = append(client.set, componentRef{entityId: entityId, componentId: componentId})
+	}
+
+	if change.created {
+		client.spawned = append(client.spawned, change.id)
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+package replication
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/akmonengine/volt"
+)
+
+const deltaVersion byte = 1
+
+const (
+	// fullDelta flags a delta computed from an empty State.
+	fullDelta byte = 1 << iota
+)
+
+const (
+	// createdEntity flags an entity absent from the State the delta starts at.
+	createdEntity byte = 1 << iota
+)
+
+// delta lists the changes between two States.
+type delta struct {
+	full     bool
+	from, to uint64
+
+	// componentsNames identifies the components of the delta across the Worlds,
+	// as their ComponentId may differ.
+	componentsNames map[volt.ComponentId]string
+	removed         []volt.EntityId
+	changes         []entityChange
+}
+
+// entityChange lists the changes of an entity: its components removed, and its components added or modified.
+type entityChange struct {
+	id      volt.EntityId
+	created bool
+	removed []volt.ComponentId
+	set     []componentValue
+}
+
+type componentValue struct {
+	id   volt.ComponentId
+	data []byte
+}
+
+// encodeDelta returns the binary delta between the States of history at the ticks from and to.
+func encodeDelta(world *volt.World, history []*State, from uint64, to uint64) ([]byte, error) {
+	if from > to {
+		return nil, fmt.Errorf("the tick %d is greater than the tick %d", from, to)
+	}
+
+	toState := findState(history, to)
+	if toState == nil {
+		return nil, fmt.Errorf("the tick %d is not in the history", to)
+	}
+
+	d, err := diff(world, findState(history, from), toState)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.appendBinary(nil), nil
+}
+
+// diff returns the delta from the State from to the State to. A nil from is an empty State.
+func diff(world *volt.World, from *State, to *State) (delta, error) {
+	d := delta{to: to.Tick, componentsNames: make(map[volt.ComponentId]string)}
+	if from == nil {
+		from = &State{}
+		d.full = true
+	}
+	d.from = from.Tick
+
+	i, j := 0, 0
+	for i < len(from.entities) || j < len(to.entities) {
+		switch {
+		case j == len(to.entities) || (i < len(from.entities) && from.entities[i].id < to.entities[j].id):
+			d.removed = append(d.removed, from.entities[i].id)
+			i++
+		case i == len(from.entities) || to.entities[j].id < from.entities[i].id:
+			d.changes = append(d.changes, diffEntity(to.entities[j].id, nil, nil, to, to.entities[j].components))
+			d.changes[len(d.changes)-1].created = true
+			j++
+		default:
+			change := diffEntity(to.entities[j].id, from, from.entities[i].components, to, to.entities[j].components)
+			if len(change.removed) > 0 || len(change.set) > 0 {
+				d.changes = append(d.changes, change)
+			}
+			i++
+			j++
+		}
+	}
+
+	for _, change := range d.changes {
+		for _, componentId := range change.removed {
+			if err := d.addComponentName(world, componentId); err != nil {
+				return d, err
+			}
+		}
+		for _, component := range change.set {
+			if err := d.addComponentName(world, component.id); err != nil {
+				return d, err
+			}
+		}
+	}
+
+	return d, nil
+}
+
+// diffEntity returns the changes of the components of an entity, both lists sorted by ComponentId.
+func diffEntity(entityId volt.EntityId, from *State, fromComponents []componentState, to *State, toComponents []componentState) entityChange {
+	change := entityChange{id: entityId}
+
+	i, j := 0, 0
+	for i < len(fromComponents) || j < len(toComponents) {
+		switch {
+		case j == len(toComponents) || (i < len(fromComponents) && fromComponents[i].id < toComponents[j].id):
+			change.removed = append(change.removed, fromComponents[i].id)
+			i++
+		case i == len(fromComponents) || toComponents[j].id < fromComponents[i].id:
+			change.set = append(change.set, componentValue{id: toComponents[j].id, data: to.componentData(toComponents[j])})
+			j++
+		default:
+			data := to.componentData(toComponents[j])
+			if !bytes.Equal(from.componentData(fromComponents[i]), data) {
+				change.set = append(change.set, componentValue{id: toComponents[j].id, data: data})
+			}
+			i++
+			j++
+		}
+	}
+
+	return change
+}
+
+func (d *delta) addComponentName(world *volt.World, componentId volt.ComponentId) error {
+	if _, ok := d.componentsNames[componentId]; ok {
+		return nil
+	}
+
+	info, err := world.ComponentInfo(componentId)
+	if err != nil {
+		return err
+	}
+	d.componentsNames[componentId] = info.Name
+
+	return nil
+}
+
+// appendBinary appends the encoding of the delta to buf.
+//
+// The integers are encoded as varints, and the EntityId as the difference with the previous one.
+func (d *delta) appendBinary(buf []byte) []byte {
+	var flags byte
+	if d.full {
+		flags |= fullDelta
+	}
+	buf = append(buf, deltaVersion, flags)
+	buf = binary.AppendUvarint(buf, d.from)
+	buf = binary.AppendUvarint(buf, d.to)
+
+	componentsIds := make([]volt.ComponentId, 0, len(d.componentsNames))
+	for componentId := range d.componentsNames {
+		componentsIds = append(componentsIds, componentId)
+	}
+	slices.Sort(componentsIds)
+
+	buf = binary.AppendUvarint(buf, uint64(len(componentsIds)))
+	for _, componentId := range componentsIds {
+		buf = binary.AppendUvarint(buf, uint64(componentId))
+		buf = binary.AppendUvarint(buf, uint64(len(d.componentsNames[componentId])))
+		buf = append(buf, d.componentsNames[componentId]...)
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(d.removed)))
+	var previous volt.EntityId
+	for _, entityId := range d.removed {
+		buf = binary.AppendUvarint(buf, uint64(entityId-previous))
+		previous = entityId
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(d.changes)))
+	previous = 0
+	for _, change := range d.changes {
+		buf = binary.AppendUvarint(buf, uint64(change.id-previous))
+		previous = change.id
+
+		flags = 0
+		if change.created {
+			flags |= createdEntity
+		}
+		buf = append(buf, flags)
+
+		buf = binary.AppendUvarint(buf, uint64(len(change.removed)))
+		for _, componentId := range change.removed {
+			buf = binary.AppendUvarint(buf, uint64(componentId))
+		}
+
+		buf = binary.AppendUvarint(buf, uint64(len(change.set)))
+		for _, component := range change.set {
+			buf = binary.AppendUvarint(buf, uint64(component.id))
+			buf = binary.AppendUvarint(buf, uint64(len(component.data)))
+			buf = append(buf, component.data...)
+		}
+	}
+
+	return buf
+}
+
+// decodeDelta decodes a delta encoded by appendBinary.
+//
+// The data of the components are views on data.
+func decodeDelta(data []byte) (delta, error) {
+	r := reader{data: data}
+	d := delta{componentsNames: make(map[volt.ComponentId]string)}
+
+	if version := r.byte(); r.err == nil && version != deltaVersion {
+		return d, fmt.Errorf("the delta version %d is not supported", version)
+	}
+	d.full = r.byte()&fullDelta != 0
+	d.from = r.uvarint()
+	d.to = r.uvarint()
+
+	for range r.count() {
+		componentId := r.componentId()
+		d.componentsNames[componentId] = string(r.bytes(r.count()))
+	}
+
+	var previous volt.EntityId
+	for range r.count() {
+		previous += volt.EntityId(r.uvarint())
+		d.removed = append(d.removed, previous)
+	}
+
+	previous = 0
+	for range r.count() {
+		previous += volt.EntityId(r.uvarint())
+		change := entityChange{id: previous, created: r.byte()&createdEntity != 0}
+
+		for range r.count() {
+			change.removed = append(change.removed, r.componentId())
+		}
+		for range r.count() {
+			componentId := r.componentId()
+			change.set = append(change.set, componentValue{id: componentId, data: r.bytes(r.count())})
+		}
+
+		d.changes = append(d.changes, change)
+	}
+
+	if r.err == nil && len(r.data) > 0 {
+		r.err = fmt.Errorf("%d bytes left after the delta", len(r.data))
+	}
+	if r.err != nil {
+		return d, fmt.Errorf("the delta is malformed: %w", r.err)
+	}
+
+	return d, nil
+}
+
+// reader decodes the fields of a delta. Once an error occurs, it is kept and the following reads return zero values.
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data) == 0 {
+		r.err = fmt.Errorf("unexpected end of data")
+		return 0
+	}
+
+	b := r.data[0]
+	r.data = r.data[1:]
+
+	return b
+}
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+
+	value, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = fmt.Errorf("invalid varint")
+		return 0
+	}
+	r.data = r.data[n:]
+
+	return value
+}
+
+// count reads a length, which cannot exceed the bytes left since each element takes at least a byte.
+func (r *reader) count() int {
+	count := r.uvarint()
+	if count > uint64(len(r.data)) {
+		if r.err == nil {
+			r.err = fmt.Errorf("the length %d overflows the %d bytes left", count, len(r.data))
+		}
+
+		return 0
+	}
+
+	return int(count)
+}
+
+func (r *reader) componentId() volt.ComponentId {
+	componentId := r.uvarint()
+	if componentId >= volt.TAGS_INDICES && r.err == nil {
+		r.err = fmt.Errorf("invalid ComponentId %d", componentId)
+	}
+
+	return volt.ComponentId(componentId)
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	b := r.data[:n:n]
+	r.data = r.data[n:]
+
+	return b
+}
@@ -0,0 +1,109 @@
+package replication
+
+import (
+	"github.com/akmonengine/volt"
+)
+
+// RelevancyFn reports whether the entity is relevant to an observer, e.g. within its range of sight,
+// owning a tag shared with it, or matched by a query.
+//
+// It is called at each tick captured, for each entity with replicated components.
+type RelevancyFn func(world *volt.World, entityId volt.EntityId) bool
+
+// Observer is the view of a Client on the World of a Server: only the entities relevant to it are replicated.
+//
+// At each tick captured, the entities entering the relevancy set are created on the Client,
+// and the entities leaving it are removed.
+type Observer struct {
+	server      *Server
+	relevancyFn RelevancyFn
+	history     []*State
+
+	entered []volt.EntityId
+	stayed  []volt.EntityId
+	left    []volt.EntityId
+}
+
+// AddObserver adds to the Server an Observer, to which the entities are relevant if relevancyFn returns true.
+//
+// Its history starts at the next tick captured.
+func (server *Server) AddObserver(relevancyFn RelevancyFn) *Observer {
+	observer := &Observer{
+		server:      server,
+		relevancyFn: relevancyFn,
+	}
+	server.observers = append(server.observers, observer)
+
+	return observer
+}
+
+// RemoveObserver removes the Observer from the Server, e.g. once its Client is disconnected.
+func (server *Server) RemoveObserver(observer *Observer) {
+	for i, o := range server.observers {
+		if o == observer {
+			server.observers = append(server.observers[:i], server.observers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Entered returns the entities which became relevant at the last tick captured.
+//
+// The slice is reused by the next Capture, and must not be modified: copy it to keep it, e.g. with slices.Clone.
+func (observer *Observer) Entered() []volt.EntityId {
+	return observer.entered
+}
+
+// Stayed returns the entities which are still relevant at the last tick captured.
+//
+// The slice is reused by the next Capture, and must not be modified: copy it to keep it, e.g. with slices.Clone.
+func (observer *Observer) Stayed() []volt.EntityId {
+	return observer.stayed
+}
+
+// Left returns the entities which are not relevant anymore at the last tick captured, or have been removed.
+//
+// The slice is reused by the next Capture, and must not be modified: copy it to keep it, e.g. with slices.Clone.
+func (observer *Observer) Left() []volt.EntityId {
+	return observer.left
+}
+
+// Delta returns the binary delta from the tick from, to the tick to, holding only the entities relevant to the Observer.
+//
+// The entities entering the relevancy set are created, and the entities leaving it are removed. See Server.Delta.
+func (observer *Observer) Delta(from uint64, to uint64) ([]byte, error) {
+	return encodeDelta(observer.server.world, observer.history, from, to)
+}
+
+// capture filters the State captured by the Server, and computes the entities entering, staying and leaving.
+func (observer *Observer) capture(state *State, historySize int) {
+	relevant := &State{Tick: state.Tick, data: state.data}
+	for _, entity := range state.entities {
+		if observer.relevancyFn(observer.server.world, entity.id) {
+			relevant.entities = append(relevant.entities, entity)
+		}
+	}
+
+	previous := &State{}
+	if len(observer.history) > 0 {
+		previous = observer.history[len(observer.history)-1]
+	}
+	observer.entered, observer.stayed, observer.left = observer.entered[:0], observer.stayed[:0], observer.left[:0]
+	i, j := 0, 0
+	for i < len(previous.entities) || j < len(relevant.entities) {
+		switch {
+		case j == len(relevant.entities) || (i < len(previous.entities) && previous.entities[i].id < relevant.entities[j].id):
+			observer.left = append(observer.left, previous.entities[i].id)
+			i++
+		case i == len(previous.entities) || relevant.entities[j].id < previous.entities[i].id:
+			observer.entered = append(observer.entered, relevant.entities[j].id)
+			j++
+		default:
+			observer.stayed = append(observer.stayed, relevant.entities[j].id)
+			i++
+			j++
+		}
+	}
+
+	observer.history = appendHistory(observer.history, relevant, historySize)
+}
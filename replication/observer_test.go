@@ -0,0 +1,109 @@
+package replication
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/akmonengine/volt"
+)
+
+const teamTag = volt.TAGS_INDICES + 1
+
+// inRange returns a RelevancyFn for the entities within distance of x, or with the tag teamTag.
+func inRange(x float32, distance float32) RelevancyFn {
+	return func(world *volt.World, entityId volt.EntityId) bool {
+		if world.HasTag(teamTag, entityId) {
+			return true
+		}
+
+		position := volt.GetComponent[positionComponent](world, entityId)
+		return position != nil && position.X >= x-distance && position.X <= x+distance
+	}
+}
+
+func TestObserver_EnterStayLeave(t *testing.T) {
+	world := createServerWorld(t)
+	server := NewServer(world, 8)
+	observer := server.AddObserver(inRange(0, 10))
+
+	near := world.CreateEntity()
+	volt.AddComponent(world, near, positionComponent{X: 5})
+	far := world.CreateEntity()
+	volt.AddComponent(world, far, positionComponent{X: 50})
+	teammate := world.CreateEntity()
+	volt.AddComponent(world, teammate, positionComponent{X: 100})
+	world.AddTag(teamTag, teammate)
+
+	server.Capture(1)
+	assertEntities(t, "entered", observer.Entered(), near, teammate)
+	assertEntities(t, "stayed", observer.Stayed())
+	assertEntities(t, "left", observer.Left())
+
+	// near leaves the range, far enters it.
+	volt.GetComponent[positionComponent](world, near).X = 20
+	volt.GetComponent[positionComponent](world, far).X = -5
+	server.Capture(2)
+	assertEntities(t, "entered", observer.Entered(), far)
+	assertEntities(t, "stayed", observer.Stayed(), teammate)
+	assertEntities(t, "left", observer.Left(), near)
+
+	world.RemoveEntity(teammate)
+	server.Capture(3)
+	assertEntities(t, "entered", observer.Entered())
+	assertEntities(t, "stayed", observer.Stayed(), far)
+	assertEntities(t, "left", observer.Left(), teammate)
+
+	server.RemoveObserver(observer)
+	if len(server.observers) != 0 {
+		t.Errorf("the observer should be removed")
+	}
+}
+
+func assertEntities(t *testing.T, name string, entitiesIds []volt.EntityId, expected ...volt.EntityId) {
+	t.Helper()
+
+	if !slices.Equal(entitiesIds, expected) {
+		t.Errorf("the entities %s should be %v, got %v", name, expected, entitiesIds)
+	}
+}
+
+func TestObserver_Loopback(t *testing.T) {
+	l := newLoopback(t, 8)
+	l.observer = l.server.AddObserver(inRange(0.5, 0.25))
+
+	var spawned, despawned int
+	l.client.SetEntitySpawnedFn(func(serverEntityId volt.EntityId, entityId volt.EntityId) {
+		spawned++
+		if !l.clientWorld.Exists(entityId) {
+			t.Errorf("the entity %d spawned should exist", entityId)
+		}
+	})
+	l.client.SetEntityDespawnedFn(func(serverEntityId volt.EntityId, entityId volt.EntityId) {
+		despawned++
+		if !l.clientWorld.Exists(entityId) {
+			t.Errorf("the entity %d despawned should still exist in the callback", entityId)
+		}
+	})
+
+	random := rand.New(rand.NewPCG(3, 4))
+	for tick := uint64(1); tick <= 200; tick++ {
+		mutate(l.serverWorld, random)
+		for entityId := range l.serverWorld.Entities() {
+			if random.IntN(50) == 0 {
+				l.serverWorld.AddTag(teamTag, entityId)
+			}
+		}
+
+		lost := tick%5 == 0 || (tick > 100 && tick < 112)
+		l.tick(t, tick, lost)
+
+		if !lost {
+			l.assertConverged(t)
+		}
+	}
+
+	if spawned == 0 || despawned == 0 || spawned-despawned != l.client.Count() {
+		t.Errorf("expected %d entities replicated, got %d spawned and %d despawned", l.client.Count(), spawned, despawned)
+	}
+}
@@ -0,0 +1,106 @@
+// Package replication synchronises the components of a World over the network.
+//
+// The components registered with ComponentConfig.Replicated are captured at each tick by a Server,
+// which encodes the changes between two ticks into a compact binary delta: the entities created or removed,
+// the components added or removed, and the values modified. A Client applies these deltas to its own World,
+// mapping the EntityId of the Server to its local entities.
+//
+// The components are identified by their name in the deltas: they must be registered with the same names
+// on both sides, but their ComponentId may differ. Unless they implement encoding.BinaryMarshaler
+// and encoding.BinaryUnmarshaler, their memory is copied as is: the Server and the Clients must then share
+// the same byte order and the same definition of the components.
+package replication
+
+import (
+	"fmt"
+
+	"github.com/akmonengine/volt"
+)
+
+// NO_TICK is the tick of an empty State: a delta from NO_TICK holds the full State of the World.
+const NO_TICK uint64 = 0
+
+// Server records the State of a World at each tick, and encodes the deltas between them.
+//
+// The deltas hold the whole World, or only the entities relevant to an Observer.
+type Server struct {
+	world       *volt.World
+	historySize int
+	history     []*State
+	observers   []*Observer
+}
+
+// NewServer returns a Server replicating world, that keeps the States of the last historySize ticks.
+//
+// The history should cover the ticks the Clients may not have acknowledged yet.
+func NewServer(world *volt.World, historySize int) *Server {
+	return &Server{
+		world:       world,
+		historySize: max(historySize, 1),
+	}
+}
+
+// Capture records the State of the World at tick, and the entities relevant to each Observer.
+//
+// It returns an error if tick is not greater than the last tick captured.
+func (server *Server) Capture(tick uint64) error {
+	if last := server.lastTick(); tick <= last {
+		return fmt.Errorf("the tick %d is not greater than the last tick captured %d", tick, last)
+	}
+
+	state, err := Capture(server.world, tick)
+	if err != nil {
+		return err
+	}
+
+	server.history = appendHistory(server.history, state, server.historySize)
+	for _, observer := range server.observers {
+		observer.capture(state, server.historySize)
+	}
+
+	return nil
+}
+
+// Delta returns the binary delta from the tick from, to the tick to.
+//
+// from is usually the last tick acknowledged by the Client, see Client.Tick. If it is NO_TICK,
+// or is not in the history anymore, the delta holds the full State at the tick to.
+// It returns an error if:
+//   - the tick to has not been captured, or is not in the history anymore
+//   - from is greater than to
+func (server *Server) Delta(from uint64, to uint64) ([]byte, error) {
+	return encodeDelta(server.world, server.history, from, to)
+}
+
+func (server *Server) lastTick() uint64 {
+	if len(server.history) == 0 {
+		return NO_TICK
+	}
+
+	return server.history[len(server.history)-1].Tick
+}
+
+// appendHistory appends state to history, dropping the oldest State beyond historySize.
+func appendHistory(history []*State, state *State, historySize int) []*State {
+	if len(history) == historySize {
+		copy(history, history[1:])
+		history = history[:len(history)-1]
+	}
+
+	return append(history, state)
+}
+
+// findState returns the State of history captured at tick, or nil.
+func findState(history []*State, tick uint64) *State {
+	if tick == NO_TICK {
+		return nil
+	}
+
+	for _, state := range history {
+		if state.Tick == tick {
+			return state
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,468 @@
+package replication
+
+import (
+	"math/rand/v2"
+	"testing"
+	"unsafe"
+
+	"github.com/akmonengine/volt"
+)
+
+type positionComponent struct {
+	X, Y float32
+}
+
+func (p positionComponent) GetComponentId() volt.ComponentId {
+	return volt.AUTO_COMPONENT_ID
+}
+
+type healthComponent struct {
+	Value int32
+}
+
+func (h healthComponent) GetComponentId() volt.ComponentId {
+	return volt.AUTO_COMPONENT_ID
+}
+
+// labelComponent holds a string: it provides its own binary encoding.
+type labelComponent struct {
+	Text string
+}
+
+func (l labelComponent) GetComponentId() volt.ComponentId {
+	return volt.AUTO_COMPONENT_ID
+}
+
+func (l labelComponent) MarshalBinary() ([]byte, error) {
+	return []byte(l.Text), nil
+}
+
+func (l *labelComponent) UnmarshalBinary(data []byte) error {
+	l.Text = string(data)
+	return nil
+}
+
+type aiComponent struct {
+	State int
+}
+
+func (a aiComponent) GetComponentId() volt.ComponentId {
+	return volt.AUTO_COMPONENT_ID
+}
+
+func createServerWorld(t *testing.T) *volt.World {
+	world := volt.CreateWorld(64)
+	if err := volt.RegisterComponent[aiComponent](world, &volt.ComponentConfig[aiComponent]{Name: "ai"}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := volt.RegisterComponent[positionComponent](world, &volt.ComponentConfig[positionComponent]{Name: "position", Replicated: true}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := volt.RegisterComponent[healthComponent](world, &volt.ComponentConfig[healthComponent]{Name: "health", Replicated: true, Storage: volt.SPARSE_STORAGE}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := volt.RegisterComponent[labelComponent](world, &volt.ComponentConfig[labelComponent]{Name: "label", Replicated: true}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	return world
+}
+
+// createClientWorld registers the components in another order than the server, so that their ComponentId differ.
+func createClientWorld(t *testing.T) *volt.World {
+	world := volt.CreateWorld(64)
+	if err := volt.RegisterComponent[labelComponent](world, &volt.ComponentConfig[labelComponent]{Name: "label", Replicated: true}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := volt.RegisterComponent[healthComponent](world, &volt.ComponentConfig[healthComponent]{Name: "health", Replicated: true, Storage: volt.SPARSE_STORAGE}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := volt.RegisterComponent[positionComponent](world, &volt.ComponentConfig[positionComponent]{Name: "position", Replicated: true}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	return world
+}
+
+// loopback connects a Server and a Client in process.
+//
+// If observer is set, the Client only receives the entities relevant to it.
+type loopback struct {
+	serverWorld *volt.World
+	clientWorld *volt.World
+	server      *Server
+	observer    *Observer
+	client      *Client
+	bytesSent   int
+}
+
+func newLoopback(t *testing.T, historySize int) *loopback {
+	serverWorld := createServerWorld(t)
+	clientWorld := createClientWorld(t)
+
+	return &loopback{
+		serverWorld: serverWorld,
+		clientWorld: clientWorld,
+		server:      NewServer(serverWorld, historySize),
+		client:      NewClient(clientWorld),
+	}
+}
+
+// tick captures the server World, and sends the delta from the tick acknowledged by the client, unless it is lost.
+func (l *loopback) tick(t *testing.T, tick uint64, lost bool) {
+	if err := l.server.Capture(tick); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	var data []byte
+	var err error
+	if l.observer != nil {
+		data, err = l.observer.Delta(l.client.Tick(), tick)
+	} else {
+		data, err = l.server.Delta(l.client.Tick(), tick)
+	}
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	l.bytesSent += len(data)
+	if lost {
+		return
+	}
+
+	if err = l.client.Apply(data); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+}
+
+func (l *loopback) assertConverged(t *testing.T) {
+	t.Helper()
+
+	replicated := 0
+	for serverEntityId := range l.serverWorld.Entities() {
+		position := volt.GetComponent[positionComponent](l.serverWorld, serverEntityId)
+		health := volt.GetComponent[healthComponent](l.serverWorld, serverEntityId)
+		label := volt.GetComponent[labelComponent](l.serverWorld, serverEntityId)
+
+		entityId, ok := l.client.EntityId(serverEntityId)
+		relevant := l.observer == nil || l.observer.relevancyFn(l.serverWorld, serverEntityId)
+		if (position == nil && health == nil && label == nil) || !relevant {
+			if ok {
+				t.Errorf("the entity %d without replicated component, or not relevant, should not be replicated", serverEntityId)
+			}
+			continue
+		}
+		replicated++
+
+		if !ok || !l.clientWorld.Exists(entityId) {
+			t.Errorf("the entity %d is not replicated", serverEntityId)
+			continue
+		}
+		if clientPosition := volt.GetComponent[positionComponent](l.clientWorld, entityId); !equal(position, clientPosition) {
+			t.Errorf("the entity %d has the position %v on the server, and %v on the client", serverEntityId, position, clientPosition)
+		}
+		if clientHealth := volt.GetComponent[healthComponent](l.clientWorld, entityId); !equal(health, clientHealth) {
+			t.Errorf("the entity %d has the health %v on the server, and %v on the client", serverEntityId, health, clientHealth)
+		}
+		if clientLabel := volt.GetComponent[labelComponent](l.clientWorld, entityId); !equal(label, clientLabel) {
+			t.Errorf("the entity %d has the label %v on the server, and %v on the client", serverEntityId, label, clientLabel)
+		}
+	}
+
+	if l.client.Count() != replicated || l.clientWorld.Count() != replicated {
+		t.Errorf("expected %d entities replicated, got %d in a world of %d entities", replicated, l.client.Count(), l.clientWorld.Count())
+	}
+}
+
+func equal[T comparable](a *T, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// mutate applies random changes to the World of the server.
+func mutate(world *volt.World, random *rand.Rand) {
+	var entitiesIds []volt.EntityId
+	for entityId := range world.Entities() {
+		entitiesIds = append(entitiesIds, entityId)
+	}
+
+	for _, entityId := range entitiesIds {
+		switch random.IntN(10) {
+		case 0:
+			world.RemoveEntity(entityId)
+		case 1:
+			if volt.GetComponent[healthComponent](world, entityId) != nil {
+				volt.RemoveComponent[healthComponent](world, entityId)
+			} else {
+				volt.AddComponent(world, entityId, healthComponent{Value: random.Int32N(100)})
+			}
+		case 2:
+			if volt.GetComponent[positionComponent](world, entityId) != nil {
+				volt.RemoveComponent[positionComponent](world, entityId)
+			} else {
+				volt.AddComponent(world, entityId, positionComponent{})
+			}
+		case 3, 4, 5:
+			if position := volt.GetComponent[positionComponent](world, entityId); position != nil {
+				position.X += random.Float32()
+			}
+			if label := volt.GetComponent[labelComponent](world, entityId); label != nil {
+				label.Text += "!"
+			}
+		}
+	}
+
+	for range random.IntN(5) {
+		entityId := world.CreateEntity()
+		volt.AddComponent(world, entityId, aiComponent{State: 1})
+		if random.IntN(3) > 0 {
+			volt.AddComponent(world, entityId, positionComponent{X: random.Float32(), Y: random.Float32()})
+		}
+		if random.IntN(2) > 0 {
+			volt.AddComponent(world, entityId, labelComponent{Text: "npc"})
+		}
+	}
+}
+
+func TestReplication_Loopback(t *testing.T) {
+	l := newLoopback(t, 8)
+	random := rand.New(rand.NewPCG(1, 2))
+
+	for tick := uint64(1); tick <= 200; tick++ {
+		mutate(l.serverWorld, random)
+
+		// Some deltas are lost, a few times for longer than the history.
+		lost := tick%7 == 0 || (tick > 100 && tick < 112)
+		l.tick(t, tick, lost)
+
+		if !lost {
+			l.assertConverged(t)
+		}
+	}
+
+	if l.client.Tick() != 200 {
+		t.Errorf("the client should be at the tick 200, got %d", l.client.Tick())
+	}
+}
+
+func TestReplication_DeltaSize(t *testing.T) {
+	l := newLoopback(t, 8)
+	for range 100 {
+		entityId := l.serverWorld.CreateEntity()
+		volt.AddComponent(l.serverWorld, entityId, positionComponent{X: 1, Y: 2})
+	}
+	l.tick(t, 1, false)
+	sent := l.bytesSent
+
+	// Only the position modified is sent, along with the name of its component.
+	*volt.GetComponent[positionComponent](l.serverWorld, 42) = positionComponent{X: 3, Y: 4}
+	l.tick(t, 2, false)
+	if l.bytesSent-sent > 40 {
+		t.Errorf("the delta of a single component should be smaller than 40 bytes, got %d", l.bytesSent-sent)
+	}
+	l.assertConverged(t)
+	sent = l.bytesSent
+
+	l.tick(t, 3, false)
+	if l.bytesSent-sent > 8 {
+		t.Errorf("the delta of an unchanged World should be empty, got %d bytes", l.bytesSent-sent)
+	}
+}
+
+func TestClient_Apply(t *testing.T) {
+	l := newLoopback(t, 8)
+	entityId := l.serverWorld.CreateEntity()
+	volt.AddComponent(l.serverWorld, entityId, positionComponent{X: 1})
+	l.tick(t, 1, false)
+
+	volt.AddComponent(l.serverWorld, entityId, healthComponent{Value: 10})
+	l.server.Capture(2)
+	l.server.Capture(3)
+
+	data, err := l.server.Delta(2, 3)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err = l.client.Apply(data); err == nil {
+		t.Errorf("Apply should reject a delta which does not start at the tick of the client")
+	}
+
+	data, err = l.server.Delta(1, 3)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	for i := range data {
+		if err = NewClient(createClientWorld(t)).Apply(data[:i]); err == nil {
+			t.Errorf("Apply should reject a delta truncated to %d bytes", i)
+		}
+	}
+
+	if err = NewClient(volt.CreateWorld(16)).Apply(data); err == nil {
+		t.Errorf("Apply should reject a delta with components not registered")
+	}
+
+	if err = l.client.Apply(data); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	l.assertConverged(t)
+
+	if _, err = l.server.Delta(3, 2); err == nil {
+		t.Errorf("Delta should reject a tick from greater than the tick to")
+	}
+	if _, err = l.server.Delta(3, 4); err == nil {
+		t.Errorf("Delta should reject a tick not captured")
+	}
+	if err = l.server.Capture(3); err == nil {
+		t.Errorf("Capture should reject a tick already captured")
+	}
+}
+
+// targetComponent holds the EntityId of another entity, remapped to the entities of the client.
+type targetComponent struct {
+	Target volt.EntityId
+}
+
+func (t targetComponent) GetComponentId() volt.ComponentId {
+	return volt.AUTO_COMPONENT_ID
+}
+
+func TestClient_RemapAndSpawn(t *testing.T) {
+	serverWorld := createServerWorld(t)
+	clientWorld := createClientWorld(t)
+	for _, world := range []*volt.World{serverWorld, clientWorld} {
+		err := volt.RegisterComponent[targetComponent](world, &volt.ComponentConfig[targetComponent]{
+			Name:       "target",
+			Replicated: true,
+			RemapFn: func(component *targetComponent, entitiesIds map[volt.EntityId]volt.EntityId) {
+				component.Target = entitiesIds[component.Target]
+			},
+		})
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+	}
+	server := NewServer(serverWorld, 8)
+	client := NewClient(clientWorld)
+
+	// The client holds an entity of its own, so that the EntityId differ; the hunter targets an entity created after it.
+	clientWorld.CreateEntity()
+	hunterId := serverWorld.CreateEntity()
+	preyId := serverWorld.CreateEntity()
+	volt.AddComponent(serverWorld, hunterId, targetComponent{Target: preyId})
+	volt.AddComponent(serverWorld, preyId, positionComponent{X: 1})
+
+	// The callback is called once per entity created, after the whole delta is applied and remapped.
+	var spawned []volt.EntityId
+	client.SetEntitySpawnedFn(func(serverEntityId volt.EntityId, entityId volt.EntityId) {
+		spawned = append(spawned, serverEntityId)
+		if _, ok := client.EntityId(preyId); !ok {
+			t.Errorf("all the entities of the delta should be created before the callback")
+		}
+		if serverEntityId != hunterId {
+			return
+		}
+		clientPreyId, _ := client.EntityId(preyId)
+		if target := volt.GetComponent[targetComponent](clientWorld, entityId); target == nil || target.Target != clientPreyId {
+			t.Errorf("the target should be remapped to the entity %d of the client, got %v", clientPreyId, target)
+		}
+	})
+
+	if err := server.Capture(1); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	data, err := server.Delta(NO_TICK, 1)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err = client.Apply(data); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if len(spawned) != 2 {
+		t.Errorf("the callback should be called for the 2 entities created, got %v", spawned)
+	}
+}
+
+// paddedComponent has padding bytes between its fields.
+type paddedComponent struct {
+	Flag  uint8
+	Value uint32
+}
+
+func (p paddedComponent) GetComponentId() volt.ComponentId {
+	return volt.AUTO_COMPONENT_ID
+}
+
+func TestReplication_Padding(t *testing.T) {
+	world := createServerWorld(t)
+	if err := volt.RegisterComponent[paddedComponent](world, &volt.ComponentConfig[paddedComponent]{Name: "padded", Replicated: true}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	server := NewServer(world, 8)
+	entityId := world.CreateEntity()
+	volt.AddComponent(world, entityId, paddedComponent{Flag: 1, Value: 2})
+	if err := server.Capture(1); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	// Only the padding bytes change: the component is not modified.
+	component := volt.GetComponent[paddedComponent](world, entityId)
+	unsafe.Slice((*byte)(unsafe.Pointer(component)), unsafe.Sizeof(*component))[1] = 0xFF
+	if err := server.Capture(2); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	data, err := server.Delta(1, 2)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	d, err := decodeDelta(data)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if len(d.changes) > 0 {
+		t.Errorf("the padding bytes should not be replicated, got %d changes", len(d.changes))
+	}
+}
+
+type teamComponent struct {
+	Id uint8
+}
+
+func (t teamComponent) GetComponentId() volt.ComponentId {
+	return volt.AUTO_COMPONENT_ID
+}
+
+func TestReplication_SharedComponent(t *testing.T) {
+	l := newLoopback(t, 8)
+	for _, world := range []*volt.World{l.serverWorld, l.clientWorld} {
+		if err := volt.RegisterComponent[teamComponent](world, &volt.ComponentConfig[teamComponent]{Name: "team", Replicated: true, Storage: volt.SHARED_STORAGE}); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+	}
+
+	for i := range 10 {
+		entityId := l.serverWorld.CreateEntity()
+		volt.AddComponent(l.serverWorld, entityId, positionComponent{X: float32(i)})
+		volt.SetSharedComponent(l.serverWorld, entityId, teamComponent{Id: uint8(i % 2)})
+	}
+	l.tick(t, 1, false)
+
+	volt.SetSharedComponent(l.serverWorld, 0, teamComponent{Id: 1})
+	volt.RemoveComponent[teamComponent](l.serverWorld, 1)
+	l.tick(t, 2, false)
+	l.assertConverged(t)
+
+	for serverEntityId := range l.serverWorld.Entities() {
+		entityId, _ := l.client.EntityId(serverEntityId)
+		if team := volt.GetComponent[teamComponent](l.clientWorld, entityId); !equal(volt.GetComponent[teamComponent](l.serverWorld, serverEntityId), team) {
+			t.Errorf("the entity %d should be replicated in its team, got %v", serverEntityId, team)
+		}
+	}
+	entityId0, _ := l.client.EntityId(0)
+	entityId3, _ := l.client.EntityId(3)
+	if team0, team3 := volt.GetComponent[teamComponent](l.clientWorld, entityId0), volt.GetComponent[teamComponent](l.clientWorld, entityId3); team0 == nil || team0 != team3 {
+		t.Errorf("the entities of a team should share the value on the client")
+	}
+}
@@ -0,0 +1,66 @@
+package replication
+
+import (
+	"github.com/akmonengine/volt"
+)
+
+// State is the replicated state of a World at a given tick:
+// the replicated components of each entity, encoded with World.AppendComponentBinary.
+type State struct {
+	Tick uint64
+
+	entities []entityState
+	data     []byte
+}
+
+// entityState lists the replicated components of an entity, in ascending order of ComponentId.
+type entityState struct {
+	id         volt.EntityId
+	components []componentState
+}
+
+// componentState is a component encoded in State.data[start:end].
+type componentState struct {
+	id         volt.ComponentId
+	start, end int
+}
+
+// Capture returns the State of the components registered as Replicated in the World, at tick.
+//
+// The entities without replicated component are not part of the State.
+func Capture(world *volt.World, tick uint64) (*State, error) {
+	componentsIds := world.ReplicatedComponentsIds()
+	state := &State{Tick: tick}
+
+	var err error
+	for entityId := range world.Entities() {
+		var components []componentState
+		for _, componentId := range componentsIds {
+			if !world.HasComponents(entityId, componentId) {
+				continue
+			}
+
+			start := len(state.data)
+			state.data, err = world.AppendComponentBinary(state.data, entityId, componentId)
+			if err != nil {
+				return nil, err
+			}
+			components = append(components, componentState{id: componentId, start: start, end: len(state.data)})
+		}
+
+		if len(components) > 0 {
+			state.entities = append(state.entities, entityState{id: entityId, components: components})
+		}
+	}
+
+	return state, nil
+}
+
+// Count returns the number of entities in the State.
+func (state *State) Count() int {
+	return len(state.entities)
+}
+
+func (state *State) componentData(component componentState) []byte {
+	return state.data[component.start:component.end]
+}
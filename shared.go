@@ -0,0 +1,329 @@
+package volt
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// SHARED_INDICES is the first id of the groups of the shared components, see SHARED_STORAGE.
+//
+// The ids from SHARED_INDICES are held by the archetypes as tags, and are not allowed as TagId.
+const SHARED_INDICES = 0xC000
+
+// sharedGroup is a value of a shared component, identified in the archetypes by its id from SHARED_INDICES.
+// The value of a freed group is -1, see releaseSharedGroups.
+type sharedGroup struct {
+	componentId ComponentId
+	value       int
+}
+
+// sharedSet stores the distinct values of a component registered with SHARED_STORAGE.
+//
+// Each value is a group: the entities sharing it belong to the archetypes holding the id of the group,
+// and groups maps each archetype to its value, shifted by one so that the zero value means absent.
+// The values of the freed groups have a zero group id, and are reused from free.
+type sharedSet[T any] struct {
+	values   []T
+	groupIds []ComponentId
+	groups   []int
+	free     []int
+
+	// keys indexes the values of a hashable type, the others are compared with equalFn.
+	keys    map[any]int
+	equalFn func(a T, b T) bool
+}
+
+func newSharedSet[T any](equalFn func(a T, b T) bool) *sharedSet[T] {
+	set := &sharedSet[T]{equalFn: equalFn}
+	if equalFn == nil && hashable(reflect.TypeFor[T]()) {
+		set.keys = make(map[any]int)
+	}
+
+	return set
+}
+
+// hashable reports whether any value of t can be a key of a map. A comparable type with an interface field is not:
+// hashing it panics if the field holds a value which cannot be compared, e.g. a slice.
+func hashable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface:
+		return false
+	case reflect.Array:
+		return hashable(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if !hashable(t.Field(i).Type) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return t.Comparable()
+	}
+}
+
+// find returns the index of the value equal to component, or -1.
+func (set *sharedSet[T]) find(component T) int {
+	if set.keys != nil {
+		if value, ok := set.keys[component]; ok {
+			return value
+		}
+
+		return -1
+	}
+
+	for value := range set.values {
+		if set.groupIds[value] != 0 && set.equal(set.values[value], component) {
+			return value
+		}
+	}
+
+	return -1
+}
+
+func (set *sharedSet[T]) equal(a T, b T) bool {
+	if set.equalFn != nil {
+		return set.equalFn(a, b)
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// get returns a pointer to the value shared by the entities of archetypeId, or nil.
+func (set *sharedSet[T]) get(archetypeId archetypeId) *T {
+	if set == nil || int(archetypeId) >= len(set.groups) || set.groups[archetypeId] == 0 {
+		return nil
+	}
+
+	return &set.values[set.groups[archetypeId]-1]
+}
+
+func (set *sharedSet[T]) link(archetypeId archetypeId, value int) {
+	for len(set.groups) <= int(archetypeId) {
+		set.groups = append(set.groups, 0)
+	}
+	set.groups[archetypeId] = value + 1
+}
+
+// insert stores the value component of the group groupId, in the slot of a freed value if any.
+func (set *sharedSet[T]) insert(groupId ComponentId, component T) int {
+	value := len(set.values)
+	if len(set.free) > 0 {
+		value = set.free[len(set.free)-1]
+		set.free = set.free[:len(set.free)-1]
+		set.values[value] = component
+		set.groupIds[value] = groupId
+	} else {
+		set.values = append(set.values, component)
+		set.groupIds = append(set.groupIds, groupId)
+	}
+	if set.keys != nil {
+		set.keys[component] = value
+	}
+
+	return value
+}
+
+// remove frees the slot of value, zeroed so that the memory it refers to can be released.
+func (set *sharedSet[T]) remove(value int) {
+	if set.keys != nil {
+		delete(set.keys, set.values[value])
+	}
+
+	var t T
+	set.values[value] = t
+	set.groupIds[value] = 0
+	set.free = append(set.free, value)
+}
+
+func (set *sharedSet[T]) clone(cloneFn func(component T) T) *sharedSet[T] {
+	clone := &sharedSet[T]{
+		values:   cloneColumn(set.values, cloneFn),
+		groupIds: slices.Clone(set.groupIds),
+		groups:   slices.Clone(set.groups),
+		free:     slices.Clone(set.free),
+		equalFn:  set.equalFn,
+	}
+	if set.keys != nil {
+		clone.keys = make(map[any]int, len(clone.values))
+		for value, component := range clone.values {
+			if clone.groupIds[value] != 0 {
+				clone.keys[component] = value
+			}
+		}
+	}
+
+	return clone
+}
+
+func (c *ComponentsStorage[T]) isShared() bool {
+	return c.shared != nil
+}
+
+// getShared returns the shared set of the storage, or nil.
+func (c *ComponentsStorage[T]) getShared() *sharedSet[T] {
+	if c == nil {
+		return nil
+	}
+
+	return c.shared
+}
+
+func (c *ComponentsStorage[T]) linkGroup(archetypeId archetypeId, value int) {
+	if c.shared != nil {
+		c.shared.link(archetypeId, value)
+	}
+}
+
+func (c *ComponentsStorage[T]) freeGroup(value int) {
+	if c.shared != nil {
+		c.shared.remove(value)
+	}
+}
+
+// SetSharedComponent sets the value of the shared component T of the entity, see SHARED_STORAGE.
+//
+// The entity moves to the group of the entities sharing an equal value, created on its first use:
+// the value is stored once for the whole group, and the queries return a pointer to it.
+// The value must not be modified through this pointer, which would change it for the whole group:
+// SetSharedComponent moves the entity to the group of the new value instead.
+// As the pointers to the columns, the pointer is valid until the next structural change of the World.
+//
+// It calls the callback set in SetComponentAddedFn if the entity did not own T.
+// It returns an error if:
+//   - the entity does not exist
+//   - T is not registered with SHARED_STORAGE
+//   - a unique index rejects the value, see CreateUniqueIndex
+//   - no group id is left, see SHARED_INDICES
+func SetSharedComponent[T ComponentInterface](world *World, entityId EntityId, component T) error {
+	if !world.Exists(entityId) {
+		return fmt.Errorf("entity %v does not exist", entityId)
+	}
+
+	componentId := ComponentIdOf[T](world)
+	owned := world.HasComponents(entityId, componentId)
+	if err := setSharedComponent(world, entityId, componentId, component); err != nil {
+		return err
+	}
+
+	if !owned {
+		world.componentAddedFn(entityId, componentId)
+	}
+
+	return nil
+}
+
+// setSharedComponent moves the entity to the group of component, without calling the callbacks.
+func setSharedComponent[T ComponentInterface](world *World, entityId EntityId, componentId ComponentId, component T) error {
+	s := getStorage[T](world)
+	if s == nil || s.shared == nil {
+		return fmt.Errorf("the component %s is not registered with SHARED_STORAGE", world.componentName(componentId))
+	}
+	if err := checkIndexes(world, entityId, componentId, component); err != nil {
+		return err
+	}
+
+	groupId, err := sharedGroupId(world, componentId, s.shared, component)
+	if err != nil {
+		return err
+	}
+
+	entityRecord := world.entities.get(entityId)
+	if world.archetypes[entityRecord.archetypeId].signature.has(groupId) {
+		return nil
+	}
+
+	componentsIds := append(world.withoutSharedComponent(entityRecord.archetypeId, componentId), componentId, groupId)
+	archetype := world.getArchetypeForComponentsIds(componentsIds...)
+	oldArchetype := &world.archetypes[entityRecord.archetypeId]
+
+	moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+	world.setArchetype(entityRecord, archetype)
+
+	world.updateIndexes(entityId, componentId)
+
+	return nil
+}
+
+// sharedGroupId returns the id of the group of the value component, created if no group holds an equal value.
+// The ids of the freed groups are reused first.
+func sharedGroupId[T any](world *World, componentId ComponentId, set *sharedSet[T], component T) (ComponentId, error) {
+	if value := set.find(component); value >= 0 {
+		return set.groupIds[value], nil
+	}
+
+	if len(world.freeSharedGroups) > 0 {
+		groupId := world.freeSharedGroups[len(world.freeSharedGroups)-1]
+		world.freeSharedGroups = world.freeSharedGroups[:len(world.freeSharedGroups)-1]
+		world.sharedGroups[groupId-SHARED_INDICES] = sharedGroup{componentId: componentId, value: set.insert(groupId, component)}
+
+		return groupId, nil
+	}
+
+	groupId := SHARED_INDICES + len(world.sharedGroups)
+	if groupId >= int(AUTO_COMPONENT_ID) {
+		return 0, fmt.Errorf("no group id left for the component %s", world.componentName(componentId))
+	}
+
+	value := set.insert(ComponentId(groupId), component)
+	world.sharedGroups = append(world.sharedGroups, sharedGroup{componentId: componentId, value: value})
+
+	return ComponentId(groupId), nil
+}
+
+// releaseSharedGroups frees the groups held by no archetype, once some have been removed, so that their ids are reused.
+func (world *World) releaseSharedGroups() {
+	held := make([]bool, len(world.sharedGroups))
+	for _, archetype := range world.archetypes {
+		for _, componentId := range archetype.Type {
+			if componentId >= SHARED_INDICES {
+				held[componentId-SHARED_INDICES] = true
+			}
+		}
+	}
+
+	for i, group := range world.sharedGroups {
+		if held[i] || group.value < 0 {
+			continue
+		}
+
+		world.storage[group.componentId].freeGroup(group.value)
+		world.sharedGroups[i].value = -1
+		world.freeSharedGroups = append(world.freeSharedGroups, ComponentId(SHARED_INDICES+i))
+	}
+}
+
+// linkSharedGroups references the new archetype in the shared sets of its groups.
+func (world *World) linkSharedGroups(archetype *archetype) {
+	for _, componentId := range archetype.Type {
+		if componentId >= SHARED_INDICES {
+			group := world.sharedGroups[componentId-SHARED_INDICES]
+			world.storage[group.componentId].linkGroup(archetype.Id, group.value)
+		}
+	}
+}
+
+// withoutSharedComponent returns the ids of the archetype, except the shared component and its group.
+func (world *World) withoutSharedComponent(archetypeId archetypeId, componentId ComponentId) []ComponentId {
+	archetypeType := world.archetypes[archetypeId].Type
+	componentsIds := make([]ComponentId, 0, len(archetypeType)+2)
+	for _, id := range archetypeType {
+		if id == componentId || (id >= SHARED_INDICES && world.sharedGroups[id-SHARED_INDICES].componentId == componentId) {
+			continue
+		}
+		componentsIds = append(componentsIds, id)
+	}
+
+	return componentsIds
+}
+
+// removeSharedComponent moves the entity to the archetype without the shared component and its group.
+func (world *World) removeSharedComponent(entityRecord entityRecord, componentId ComponentId) {
+	archetype := world.getArchetypeForComponentsIds(world.withoutSharedComponent(entityRecord.archetypeId, componentId)...)
+	oldArchetype := &world.archetypes[entityRecord.archetypeId]
+
+	moveComponentsToArchetype(world, entityRecord, oldArchetype, archetype)
+	world.setArchetype(entityRecord, archetype)
+}
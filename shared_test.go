@@ -0,0 +1,431 @@
+package volt
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+type testMaterialComponent struct {
+	texture string
+	opacity float32
+}
+
+func (t testMaterialComponent) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+type testFactionComponent struct {
+	allies []string
+}
+
+func (t testFactionComponent) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+func TestSetSharedComponent(t *testing.T) {
+	world := CreateWorld(16)
+	registerTestComponents(t, world)
+	RegisterComponent[testMaterialComponent](world, &ComponentConfig[testMaterialComponent]{Storage: SHARED_STORAGE})
+	RegisterComponent[testFactionComponent](world, &ComponentConfig[testFactionComponent]{Storage: SHARED_STORAGE})
+	added := 0
+	world.SetComponentAddedFn(func(entityId EntityId, componentId ComponentId) {
+		if componentId == ComponentIdOf[testMaterialComponent](world) {
+			added++
+		}
+	})
+
+	red := testMaterialComponent{texture: "red", opacity: 1}
+	blue := testMaterialComponent{texture: "blue", opacity: 0.5}
+	for i := range 100 {
+		entityId := world.CreateEntity()
+		AddComponent(world, entityId, testComponent1{testComponent{x: i}})
+		material := red
+		if i%2 == 1 {
+			material = blue
+		}
+		if err := SetSharedComponent(world, entityId, material); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+	}
+
+	if values := getStorage[testMaterialComponent](world).shared.values; len(values) != 2 || added != 100 {
+		t.Errorf("the values should be stored once per group, got %v and %d callbacks", values, added)
+	}
+
+	// The entities of a group see the same value.
+	query := CreateQuery2[testComponent1, testMaterialComponent](world, QueryConfiguration{})
+	values := make(map[string]*testMaterialComponent)
+	for result := range query.Foreach(nil) {
+		expected := red
+		if result.A.x%2 == 1 {
+			expected = blue
+		}
+		if *result.B != expected {
+			t.Errorf("the entity %d should see the material %v, got %v", result.EntityId, expected, *result.B)
+		}
+		if pointer, ok := values[result.B.texture]; ok && pointer != result.B {
+			t.Errorf("the entities of a group should share the same value")
+		}
+		values[result.B.texture] = result.B
+	}
+	if query.Count() != 100 {
+		t.Errorf("the query should fetch 100 entities, got %d", query.Count())
+	}
+
+	var mu sync.Mutex
+	count := 0
+	query.Task(4, nil, func(result QueryResult2[testComponent1, testMaterialComponent]) {
+		if result.B != values[result.B.texture] {
+			t.Errorf("the task should see the value of the group")
+		}
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+	if count != 100 {
+		t.Errorf("the task should fetch 100 entities, got %d", count)
+	}
+
+	count = 0
+	for results := range query.ForeachChannel(7, nil) {
+		for result := range results {
+			if result.B == nil || result.B != values[result.B.texture] {
+				t.Errorf("the channel should see the value of the group, got %v", result.B)
+			}
+			count++
+		}
+	}
+	if count != 100 {
+		t.Errorf("the channel should fetch 100 entities, got %d", count)
+	}
+
+	// Changing the value moves the entity to another group, keeping its other components.
+	green := testMaterialComponent{texture: "green"}
+	if err := SetSharedComponent(world, 0, green); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := SetComponent(world, 2, green); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if *GetComponent[testMaterialComponent](world, 0) != green || *GetComponent[testMaterialComponent](world, 2) != green || *GetComponent[testMaterialComponent](world, 4) != red {
+		t.Errorf("the entities 0 and 2 should move to the green group only")
+	}
+	if GetComponent[testComponent1](world, 2).x != 2 || added != 100 {
+		t.Errorf("the entity should keep its components, and the callback should not be called again")
+	}
+	if component, err := world.GetComponent(0, ComponentIdOf[testMaterialComponent](world)); err != nil || *component.(*testMaterialComponent) != green {
+		t.Errorf("the non-generic GetComponent should return the shared value, got %v %v", component, err)
+	}
+
+	// The AddComponent functions reject the shared components, set with SetSharedComponent.
+	entityId := world.CreateEntity()
+	if err := AddComponent(world, entityId, red); err == nil || world.HasComponents(entityId, ComponentIdOf[testMaterialComponent](world)) {
+		t.Errorf("AddComponent should reject a shared component")
+	}
+	if err := world.AddComponent(entityId, ComponentIdOf[testMaterialComponent](world), nil); err == nil {
+		t.Errorf("the non-generic AddComponent should reject a shared component")
+	}
+	if err := AddComponents2(world, world.CreateEntity(), testComponent2{}, red); err == nil {
+		t.Errorf("AddComponents should reject a shared component")
+	}
+	if err := SetSharedComponent(world, entityId, red); err != nil || GetComponent[testMaterialComponent](world, entityId) != GetComponent[testMaterialComponent](world, 4) {
+		t.Errorf("SetSharedComponent should add the entity to the group, got %v", err)
+	}
+
+	// Removing the component or the entity leaves the groups unchanged.
+	if err := RemoveComponent[testMaterialComponent](world, 4); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	world.RemoveEntity(6)
+	if GetComponent[testMaterialComponent](world, 4) != nil || GetComponent[testComponent1](world, 4).x != 4 {
+		t.Errorf("the entity 4 should lose its shared component only")
+	}
+	if GetComponent[testMaterialComponent](world, 8) != GetComponent[testMaterialComponent](world, entityId) || query.Count() != 98 {
+		t.Errorf("the other entities should stay in their group, got %d entities", query.Count())
+	}
+
+	// The values not comparable are compared with reflect.DeepEqual, or EqualFn.
+	SetSharedComponent(world, 10, testFactionComponent{allies: []string{"elves"}})
+	SetSharedComponent(world, 11, testFactionComponent{allies: []string{"elves"}})
+	if GetComponent[testFactionComponent](world, 10) != GetComponent[testFactionComponent](world, 11) {
+		t.Errorf("the equal values should share their group")
+	}
+
+	if err := SetSharedComponent(world, 1, testComponent2{}); err == nil {
+		t.Errorf("SetSharedComponent should reject a component not registered with SHARED_STORAGE")
+	}
+	if err := world.AddTag(SHARED_INDICES, 1); err == nil {
+		t.Errorf("AddTag should reject the ids of the groups")
+	}
+}
+
+func TestSharedComponent_World(t *testing.T) {
+	world := CreateWorld(16)
+	registerTestComponents(t, world)
+	RegisterComponent[testMaterialComponent](world, &ComponentConfig[testMaterialComponent]{Storage: SHARED_STORAGE})
+	RegisterComponent[testFactionComponent](world, &ComponentConfig[testFactionComponent]{Storage: SHARED_STORAGE})
+	red := testMaterialComponent{texture: "red"}
+	blue := testMaterialComponent{texture: "blue"}
+	for i := range 10 {
+		entityId := world.CreateEntity()
+		AddComponent(world, entityId, testComponent1{testComponent{x: i}})
+		SetSharedComponent(world, entityId, red)
+	}
+	world.AddTag(TAG_1, 3)
+
+	// Snapshot and Restore.
+	snapshot := world.Snapshot()
+	SetSharedComponent(world, 1, blue)
+	world.RemoveEntity(2)
+	if err := world.Restore(snapshot); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if *GetComponent[testMaterialComponent](world, 1) != red || *GetComponent[testMaterialComponent](world, 2) != red {
+		t.Errorf("the entities should be restored in their group")
+	}
+
+	// Compact removes the empty groups archetypes, and keeps the others.
+	SetSharedComponent(world, 1, blue)
+	SetSharedComponent(world, 1, red)
+	world.Compact()
+	if *GetComponent[testMaterialComponent](world, 1) != red || *GetComponent[testMaterialComponent](world, 3) != red || !world.HasTag(TAG_1, 3) {
+		t.Errorf("the groups should be kept by Compact")
+	}
+	SetSharedComponent(world, 5, blue)
+	if *GetComponent[testMaterialComponent](world, 5) != blue {
+		t.Errorf("a group should be reused after Compact")
+	}
+
+	// Clone, then Diff.
+	clone := world.Clone()
+	if diff := Diff(world, clone); !diff.Empty() {
+		t.Errorf("the clone should be identical, got %s", diff.String())
+	}
+	SetSharedComponent(clone, 5, red)
+	diff := Diff(world, clone)
+	if len(diff.Entities) != 1 || len(diff.Entities[0].Components) != 1 || diff.Entities[0].Components[0].B != red {
+		t.Errorf("the diff should report the shared component changed, got %s", diff.String())
+	}
+	if *GetComponent[testMaterialComponent](world, 5) != blue {
+		t.Errorf("the clone should not share its groups with the world")
+	}
+
+	// The entities moved to another world join the groups of its values.
+	dst := CreateWorld(16)
+	registerTestComponents(t, dst)
+	RegisterComponent[testMaterialComponent](dst, &ComponentConfig[testMaterialComponent]{Storage: SHARED_STORAGE})
+	RegisterComponent[testFactionComponent](dst, &ComponentConfig[testFactionComponent]{Storage: SHARED_STORAGE})
+	newEntityId, err := MoveEntity(world, dst, 3)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if *GetComponent[testMaterialComponent](dst, newEntityId) != red || !dst.HasTag(TAG_1, newEntityId) {
+		t.Errorf("the shared component and the tag should be transferred")
+	}
+
+	// The deterministic mode keeps the groups in order.
+	world.SetDeterministic(true)
+	SetSharedComponent(world, 0, blue)
+	SetSharedComponent(world, 9, blue)
+	query := CreateQuery1[testMaterialComponent](world, QueryConfiguration{})
+	var entitiesIds []EntityId
+	for result := range query.Foreach(nil) {
+		if result.A.texture == "blue" {
+			entitiesIds = append(entitiesIds, result.EntityId)
+		}
+	}
+	if !slices.Equal(entitiesIds, []EntityId{0, 5, 9}) {
+		t.Errorf("the blue group should iterate in order, got %v", entitiesIds)
+	}
+}
+
+// TestConcurrentSetComponent_Shared moves the entities between the groups from several goroutines,
+// while the queries run. It is meant to be run with the race detector: go test -race.
+func TestConcurrentSetComponent_Shared(t *testing.T) {
+	world := CreateWorld(16)
+	registerTestComponents(t, world)
+	RegisterComponent[testMaterialComponent](world, &ComponentConfig[testMaterialComponent]{Storage: SHARED_STORAGE})
+	RegisterComponent[testFactionComponent](world, &ComponentConfig[testFactionComponent]{Storage: SHARED_STORAGE})
+	cw := CreateConcurrentWorld(world)
+
+	const writers = 4
+	const entitiesCount = 50
+	entitiesIds := make([]EntityId, writers*entitiesCount)
+	for i := range entitiesIds {
+		entitiesIds[i] = cw.CreateEntity()
+		if err := ConcurrentAddComponent(cw, entitiesIds[i], testComponent1{testComponent{x: i}}); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		cw.Write(func(world *World) {
+			if err := SetSharedComponent(world, entitiesIds[i], testMaterialComponent{texture: "red"}); err != nil {
+				t.Fatalf("%s", err.Error())
+			}
+		})
+	}
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, entityId := range entitiesIds[w*entitiesCount : (w+1)*entitiesCount] {
+				material := testMaterialComponent{texture: "blue", opacity: float32(i % 3)}
+				if err := ConcurrentSetComponent(cw, entityId, material); err != nil {
+					t.Errorf("%s", err.Error())
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		query := CreateQuery2[testComponent1, testMaterialComponent](world, QueryConfiguration{})
+		for range entitiesCount {
+			cw.Read(func(world *World) {
+				for result := range query.Foreach(nil) {
+					_ = result.B.texture
+				}
+			})
+		}
+	}()
+	wg.Wait()
+
+	for _, entityId := range entitiesIds {
+		if material, ok := ConcurrentGetComponent[testMaterialComponent](cw, entityId); !ok || material.texture != "blue" {
+			t.Errorf("expected the entity %d in a blue group, got %v", entityId, material)
+		}
+	}
+}
+
+func TestSharedComponent_FreeGroups(t *testing.T) {
+	world := CreateWorld(16)
+	registerTestComponents(t, world)
+	RegisterComponent[testMaterialComponent](world, &ComponentConfig[testMaterialComponent]{Storage: SHARED_STORAGE})
+	RegisterComponent[testFactionComponent](world, &ComponentConfig[testFactionComponent]{Storage: SHARED_STORAGE})
+	entityId := world.CreateEntity()
+
+	// More distinct values than group ids: the unused groups are freed by Compact.
+	for i := range int(AUTO_COMPONENT_ID-SHARED_INDICES) + 100 {
+		if err := SetSharedComponent(world, entityId, testMaterialComponent{opacity: float32(i)}); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		if err := SetSharedComponent(world, entityId, testFactionComponent{allies: []string{"faction", string(rune(i))}}); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		if i%1000 == 999 {
+			world.Compact()
+		}
+	}
+
+	world.Compact()
+	if len(world.sharedGroups)-len(world.freeSharedGroups) != 2 {
+		t.Errorf("expected 2 groups held, got %d", len(world.sharedGroups)-len(world.freeSharedGroups))
+	}
+	if material := GetComponent[testMaterialComponent](world, entityId); material == nil || material.opacity != float32(AUTO_COMPONENT_ID-SHARED_INDICES+99) {
+		t.Errorf("expected the last material, got %v", material)
+	}
+
+	// A freed value is not found anymore, its group is created again.
+	other := world.CreateEntity()
+	if err := SetSharedComponent(world, other, testFactionComponent{allies: []string{"faction", string(rune(0))}}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if faction := GetComponent[testFactionComponent](world, other); faction == nil || faction.allies[1] != string(rune(0)) {
+		t.Errorf("expected the faction of the new group, got %v", faction)
+	}
+
+	clone := world.Clone()
+	world.Clear(false)
+	if len(world.sharedGroups) != len(world.freeSharedGroups) {
+		t.Errorf("expected all the groups freed by Clear, %d are held", len(world.sharedGroups)-len(world.freeSharedGroups))
+	}
+	entityId = world.CreateEntity()
+	if err := SetSharedComponent(world, entityId, testMaterialComponent{texture: "red"}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if material := GetComponent[testMaterialComponent](world, entityId); material == nil || material.texture != "red" {
+		t.Errorf("expected the red material, got %v", material)
+	}
+
+	// The clone keeps its own groups.
+	if faction := GetComponent[testFactionComponent](clone, other); faction == nil || faction.allies[1] != string(rune(0)) {
+		t.Errorf("expected the faction in the clone, got %v", faction)
+	}
+}
+
+type testOwnerComponent struct {
+	owner EntityId
+}
+
+func (t testOwnerComponent) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+func TestSharedComponent_Remap(t *testing.T) {
+	config := &ComponentConfig[testOwnerComponent]{
+		Storage: SHARED_STORAGE,
+		RemapFn: func(component *testOwnerComponent, entitiesIds map[EntityId]EntityId) {
+			component.owner = entitiesIds[component.owner]
+		},
+	}
+	world := CreateWorld(16)
+	RegisterComponent[testOwnerComponent](world, config)
+	other := CreateWorld(16)
+	RegisterComponent[testOwnerComponent](other, config)
+
+	// The value remapped of the entity merged collides with the key of the entity 0.
+	SetSharedComponent(world, world.CreateEntity(), testOwnerComponent{owner: 1})
+	SetSharedComponent(other, other.CreateEntity(), testOwnerComponent{owner: 0})
+	if _, err := CreateUniqueIndex(world, func(component testOwnerComponent) EntityId { return component.owner }); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	if _, err := world.Merge(other); err == nil {
+		t.Errorf("Merge should return the error of the shared component remapped")
+	}
+	if err := world.RemapComponent(1, ComponentIdOf[testOwnerComponent](world), map[EntityId]EntityId{0: 1}); err == nil {
+		t.Errorf("RemapComponent should return the error of the shared component remapped")
+	}
+	if err := world.RemapComponent(1, ComponentIdOf[testOwnerComponent](world), map[EntityId]EntityId{0: 2}); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if owner := GetComponent[testOwnerComponent](world, 1); owner == nil || owner.owner != 2 {
+		t.Errorf("expected the owner remapped to 2, got %v", owner)
+	}
+}
+
+// testBehaviourComponent is comparable, but its interface field may hold a value which cannot be hashed.
+type testBehaviourComponent struct {
+	name   string
+	params any
+}
+
+func (t testBehaviourComponent) GetComponentId() ComponentId {
+	return AUTO_COMPONENT_ID
+}
+
+func TestSharedComponent_Interface(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testBehaviourComponent](world, &ComponentConfig[testBehaviourComponent]{Storage: SHARED_STORAGE})
+
+	behaviours := []testBehaviourComponent{
+		{name: "patrol", params: []string{"a", "b"}},
+		{name: "patrol", params: []string{"a", "b"}},
+		{name: "patrol", params: map[string]int{"speed": 2}},
+		{name: "idle", params: 3},
+	}
+	for _, behaviour := range behaviours {
+		if err := SetSharedComponent(world, world.CreateEntity(), behaviour); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+	}
+
+	// The values are compared as with reflect.DeepEqual, rather than hashed.
+	if values := getStorage[testBehaviourComponent](world).shared.values; len(values) != 3 {
+		t.Errorf("the equal values should share a group, got %v", values)
+	}
+	if component := GetComponent[testBehaviourComponent](world, 1); component == nil || component != GetComponent[testBehaviourComponent](world, 0) {
+		t.Errorf("the entities 0 and 1 should share the same value")
+	}
+}
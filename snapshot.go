@@ -0,0 +1,257 @@
+package volt
+
+import (
+	"fmt"
+)
+
+// DEFAULT_SNAPSHOT_FRAMES is the number of snapshots kept by a World, unless set with SetSnapshotFrames.
+const DEFAULT_SNAPSHOT_FRAMES = 8
+
+// Snapshot refers to a state of the World, taken by World.Snapshot and restored by World.Restore.
+//
+// The state is kept in a ring of frames owned by the World: it is overwritten once as many
+// snapshots as frames have been taken since.
+type Snapshot struct {
+	frame    int
+	sequence uint64
+}
+
+// snapshotRing holds the frames of the snapshots, whose buffers are reused from one snapshot to the next.
+type snapshotRing struct {
+	frames   []worldSnapshot
+	sequence uint64
+}
+
+// worldSnapshot is the copy of the state of a World.
+type worldSnapshot struct {
+	sequence             uint64
+	archetypesGeneration int
+
+	entities      entities
+	entitiesCount int
+
+	poolIds    []EntityId
+	poolNext   EntityId
+	ranges     []*EntityRange
+	rangesIds  [][]EntityId
+	rangesUsed []uint64
+
+	// archetypesEntities holds the entities of each archetype.
+	archetypesEntities [][]EntityId
+	// storage holds the state returned by storage.snapshot, for each ComponentId.
+	storage []any
+}
+
+// SetSnapshotFrames sets the number of snapshots kept by the World, DEFAULT_SNAPSHOT_FRAMES by default.
+//
+// It should cover the frames a rollback can go back to. The snapshots already taken are discarded.
+func (world *World) SetSnapshotFrames(framesCount int) {
+	world.snapshots = snapshotRing{
+		frames:   make([]worldSnapshot, max(framesCount, 1)),
+		sequence: world.snapshots.sequence,
+	}
+}
+
+// Snapshot copies the whole state of the World: the entities, the free EntityId, the archetypes membership
+// and the components of every storage. It can be restored with Restore, e.g. to re-simulate the frames
+// following a rollback.
+//
+// The copy is written into the oldest frame of the ring of the World, reusing its buffers:
+// once the buffers have grown to the size of the World, a snapshot does not allocate.
+// The callbacks and the queries are not part of the snapshot.
+func (world *World) Snapshot() Snapshot {
+	if len(world.snapshots.frames) == 0 {
+		world.SetSnapshotFrames(DEFAULT_SNAPSHOT_FRAMES)
+	}
+
+	world.snapshots.sequence++
+	sequence := world.snapshots.sequence
+	frame := int(sequence % uint64(len(world.snapshots.frames)))
+	snapshot := &world.snapshots.frames[frame]
+
+	snapshot.sequence = sequence
+	snapshot.archetypesGeneration = world.archetypesGeneration
+	snapshot.entities.copyFrom(&world.entities)
+	snapshot.entitiesCount = world.entitiesCount
+
+	snapshot.poolIds = append(snapshot.poolIds[:0], world.pool.ids...)
+	snapshot.poolNext = world.pool.next
+	snapshot.ranges = append(snapshot.ranges[:0], world.pool.ranges...)
+	snapshot.rangesIds = resizeBuffers(snapshot.rangesIds, len(world.pool.ranges))
+	snapshot.rangesUsed = snapshot.rangesUsed[:0]
+	for i, entityRange := range world.pool.ranges {
+		snapshot.rangesIds[i] = append(snapshot.rangesIds[i][:0], entityRange.ids...)
+		snapshot.rangesUsed = append(snapshot.rangesUsed, entityRange.used)
+	}
+
+	snapshot.archetypesEntities = resizeBuffers(snapshot.archetypesEntities, len(world.archetypes))
+	for i, archetype := range world.archetypes {
+		snapshot.archetypesEntities[i] = append(snapshot.archetypesEntities[i][:0], archetype.entities...)
+	}
+
+	if snapshot.storage == nil {
+		snapshot.storage = make([]any, TAGS_INDICES)
+	}
+	for componentId, s := range world.storage {
+		if s != nil {
+			snapshot.storage[componentId] = s.snapshot(snapshot.storage[componentId])
+		}
+	}
+
+	return Snapshot{frame: frame, sequence: sequence}
+}
+
+// Restore sets the World back to the state of the snapshot.
+//
+// The EntityId handed out afterward are the same as after the snapshot, so that re-simulating
+// the same inputs produces the same World. The archetypes created since the snapshot are kept, empty.
+// No callback is called, and Restore must not be called while a query is iterated.
+// It returns an error if:
+//   - the snapshot has been overwritten by the following ones, see SetSnapshotFrames
+//   - the World has been compacted since the snapshot
+func (world *World) Restore(snapshot Snapshot) error {
+	if snapshot.sequence == 0 || snapshot.frame >= len(world.snapshots.frames) || world.snapshots.frames[snapshot.frame].sequence != snapshot.sequence {
+		return fmt.Errorf("the snapshot %d is not available anymore", snapshot.sequence)
+	}
+
+	state := &world.snapshots.frames[snapshot.frame]
+	if state.archetypesGeneration != world.archetypesGeneration {
+		return fmt.Errorf("the snapshot %d cannot be restored, the world has been compacted since", snapshot.sequence)
+	}
+
+	world.guardWorldChange()
+
+	world.entities.copyFrom(&state.entities)
+	world.entitiesCount = state.entitiesCount
+
+	world.pool.ids = append(world.pool.ids[:0], state.poolIds...)
+	world.pool.next = state.poolNext
+	world.pool.ranges = append(world.pool.ranges[:0], state.ranges...)
+	for i, entityRange := range world.pool.ranges {
+		entityRange.ids = append(entityRange.ids[:0], state.rangesIds[i]...)
+		entityRange.used = state.rangesUsed[i]
+	}
+
+	for i := range world.archetypes {
+		archetype := &world.archetypes[i]
+		if i < len(state.archetypesEntities) {
+			archetype.entities = append(archetype.entities[:0], state.archetypesEntities[i]...)
+		} else {
+			archetype.entities = archetype.entities[:0]
+		}
+	}
+
+	for componentId, s := range world.storage {
+		if s != nil {
+			s.restore(state.storage[componentId])
+		}
+	}
+	if world.deterministic {
+		world.sortArchetypesByEntityId()
+	}
+	world.rebuildIndexes()
+
+	return nil
+}
+
+// resizeBuffers sets the length of buffers to length, keeping the buffers beyond it for the next resize.
+func resizeBuffers[T any](buffers [][]T, length int) [][]T {
+	for len(buffers) < length {
+		if len(buffers) < cap(buffers) {
+			buffers = buffers[:len(buffers)+1]
+		} else {
+			buffers = append(buffers, nil)
+		}
+	}
+
+	return buffers[:length]
+}
+
+// restoreColumns copies the columns of the snapshot into columns, reusing their buffers.
+// The columns absent from the snapshot, e.g. of the archetypes created since, are emptied.
+func restoreColumns[T any](columns [][]T, snapshot [][]T) [][]T {
+	columns = resizeBuffers(columns, max(len(columns), len(snapshot)))
+	for i := range columns {
+		if i < len(snapshot) {
+			columns[i] = append(columns[i][:0], snapshot[i]...)
+		} else {
+			columns[i] = columns[i][:0]
+		}
+	}
+
+	return columns
+}
+
+// componentsSnapshot is the state of a ComponentsStorage.
+type componentsSnapshot[T ComponentInterface] struct {
+	columns  [][]T
+	indices  [][]int
+	dense    []T
+	entities []EntityId
+}
+
+// snapshot copies the components into state, a *componentsSnapshot[T] reused if not nil, and returns it.
+func (c *ComponentsStorage[T]) snapshot(state any) any {
+	snapshot, _ := state.(*componentsSnapshot[T])
+	if snapshot == nil {
+		snapshot = &componentsSnapshot[T]{}
+	}
+
+	snapshot.columns = resizeBuffers(snapshot.columns, len(c.archetypesComponentsEntities))
+	for i, column := range c.archetypesComponentsEntities {
+		snapshot.columns[i] = append(snapshot.columns[i][:0], column...)
+	}
+
+	if c.sparse != nil {
+		snapshot.indices = copyIndices(snapshot.indices, c.sparse.indices)
+		snapshot.dense = append(snapshot.dense[:0], c.sparse.dense...)
+		snapshot.entities = append(snapshot.entities[:0], c.sparse.entities...)
+	}
+
+	return snapshot
+}
+
+// restore copies the components of state back into the storage. A nil state, for a component
+// registered after the snapshot, empties the storage.
+func (c *ComponentsStorage[T]) restore(state any) {
+	snapshot, _ := state.(*componentsSnapshot[T])
+	if snapshot == nil {
+		snapshot = &componentsSnapshot[T]{}
+	}
+
+	c.archetypesComponentsEntities = restoreColumns(c.archetypesComponentsEntities, snapshot.columns)
+
+	if c.sparse != nil {
+		c.sparse.indices = copyIndices(c.sparse.indices, snapshot.indices)
+		c.sparse.dense = append(c.sparse.dense[:0], snapshot.dense...)
+		c.sparse.entities = append(c.sparse.entities[:0], snapshot.entities...)
+	}
+}
+
+// dynamicSnapshot is the state of a dynamicStorage.
+type dynamicSnapshot struct {
+	columns [][]byte
+}
+
+func (c *dynamicStorage) snapshot(state any) any {
+	snapshot, _ := state.(*dynamicSnapshot)
+	if snapshot == nil {
+		snapshot = &dynamicSnapshot{}
+	}
+
+	snapshot.columns = resizeBuffers(snapshot.columns, len(c.columns))
+	for i, column := range c.columns {
+		snapshot.columns[i] = append(snapshot.columns[i][:0], column...)
+	}
+
+	return snapshot
+}
+
+func (c *dynamicStorage) restore(state any) {
+	snapshot, _ := state.(*dynamicSnapshot)
+	if snapshot == nil {
+		snapshot = &dynamicSnapshot{}
+	}
+
+	c.columns = restoreColumns(c.columns, snapshot.columns)
+}
@@ -0,0 +1,188 @@
+package volt
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// simulate applies the changes of a frame, depending only on the frame and the state of the World.
+func simulate(world *World, statsId ComponentId, frame int) {
+	random := rand.New(rand.NewPCG(uint64(frame), 0))
+
+	var entitiesIds []EntityId
+	for entityId := range world.Entities() {
+		entitiesIds = append(entitiesIds, entityId)
+	}
+
+	for _, entityId := range entitiesIds {
+		switch random.IntN(8) {
+		case 0:
+			world.RemoveEntity(entityId)
+		case 1:
+			if RemoveComponent[testComponent2](world, entityId) != nil {
+				AddComponent(world, entityId, testComponent2{testComponent{x: frame}})
+			}
+		case 2:
+			if RemoveComponent[testSparseComponent](world, entityId) != nil {
+				AddComponent(world, entityId, testSparseComponent{testComponent{y: frame}})
+			}
+		case 3:
+			if world.RemoveTag(TAG_1, entityId) != nil {
+				world.AddTag(TAG_1, entityId)
+			}
+		case 4:
+			if world.RemoveComponent(entityId, statsId) != nil {
+				world.AddDynamicComponent(entityId, statsId, nil)
+			}
+			SetDynamicField[float32](world, entityId, statsId, "health", float32(frame))
+		default:
+			if component := GetComponent[testComponent1](world, entityId); component != nil {
+				component.x += random.IntN(10)
+			}
+		}
+	}
+
+	for range random.IntN(6) {
+		entityId := world.CreateEntity()
+		AddComponent(world, entityId, testComponent1{testComponent{z: frame}})
+	}
+}
+
+// fingerprint describes the whole state of the World, including the next EntityId handed out.
+func fingerprint(world *World, statsId ComponentId) string {
+	var builder strings.Builder
+	for entityId := range world.Entities() {
+		fmt.Fprintf(&builder, "%d:", entityId)
+		if component := GetComponent[testComponent1](world, entityId); component != nil {
+			fmt.Fprintf(&builder, " 1%v", *component)
+		}
+		if component := GetComponent[testComponent2](world, entityId); component != nil {
+			fmt.Fprintf(&builder, " 2%v", *component)
+		}
+		if component := GetComponent[testSparseComponent](world, entityId); component != nil {
+			fmt.Fprintf(&builder, " s%v", *component)
+		}
+		if data, err := world.GetDynamicComponent(entityId, statsId); err == nil {
+			fmt.Fprintf(&builder, " d%v", data)
+		}
+		fmt.Fprintf(&builder, " t%v\n", world.HasTag(TAG_1, entityId))
+	}
+
+	query := CreateQuery2[testComponent1, testComponent2](world, QueryConfiguration{Tags: []TagId{TAG_1}})
+	fmt.Fprintf(&builder, "count %d, query %d %v, pool %v %d", world.Count(), query.Count(), query.GetEntitiesIds(), world.pool.ids, world.pool.next)
+
+	return builder.String()
+}
+
+func TestWorld_Snapshot_Determinism(t *testing.T) {
+	world := CreateWorld(64)
+	statsId := registerTestComponents(t, world)
+	world.SetSnapshotFrames(256)
+
+	const framesCount = 60
+	snapshots := make([]Snapshot, framesCount+1)
+	fingerprints := make([]string, framesCount+1)
+	for frame := 1; frame <= framesCount; frame++ {
+		snapshots[frame-1] = world.Snapshot()
+		simulate(world, statsId, frame)
+		fingerprints[frame] = fingerprint(world, statsId)
+	}
+
+	// Roll back to the state before each frame, and re-simulate the following frames.
+	for _, rollback := range []int{50, 5, 58, 30, 60} {
+		if err := world.Restore(snapshots[rollback-1]); err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		if rollback > 1 && fingerprint(world, statsId) != fingerprints[rollback-1] {
+			t.Fatalf("the state restored before the frame %d differs", rollback)
+		}
+
+		for frame := rollback; frame <= framesCount; frame++ {
+			snapshots[frame-1] = world.Snapshot()
+			simulate(world, statsId, frame)
+			if got := fingerprint(world, statsId); got != fingerprints[frame] {
+				t.Fatalf("the frame %d re-simulated after the rollback to %d differs:\n%s\nexpected:\n%s", frame, rollback, got, fingerprints[frame])
+			}
+		}
+	}
+}
+
+func TestWorld_Restore(t *testing.T) {
+	world := CreateWorld(64)
+	statsId := registerTestComponents(t, world)
+	world.SetSnapshotFrames(2)
+
+	if err := world.Restore(Snapshot{}); err == nil {
+		t.Errorf("Restore should reject a snapshot never taken")
+	}
+
+	entityRange, err := world.ReserveEntityRange(100, 110)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	world.CreateEntityInRange(entityRange)
+	snapshot := world.Snapshot()
+
+	entityId, _ := world.CreateEntityInRange(entityRange)
+	AddComponent(world, entityId, testComponent1{})
+	otherRange, _ := world.ReserveEntityRange(200, 210)
+	if err = world.Restore(snapshot); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if world.Exists(entityId) || world.Count() != 1 {
+		t.Errorf("the entity %d created after the snapshot should not exist", entityId)
+	}
+	if restoredId, err := world.CreateEntityInRange(entityRange); err != nil || restoredId != entityId {
+		t.Errorf("CreateEntityInRange should return the id %d again, got %d %v", entityId, restoredId, err)
+	}
+	if _, err = world.CreateEntityInRange(otherRange); err == nil {
+		t.Errorf("the range reserved after the snapshot should not be reserved anymore")
+	}
+
+	// A storage created after the snapshot is emptied.
+	snapshot = world.Snapshot()
+	RegisterComponent[testComponent3](world, &ComponentConfig[testComponent3]{})
+	AddComponent(world, entityId, testComponent3{})
+	world.AddDynamicComponent(entityId, statsId, nil)
+	world.Restore(snapshot)
+	if world.HasComponents(entityId, testComponent3Id) || world.HasComponents(entityId, statsId) || GetComponent[testComponent3](world, entityId) != nil {
+		t.Errorf("the components added after the snapshot should be removed")
+	}
+
+	world.Snapshot()
+	world.Snapshot()
+	if err = world.Restore(snapshot); err == nil {
+		t.Errorf("Restore should reject a snapshot overwritten")
+	}
+
+	snapshot = world.Snapshot()
+	world.RemoveEntity(entityId)
+	world.Compact()
+	if err = world.Restore(snapshot); err == nil {
+		t.Errorf("Restore should reject a snapshot taken before Compact")
+	}
+}
+
+func TestWorld_Snapshot_Allocations(t *testing.T) {
+	world := CreateWorld(64)
+	statsId := registerTestComponents(t, world)
+	for frame := range 20 {
+		simulate(world, statsId, frame)
+	}
+
+	world.SetSnapshotFrames(4)
+	for range 4 {
+		world.Snapshot()
+	}
+	snapshot := world.Snapshot()
+
+	allocations := testing.AllocsPerRun(100, func() {
+		world.Restore(snapshot)
+		snapshot = world.Snapshot()
+	})
+	if allocations != 0 {
+		t.Errorf("Snapshot and Restore should not allocate once the buffers are grown, got %v allocations", allocations)
+	}
+}
@@ -0,0 +1,146 @@
+package volt
+
+import (
+	"iter"
+	"slices"
+)
+
+// sortResults appends the results of seq to results, and sorts them in the order of less.
+// The results with an equal order keep the order of seq.
+func sortResults[R any](results []R, seq iter.Seq[R], less func(a, b R) bool) []R {
+	for result := range seq {
+		results = append(results, result)
+	}
+
+	slices.SortStableFunc(results, func(a, b R) int {
+		if less(a, b) {
+			return -1
+		}
+		if less(b, a) {
+			return 1
+		}
+		return 0
+	})
+
+	return results
+}
+
+// sortRows reorders the rows of the archetypes so that their entities follow the order of results,
+// e.g. the results of a query once sorted. The rows of the entities absent from results follow, in their order.
+//
+// The components are moved within their columns: the order holds until the next structural change of the archetypes.
+// A deterministic World keeps its rows in the order of their EntityId, see SetDeterministic.
+func sortRows[R any](world *World, results []R, entityIdFn func(R) EntityId) {
+	world.guardWorldChange()
+	if world.deterministic {
+		return
+	}
+
+	permutations := make(map[archetypeId][]int)
+	var archetypesIds []archetypeId
+	for _, result := range results {
+		entityRecord := world.entities.get(entityIdFn(result))
+		if _, ok := permutations[entityRecord.archetypeId]; !ok {
+			archetypesIds = append(archetypesIds, entityRecord.archetypeId)
+		}
+		permutations[entityRecord.archetypeId] = append(permutations[entityRecord.archetypeId], entityRecord.key)
+	}
+
+	var visited []bool
+	for _, archetypeId := range archetypesIds {
+		archetype := &world.archetypes[archetypeId]
+		permutation := permutations[archetypeId]
+
+		visited = resizeVisited(visited, len(archetype.entities))
+		for _, key := range permutation {
+			visited[key] = true
+		}
+		for key := range archetype.entities {
+			if !visited[key] {
+				permutation = append(permutation, key)
+			}
+		}
+
+		world.permuteArchetype(archetype, permutation, visited)
+	}
+}
+
+// permuteArchetype reorders the rows of the archetype, so that the row i receives the row permutation[i],
+// and updates the keys of its entities.
+func (world *World) permuteArchetype(archetype *archetype, permutation []int, visited []bool) {
+	for _, componentId := range archetype.Type {
+		if componentId < TAGS_INDICES && world.storage[componentId] != nil {
+			world.storage[componentId].permute(archetype.Id, permutation, visited)
+		}
+	}
+	permuteColumn(archetype.entities, permutation, visited)
+
+	for key, entityId := range archetype.entities {
+		world.entities.at(entityId).key = key
+	}
+}
+
+func resizeVisited(visited []bool, length int) []bool {
+	if cap(visited) < length {
+		return make([]bool, length)
+	}
+
+	visited = visited[:length]
+	clear(visited)
+
+	return visited
+}
+
+// permuteColumn reorders the column in place, so that the row i receives the row permutation[i],
+// by following the cycles of the permutation. visited is a buffer of the length of the column.
+func permuteColumn[T any](column []T, permutation []int, visited []bool) {
+	clear(visited)
+	for start := range column {
+		if visited[start] {
+			continue
+		}
+
+		value := column[start]
+		i := start
+		for {
+			visited[i] = true
+			next := permutation[i]
+			if next == start {
+				column[i] = value
+				break
+			}
+			column[i] = column[next]
+			i = next
+		}
+	}
+}
+
+func (c *ComponentsStorage[T]) permute(archetypeId archetypeId, permutation []int, visited []bool) {
+	permuteColumn(c.getColumn(archetypeId), permutation, visited)
+}
+
+// permute reorders the rows of the column of bytes, as permuteColumn.
+func (c *dynamicStorage) permute(archetypeId archetypeId, permutation []int, visited []bool) {
+	column := c.getColumn(archetypeId)
+	value := make([]byte, c.stride)
+
+	clear(visited)
+	for start := range permutation {
+		if visited[start] {
+			continue
+		}
+
+		copy(value, c.row(archetypeId, start))
+		i := start
+		for {
+			visited[i] = true
+			next := permutation[i]
+			if next == start {
+				copy(column[i*c.stride:(i+1)*c.stride], value)
+				break
+			}
+			copy(column[i*c.stride:(i+1)*c.stride], column[next*c.stride:(next+1)*c.stride])
+			i = next
+		}
+	}
+}
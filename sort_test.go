@@ -0,0 +1,127 @@
+package volt
+
+import (
+	"slices"
+	"testing"
+)
+
+// addSortEntities creates entities with a depth in a shuffled order, half of them with testComponent2.
+func addSortEntities(world *World, statsId ComponentId) {
+	for i := range 20 {
+		depth := (i * 7) % 20
+		entityId := world.CreateEntity()
+		AddComponent(world, entityId, testComponent1{testComponent{x: depth}})
+		AddComponent(world, entityId, testSparseComponent{testComponent{x: depth}})
+		world.AddDynamicComponent(entityId, statsId, nil)
+		SetDynamicField[float32](world, entityId, statsId, "health", float32(depth))
+		if i%2 == 0 {
+			AddComponent(world, entityId, testComponent2{testComponent{x: depth}})
+		}
+	}
+}
+
+func TestQuery2_ForeachSorted(t *testing.T) {
+	world := CreateWorld(16)
+	statsId := registerTestComponents(t, world)
+	addSortEntities(world, statsId)
+	query := CreateQuery2[testComponent1, testComponent2](world, QueryConfiguration{OptionalComponents: []OptionalComponent{testComponent2Id}})
+
+	for range 2 {
+		var depths []int
+		for result := range query.ForeachSorted(func(a, b QueryResult2[testComponent1, testComponent2]) bool {
+			return a.A.x > b.A.x
+		}) {
+			depths = append(depths, result.A.x)
+			if (result.B != nil) != (result.A.x%2 == 0) {
+				t.Errorf("the optional component of the entity %d should be set only when it has one, got %v", result.EntityId, result.B)
+			}
+			if result.B != nil && result.B.x != result.A.x {
+				t.Errorf("the components of the entity %d should be of the same entity, got %d and %d", result.EntityId, result.A.x, result.B.x)
+			}
+		}
+
+		if len(depths) != 20 || !slices.IsSortedFunc(depths, func(a, b int) int { return b - a }) {
+			t.Errorf("the results should be sorted back to front, got %v", depths)
+		}
+	}
+
+	// The results point to the components of the World, not to copies.
+	for result := range query.ForeachSorted(func(a, b QueryResult2[testComponent1, testComponent2]) bool {
+		return a.A.x < b.A.x
+	}) {
+		result.A.y = result.A.x
+	}
+	for result := range query.Foreach(nil) {
+		if result.A.y != result.A.x {
+			t.Errorf("the component of the entity %d should be modified through ForeachSorted", result.EntityId)
+		}
+	}
+
+	// The entities with an equal order keep the order of Foreach.
+	var expected []EntityId
+	for result := range query.Foreach(nil) {
+		expected = append(expected, result.EntityId)
+	}
+	var entitiesIds []EntityId
+	for result := range query.ForeachSorted(func(a, b QueryResult2[testComponent1, testComponent2]) bool { return false }) {
+		entitiesIds = append(entitiesIds, result.EntityId)
+	}
+	if !slices.Equal(entitiesIds, expected) {
+		t.Errorf("the sort should be stable, expected %v, got %v", expected, entitiesIds)
+	}
+
+	// The iteration can be stopped early.
+	count := 0
+	for range query.ForeachSorted(func(a, b QueryResult2[testComponent1, testComponent2]) bool { return false }) {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("the iteration should stop after the first result, got %d", count)
+	}
+}
+
+func TestQuery1_SortArchetypes(t *testing.T) {
+	world := CreateWorld(16)
+	statsId := registerTestComponents(t, world)
+	addSortEntities(world, statsId)
+	query := CreateQuery1[testComponent1](world, QueryConfiguration{})
+
+	query.SortArchetypes(func(a, b QueryResult1[testComponent1]) bool {
+		return a.A.x < b.A.x
+	})
+
+	for _, archetypeId := range query.filter() {
+		column := getStorage[testComponent1](world).getColumn(archetypeId)
+		if !slices.IsSortedFunc(column, func(a, b testComponent1) int { return a.x - b.x }) {
+			t.Errorf("the column of the archetype %d should be sorted, got %v", archetypeId, column)
+		}
+	}
+
+	// Every component still belongs to its entity.
+	for result := range query.Foreach(nil) {
+		entityId := result.EntityId
+		depth := result.A.x
+		if component := GetComponent[testSparseComponent](world, entityId); component == nil || component.x != depth {
+			t.Errorf("the sparse component of the entity %d should be unchanged, got %v", entityId, component)
+		}
+		if component := GetComponent[testComponent2](world, entityId); component != nil && component.x != depth {
+			t.Errorf("the component testComponent2 of the entity %d should be moved along, got %v", entityId, component)
+		}
+		if health, err := GetDynamicField[float32](world, entityId, statsId, "health"); err != nil || health != float32(depth) {
+			t.Errorf("the dynamic component of the entity %d should be moved along, got %v %v", entityId, health, err)
+		}
+		if component := GetComponent[testComponent1](world, entityId); component != result.A {
+			t.Errorf("the record of the entity %d should point to its new row", entityId)
+		}
+	}
+
+	// The structural changes keep working on the sorted rows.
+	world.RemoveEntity(1)
+	if err := RemoveComponent[testComponent2](world, 2); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if query.Count() != 19 || GetComponent[testComponent1](world, 2).x != 14 {
+		t.Errorf("the entities should be consistent after a structural change, got %d entities", query.Count())
+	}
+}
@@ -0,0 +1,122 @@
+package spatial
+
+import (
+	"math"
+
+	"github.com/akmonengine/volt"
+)
+
+// Grid is a Partition dividing the space in cubic cells of the same size, created as the entities fill them.
+//
+// The cells should be about the size of the queries: a query visits all the cells its Box overlaps.
+type Grid struct {
+	cellSize float64
+	cells    map[cell][]item
+	entities map[volt.EntityId]cell
+}
+
+type cell struct {
+	x, y, z int64
+}
+
+// NewGrid returns an empty Grid of cells of cellSize on each axis.
+func NewGrid(cellSize float64) *Grid {
+	return &Grid{
+		cellSize: cellSize,
+		cells:    make(map[cell][]item),
+		entities: make(map[volt.EntityId]cell),
+	}
+}
+
+func (grid *Grid) cellOf(position Vec3) cell {
+	return cell{
+		x: int64(math.Floor(position.X / grid.cellSize)),
+		y: int64(math.Floor(position.Y / grid.cellSize)),
+		z: int64(math.Floor(position.Z / grid.cellSize)),
+	}
+}
+
+func (grid *Grid) Insert(entityId volt.EntityId, position Vec3) {
+	c := grid.cellOf(position)
+	if current, ok := grid.entities[entityId]; ok {
+		if current == c {
+			items := grid.cells[c]
+			for i := range items {
+				if items[i].entityId == entityId {
+					items[i].position = position
+					break
+				}
+			}
+
+			return
+		}
+		grid.removeFromCell(current, entityId)
+	}
+
+	grid.cells[c] = append(grid.cells[c], item{entityId: entityId, position: position})
+	grid.entities[entityId] = c
+}
+
+func (grid *Grid) Remove(entityId volt.EntityId) {
+	if c, ok := grid.entities[entityId]; ok {
+		grid.removeFromCell(c, entityId)
+		delete(grid.entities, entityId)
+	}
+}
+
+func (grid *Grid) removeFromCell(c cell, entityId volt.EntityId) {
+	items := removeItem(grid.cells[c], entityId)
+	if len(items) == 0 {
+		delete(grid.cells, c)
+	} else {
+		grid.cells[c] = items
+	}
+}
+
+func (grid *Grid) Query(box Box, fn func(entityId volt.EntityId, position Vec3) bool) {
+	minCell, maxCell := grid.cellOf(box.Min), grid.cellOf(box.Max)
+
+	// A Box larger than the cells filled visits these cells rather than all the cells it overlaps.
+	cellsCount := float64(maxCell.x-minCell.x+1) * float64(maxCell.y-minCell.y+1) * float64(maxCell.z-minCell.z+1)
+	if cellsCount > float64(len(grid.cells)) {
+		for c, items := range grid.cells {
+			if c.x >= minCell.x && c.x <= maxCell.x && c.y >= minCell.y && c.y <= maxCell.y && c.z >= minCell.z && c.z <= maxCell.z {
+				if !queryItems(items, box, fn) {
+					return
+				}
+			}
+		}
+
+		return
+	}
+
+	for x := minCell.x; x <= maxCell.x; x++ {
+		for y := minCell.y; y <= maxCell.y; y++ {
+			for z := minCell.z; z <= maxCell.z; z++ {
+				if !queryItems(grid.cells[cell{x: x, y: y, z: z}], box, fn) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// queryItems calls fn with the items in the box, and returns false once fn does.
+func queryItems(items []item, box Box, fn func(entityId volt.EntityId, position Vec3) bool) bool {
+	for _, item := range items {
+		if box.Contains(item.position) && !fn(item.entityId, item.position) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (grid *Grid) Len() int {
+	return len(grid.entities)
+}
+
+func (grid *Grid) Clear() {
+	clear(grid.cells)
+	clear(grid.entities)
+}
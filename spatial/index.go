@@ -0,0 +1,194 @@
+package spatial
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	"github.com/akmonengine/volt"
+)
+
+// Matcher restricts the entities found by an Index. It is implemented by the volt queries, e.g. *volt.Query2[A, B],
+// so that only the entities with their components and tags are found.
+type Matcher interface {
+	Matches(entityId volt.EntityId) bool
+}
+
+// Index keeps a Partition of the entities owning the component T, at the position returned by positionFn.
+type Index[T volt.ComponentInterface] struct {
+	world      *volt.World
+	partition  Partition
+	positionFn func(component T) Vec3
+	query      volt.Query1[T]
+
+	// entries are the positions indexed, and the Sync which last saw them.
+	entries map[volt.EntityId]entry
+	sync    uint64
+}
+
+type entry struct {
+	position Vec3
+	sync     uint64
+}
+
+// NewIndex returns an Index of the entities of world owning the component T, stored in partition.
+//
+// The Index is filled by Sync, or entity by entity with Update.
+func NewIndex[T volt.ComponentInterface](world *volt.World, partition Partition, positionFn func(component T) Vec3) *Index[T] {
+	partition.Clear()
+
+	return &Index[T]{
+		world:      world,
+		partition:  partition,
+		positionFn: positionFn,
+		query:      volt.CreateQuery1[T](world, volt.QueryConfiguration{}),
+		entries:    make(map[volt.EntityId]entry),
+	}
+}
+
+// Sync detects the changes of the components T since the last Sync: the entities moved are moved in the Partition,
+// and the entities removed, or without the component T anymore, are removed from it.
+//
+// It iterates all the components T, and is meant to be called once per frame, e.g. after the movements are applied.
+// It sees all the changes, including those made through the pointers of the queries, Clear or Restore.
+func (index *Index[T]) Sync() {
+	index.sync++
+	seen := 0
+	for result := range index.query.Foreach(nil) {
+		index.update(result.EntityId, *result.A)
+		seen++
+	}
+
+	if seen < len(index.entries) {
+		for entityId, e := range index.entries {
+			if e.sync != index.sync {
+				index.Remove(entityId)
+			}
+		}
+	}
+}
+
+// Update indexes the entity at the position of its component T, or removes it from the Index if it does not own it.
+//
+// It is meant to be called from the callbacks of the World, or after a component is changed, to keep the Index
+// up to date between two calls of Sync.
+func (index *Index[T]) Update(entityId volt.EntityId) {
+	component := volt.GetComponent[T](index.world, entityId)
+	if component == nil {
+		index.Remove(entityId)
+		return
+	}
+
+	index.update(entityId, *component)
+}
+
+func (index *Index[T]) update(entityId volt.EntityId, component T) {
+	position := index.positionFn(component)
+	e, ok := index.entries[entityId]
+	if !ok || e.position != position {
+		index.partition.Insert(entityId, position)
+	}
+
+	index.entries[entityId] = entry{position: position, sync: index.sync}
+}
+
+// Remove removes the entity from the Index, e.g. from the callback of SetEntityRemovedFn.
+func (index *Index[T]) Remove(entityId volt.EntityId) {
+	if _, ok := index.entries[entityId]; ok {
+		index.partition.Remove(entityId)
+		delete(index.entries, entityId)
+	}
+}
+
+// Position returns the position indexed for the entity, or false if it is not indexed.
+func (index *Index[T]) Position(entityId volt.EntityId) (Vec3, bool) {
+	e, ok := index.entries[entityId]
+
+	return e.position, ok
+}
+
+// Len returns the number of entities indexed.
+func (index *Index[T]) Len() int {
+	return len(index.entries)
+}
+
+// InBox returns an iterator of the entities in the box, matched by matcher if it is not nil, in no specific order.
+func (index *Index[T]) InBox(box Box, matcher Matcher) iter.Seq[volt.EntityId] {
+	return func(yield func(volt.EntityId) bool) {
+		index.partition.Query(box, func(entityId volt.EntityId, position Vec3) bool {
+			if matcher != nil && !matcher.Matches(entityId) {
+				return true
+			}
+
+			return yield(entityId)
+		})
+	}
+}
+
+// Within returns an iterator of the entities within radius of center, matched by matcher if it is not nil, in no specific order.
+func (index *Index[T]) Within(center Vec3, radius float64, matcher Matcher) iter.Seq[volt.EntityId] {
+	return func(yield func(volt.EntityId) bool) {
+		radiusSquared := radius * radius
+		index.partition.Query(BoxAround(center, radius), func(entityId volt.EntityId, position Vec3) bool {
+			if position.distanceSquared(center) > radiusSquared || (matcher != nil && !matcher.Matches(entityId)) {
+				return true
+			}
+
+			return yield(entityId)
+		})
+	}
+}
+
+// neighbour is an entity found by Nearest.
+type neighbour struct {
+	entityId        volt.EntityId
+	distanceSquared float64
+}
+
+// Nearest returns the k entities nearest to center within maxDistance, matched by matcher if it is not nil,
+// by ascending distance then EntityId. maxDistance may be math.Inf(1).
+//
+// The search starts around center, and widens until k entities are found.
+func (index *Index[T]) Nearest(center Vec3, k int, maxDistance float64, matcher Matcher) []volt.EntityId {
+	if k <= 0 || index.Len() == 0 {
+		return nil
+	}
+
+	var neighbours []neighbour
+	maxDistanceSquared := maxDistance * maxDistance
+	for radius := min(1, maxDistance); ; radius = min(2*radius, maxDistance) {
+		radiusSquared := radius * radius
+
+		neighbours = neighbours[:0]
+		visited, within := 0, 0
+		index.partition.Query(BoxAround(center, radius), func(entityId volt.EntityId, position Vec3) bool {
+			visited++
+			distanceSquared := position.distanceSquared(center)
+			if distanceSquared <= maxDistanceSquared && (matcher == nil || matcher.Matches(entityId)) {
+				neighbours = append(neighbours, neighbour{entityId: entityId, distanceSquared: distanceSquared})
+				if distanceSquared <= radiusSquared {
+					within++
+				}
+			}
+
+			return true
+		})
+
+		// The entities found in the corners of the box, beyond radius, may be farther than those outside the box:
+		// the k nearest are only known once found within radius, or once all the entities are visited.
+		if within >= k || radius >= maxDistance || visited == index.Len() {
+			break
+		}
+	}
+
+	slices.SortFunc(neighbours, func(a, b neighbour) int {
+		return cmp.Or(cmp.Compare(a.distanceSquared, b.distanceSquared), cmp.Compare(a.entityId, b.entityId))
+	})
+
+	entities := make([]volt.EntityId, 0, min(k, len(neighbours)))
+	for _, n := range neighbours[:min(k, len(neighbours))] {
+		entities = append(entities, n.entityId)
+	}
+
+	return entities
+}
@@ -0,0 +1,152 @@
+package spatial
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/akmonengine/volt"
+)
+
+const enemyTag = volt.TAGS_INDICES + 1
+
+type positionComponent struct {
+	X, Y float64
+}
+
+func (p positionComponent) GetComponentId() volt.ComponentId {
+	return volt.AUTO_COMPONENT_ID
+}
+
+type healthComponent struct {
+	Value int
+}
+
+func (h healthComponent) GetComponentId() volt.ComponentId {
+	return volt.AUTO_COMPONENT_ID
+}
+
+func position(component positionComponent) Vec3 {
+	return Vec3{X: component.X, Y: component.Y}
+}
+
+func createWorld(t *testing.T, random *rand.Rand) *volt.World {
+	world := volt.CreateWorld(256)
+	if err := volt.RegisterComponent[positionComponent](world, &volt.ComponentConfig[positionComponent]{}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := volt.RegisterComponent[healthComponent](world, &volt.ComponentConfig[healthComponent]{}); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	for i := range 200 {
+		entityId := world.CreateEntity()
+		volt.AddComponent(world, entityId, positionComponent{X: random.Float64() * 100, Y: random.Float64() * 100})
+		if i%2 == 0 {
+			volt.AddComponent(world, entityId, healthComponent{Value: 10})
+		}
+		if i%3 == 0 {
+			world.AddTag(enemyTag, entityId)
+		}
+	}
+
+	return world
+}
+
+// within returns the entities within radius of center, matched by the query, by brute force.
+func within(world *volt.World, center Vec3, radius float64, matcher Matcher) []volt.EntityId {
+	var entities []volt.EntityId
+	for entityId := range world.Entities() {
+		component := volt.GetComponent[positionComponent](world, entityId)
+		if component != nil && position(*component).distanceSquared(center) <= radius*radius && matcher.Matches(entityId) {
+			entities = append(entities, entityId)
+		}
+	}
+
+	return entities
+}
+
+func TestIndex(t *testing.T) {
+	for name, partition := range createPartitions() {
+		t.Run(name, func(t *testing.T) {
+			random := rand.New(rand.NewPCG(3, 4))
+			world := createWorld(t, random)
+			enemies := volt.CreateQuery2[positionComponent, healthComponent](world, volt.QueryConfiguration{Tags: []volt.TagId{enemyTag}})
+
+			index := NewIndex(world, partition, position)
+			index.Sync()
+			if index.Len() != 200 {
+				t.Fatalf("the index should hold the 200 entities, got %d", index.Len())
+			}
+
+			for frame := range 20 {
+				// The entities move through the pointers of a query: Sync detects it.
+				query := volt.CreateQuery1[positionComponent](world, volt.QueryConfiguration{})
+				for result := range query.Foreach(nil) {
+					result.A.X += random.Float64()*4 - 2
+					result.A.Y += random.Float64()*4 - 2
+				}
+				if frame%5 == 0 {
+					world.RemoveEntity(volt.EntityId(frame))
+					volt.RemoveComponent[positionComponent](world, volt.EntityId(frame+1))
+				}
+				index.Sync()
+
+				center := Vec3{X: random.Float64() * 100, Y: random.Float64() * 100}
+				got := slices.Sorted(index.Within(center, 20, &enemies))
+				if expected := within(world, center, 20, &enemies); !slices.Equal(got, expected) {
+					t.Fatalf("the enemies within 20 of %v should be %v, got %v", center, expected, got)
+				}
+
+				nearest := index.Nearest(center, 5, 1000, &enemies)
+				expected := within(world, center, 1000, &enemies)
+				slices.SortFunc(expected, func(a, b volt.EntityId) int {
+					distanceA := position(*volt.GetComponent[positionComponent](world, a)).distanceSquared(center)
+					distanceB := position(*volt.GetComponent[positionComponent](world, b)).distanceSquared(center)
+					return cmp.Or(cmp.Compare(distanceA, distanceB), cmp.Compare(a, b))
+				})
+				if !slices.Equal(nearest, expected[:5]) {
+					t.Fatalf("the 5 enemies nearest to %v should be %v, got %v", center, expected[:5], nearest)
+				}
+			}
+
+			if _, ok := index.Position(0); ok {
+				t.Errorf("the entity removed should not be indexed anymore")
+			}
+			if nearest := index.Nearest(Vec3{}, 3, 0.001, nil); len(nearest) != 0 {
+				t.Errorf("Nearest should not find entities beyond maxDistance, got %v", nearest)
+			}
+		})
+	}
+}
+
+func TestIndex_Update(t *testing.T) {
+	world := volt.CreateWorld(16)
+	volt.RegisterComponent[positionComponent](world, &volt.ComponentConfig[positionComponent]{})
+	index := NewIndex(world, NewGrid(10), position)
+
+	// The callbacks of the World keep the index up to date between two Sync.
+	world.SetComponentAddedFn(func(entityId volt.EntityId, componentId volt.ComponentId) { index.Update(entityId) })
+	world.SetEntityRemovedFn(index.Remove)
+
+	entityId := world.CreateEntity()
+	volt.AddComponent(world, entityId, positionComponent{X: 5, Y: 5})
+	if got := slices.Collect(index.Within(Vec3{X: 5, Y: 5}, 1, nil)); !slices.Equal(got, []volt.EntityId{entityId}) {
+		t.Errorf("the entity should be indexed once its component added, got %v", got)
+	}
+
+	volt.SetComponent(world, entityId, positionComponent{X: 50, Y: 50})
+	index.Update(entityId)
+	if position, _ := index.Position(entityId); position != (Vec3{X: 50, Y: 50}) {
+		t.Errorf("the entity should be moved by Update, got %v", position)
+	}
+	if got := slices.Collect(index.InBox(Box{Min: Vec3{X: 40, Y: 40}, Max: Vec3{X: 60, Y: 60}}, nil)); !slices.Equal(got, []volt.EntityId{entityId}) {
+		t.Errorf("the entity should be found at its new position, got %v", got)
+	}
+
+	world.RemoveEntity(entityId)
+	if index.Len() != 0 {
+		t.Errorf("the entity should be removed from the index with the callback")
+	}
+}
@@ -0,0 +1,103 @@
+package spatial
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/akmonengine/volt"
+)
+
+func createPartitions() map[string]Partition {
+	bounds := Box{Min: Vec3{X: -100, Y: -100, Z: -100}, Max: Vec3{X: 100, Y: 100, Z: 100}}
+
+	return map[string]Partition{
+		"grid":     NewGrid(10),
+		"quadtree": NewQuadtree(bounds),
+		"octree":   NewOctree(bounds),
+	}
+}
+
+func randomPosition(random *rand.Rand, flat bool) Vec3 {
+	// Some positions are out of the bounds of the trees.
+	position := Vec3{X: random.Float64()*240 - 120, Y: random.Float64()*240 - 120}
+	if !flat {
+		position.Z = random.Float64()*240 - 120
+	}
+
+	return position
+}
+
+func queryAll(partition Partition, box Box) []volt.EntityId {
+	var entities []volt.EntityId
+	partition.Query(box, func(entityId volt.EntityId, position Vec3) bool {
+		entities = append(entities, entityId)
+		return true
+	})
+	slices.Sort(entities)
+
+	return entities
+}
+
+func TestPartition(t *testing.T) {
+	for name, partition := range createPartitions() {
+		t.Run(name, func(t *testing.T) {
+			random := rand.New(rand.NewPCG(1, 2))
+			flat := name == "quadtree"
+			positions := make(map[volt.EntityId]Vec3)
+
+			for step := range 5000 {
+				entityId := volt.EntityId(random.IntN(500))
+				switch random.IntN(4) {
+				case 0:
+					partition.Remove(entityId)
+					delete(positions, entityId)
+				case 1:
+					// A small move, within the loose bounds of its node.
+					if position, ok := positions[entityId]; ok {
+						position.X += random.Float64() - 0.5
+						partition.Insert(entityId, position)
+						positions[entityId] = position
+					}
+				default:
+					position := randomPosition(random, flat)
+					partition.Insert(entityId, position)
+					positions[entityId] = position
+				}
+
+				if step%100 != 0 {
+					continue
+				}
+				if partition.Len() != len(positions) {
+					t.Fatalf("the partition should hold %d entities, got %d", len(positions), partition.Len())
+				}
+
+				box := BoxAround(randomPosition(random, flat), random.Float64()*60)
+				var expected []volt.EntityId
+				for entityId, position := range positions {
+					if box.Contains(position) {
+						expected = append(expected, entityId)
+					}
+				}
+				slices.Sort(expected)
+				if got := queryAll(partition, box); !slices.Equal(got, expected) {
+					t.Fatalf("the query of %v should find %v, got %v", box, expected, got)
+				}
+			}
+
+			found := 0
+			partition.Query(BoxAround(Vec3{}, 1000), func(entityId volt.EntityId, position Vec3) bool {
+				found++
+				return found < 3
+			})
+			if found != 3 {
+				t.Errorf("the query should stop once fn returns false, got %d entities", found)
+			}
+
+			partition.Clear()
+			if partition.Len() != 0 || len(queryAll(partition, BoxAround(Vec3{}, 1000))) != 0 {
+				t.Errorf("the partition should be empty once cleared")
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Package spatial indexes the entities of a World by the position held by one of their components,
+// for the range and nearest-neighbour queries such as "all the enemies within 10 m".
+//
+// The positions are stored in a Partition: a uniform Grid, suited to the entities spread over a bounded area,
+// or a loose Tree (a quadtree in 2D, an octree in 3D), adapting to the clusters of entities.
+// An Index keeps a Partition in sync with the components of a World, either by detecting the positions changed
+// at each Sync, or by being notified of each change, e.g. from the callbacks of the World.
+// The entities found can be restricted to those matched by a volt query.
+package spatial
+
+import (
+	"github.com/akmonengine/volt"
+)
+
+// Vec3 is a position. The 2D positions leave Z to 0.
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+func (v Vec3) distanceSquared(other Vec3) float64 {
+	x, y, z := v.X-other.X, v.Y-other.Y, v.Z-other.Z
+
+	return x*x + y*y + z*z
+}
+
+// Box is an axis-aligned box, from Min to Max inclusive.
+type Box struct {
+	Min, Max Vec3
+}
+
+// BoxAround returns the Box centered on center, extending by radius on each axis.
+func BoxAround(center Vec3, radius float64) Box {
+	return Box{
+		Min: Vec3{X: center.X - radius, Y: center.Y - radius, Z: center.Z - radius},
+		Max: Vec3{X: center.X + radius, Y: center.Y + radius, Z: center.Z + radius},
+	}
+}
+
+// Contains reports whether position is in the Box.
+func (box Box) Contains(position Vec3) bool {
+	return position.X >= box.Min.X && position.X <= box.Max.X &&
+		position.Y >= box.Min.Y && position.Y <= box.Max.Y &&
+		position.Z >= box.Min.Z && position.Z <= box.Max.Z
+}
+
+func (box Box) intersects(other Box) bool {
+	return box.Min.X <= other.Max.X && box.Max.X >= other.Min.X &&
+		box.Min.Y <= other.Max.Y && box.Max.Y >= other.Min.Y &&
+		box.Min.Z <= other.Max.Z && box.Max.Z >= other.Min.Z
+}
+
+// Partition stores the positions of entities, keyed by EntityId, for the queries by Box.
+//
+// It is implemented by Grid and Tree.
+type Partition interface {
+	// Insert adds the entity at position, or moves it there if it is already in the Partition.
+	Insert(entityId volt.EntityId, position Vec3)
+	// Remove removes the entity, if it is in the Partition.
+	Remove(entityId volt.EntityId)
+	// Query calls fn with the entities whose position is in the box, in no specific order, until fn returns false.
+	Query(box Box, fn func(entityId volt.EntityId, position Vec3) bool)
+	// Len returns the number of entities in the Partition.
+	Len() int
+	// Clear removes all the entities.
+	Clear()
+}
+
+// item is an entity stored in a Partition.
+type item struct {
+	entityId volt.EntityId
+	position Vec3
+}
+
+// removeItem removes the entity from items, by moving the last item to its position.
+func removeItem(items []item, entityId volt.EntityId) []item {
+	for i := range items {
+		if items[i].entityId == entityId {
+			items[i] = items[len(items)-1]
+			return items[:len(items)-1]
+		}
+	}
+
+	return items
+}
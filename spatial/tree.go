@@ -0,0 +1,250 @@
+package spatial
+
+import (
+	"math"
+
+	"github.com/akmonengine/volt"
+)
+
+// TREE_NODE_CAPACITY is the number of entities a node of a Tree holds before it is divided.
+const TREE_NODE_CAPACITY = 16
+
+// TREE_MAX_DEPTH is the depth beyond which the nodes of a Tree are not divided anymore.
+const TREE_MAX_DEPTH = 12
+
+// Tree is a Partition dividing the space recursively where the entities are dense:
+// a quadtree dividing X and Y, or an octree dividing X, Y and Z.
+//
+// The tree is loose: a node holds the entities within twice its bounds, so that an entity moving
+// around the border of a node stays in it rather than being moved to its neighbour.
+// The entities outside the bounds of the Tree are held by its root.
+type Tree struct {
+	dimensions int
+	root       *node
+	nodes      map[volt.EntityId]*node
+}
+
+type node struct {
+	center   Vec3
+	halfSize Vec3
+	depth    int
+	parent   *node
+	children []*node
+	items    []item
+	// count is the number of entities held by the node and its descendants.
+	count int
+}
+
+// NewQuadtree returns an empty Tree over bounds, dividing X and Y.
+func NewQuadtree(bounds Box) *Tree {
+	return newTree(bounds, 2)
+}
+
+// NewOctree returns an empty Tree over bounds, dividing X, Y and Z.
+func NewOctree(bounds Box) *Tree {
+	return newTree(bounds, 3)
+}
+
+func newTree(bounds Box, dimensions int) *Tree {
+	root := &node{
+		center:   Vec3{X: (bounds.Min.X + bounds.Max.X) / 2, Y: (bounds.Min.Y + bounds.Max.Y) / 2, Z: (bounds.Min.Z + bounds.Max.Z) / 2},
+		halfSize: Vec3{X: (bounds.Max.X - bounds.Min.X) / 2, Y: (bounds.Max.Y - bounds.Min.Y) / 2, Z: (bounds.Max.Z - bounds.Min.Z) / 2},
+	}
+	// A quadtree does not divide Z: its nodes span the whole axis.
+	if dimensions == 2 {
+		root.center.Z = 0
+		root.halfSize.Z = math.Inf(1)
+	}
+
+	return &Tree{
+		dimensions: dimensions,
+		root:       root,
+		nodes:      make(map[volt.EntityId]*node),
+	}
+}
+
+// bounds returns the bounds of the node, extended by looseness times its half size.
+func (n *node) bounds(looseness float64) Box {
+	return Box{
+		Min: Vec3{X: n.center.X - looseness*n.halfSize.X, Y: n.center.Y - looseness*n.halfSize.Y, Z: n.center.Z - looseness*n.halfSize.Z},
+		Max: Vec3{X: n.center.X + looseness*n.halfSize.X, Y: n.center.Y + looseness*n.halfSize.Y, Z: n.center.Z + looseness*n.halfSize.Z},
+	}
+}
+
+// looseBounds returns the bounds of the entities the node may hold.
+func (n *node) looseBounds() Box {
+	return n.bounds(2)
+}
+
+// childIndex returns the child whose bounds contain position.
+func (n *node) childIndex(position Vec3) int {
+	index := 0
+	if position.X >= n.center.X {
+		index |= 1
+	}
+	if position.Y >= n.center.Y {
+		index |= 2
+	}
+	if len(n.children) == 8 && position.Z >= n.center.Z {
+		index |= 4
+	}
+
+	return index
+}
+
+func (tree *Tree) Insert(entityId volt.EntityId, position Vec3) {
+	if n, ok := tree.nodes[entityId]; ok {
+		// The entity stays in its node while it is within its loose bounds.
+		if n == tree.root || n.looseBounds().Contains(position) {
+			for i := range n.items {
+				if n.items[i].entityId == entityId {
+					n.items[i].position = position
+					break
+				}
+			}
+
+			return
+		}
+		tree.Remove(entityId)
+	}
+
+	tree.insert(tree.root, item{entityId: entityId, position: position})
+}
+
+// insert adds the item to the deepest node of n whose bounds contain its position.
+func (tree *Tree) insert(n *node, it item) {
+	for {
+		n.count++
+		if n.children == nil || !n.bounds(1).Contains(it.position) {
+			break
+		}
+		n = n.children[n.childIndex(it.position)]
+	}
+
+	n.items = append(n.items, it)
+	tree.nodes[it.entityId] = n
+
+	if n.children == nil && len(n.items) > TREE_NODE_CAPACITY && n.depth < TREE_MAX_DEPTH {
+		tree.divide(n)
+	}
+}
+
+// divide creates the children of n, and moves them its items within its bounds.
+func (tree *Tree) divide(n *node) {
+	childrenCount := 4
+	if tree.dimensions == 3 {
+		childrenCount = 8
+	}
+
+	n.children = make([]*node, childrenCount)
+	halfSize := Vec3{X: n.halfSize.X / 2, Y: n.halfSize.Y / 2, Z: n.halfSize.Z}
+	if tree.dimensions == 3 {
+		halfSize.Z /= 2
+	}
+	for i := range n.children {
+		center := n.center
+		center.X += sign(i&1 != 0) * halfSize.X
+		center.Y += sign(i&2 != 0) * halfSize.Y
+		if tree.dimensions == 3 {
+			center.Z += sign(i&4 != 0) * halfSize.Z
+		}
+		n.children[i] = &node{center: center, halfSize: halfSize, depth: n.depth + 1, parent: n}
+	}
+
+	items := n.items
+	n.items = nil
+	bounds := n.bounds(1)
+	for _, it := range items {
+		if !bounds.Contains(it.position) {
+			n.items = append(n.items, it)
+			continue
+		}
+
+		child := n.children[n.childIndex(it.position)]
+		child.items = append(child.items, it)
+		child.count++
+		tree.nodes[it.entityId] = child
+	}
+}
+
+func sign(positive bool) float64 {
+	if positive {
+		return 1
+	}
+
+	return -1
+}
+
+func (tree *Tree) Remove(entityId volt.EntityId) {
+	n, ok := tree.nodes[entityId]
+	if !ok {
+		return
+	}
+
+	n.items = removeItem(n.items, entityId)
+	delete(tree.nodes, entityId)
+
+	// The highest ancestor holding few enough entities gathers those of its descendants.
+	var merged *node
+	for ancestor := n; ancestor != nil; ancestor = ancestor.parent {
+		ancestor.count--
+		if ancestor.children != nil && ancestor.count <= TREE_NODE_CAPACITY/2 {
+			merged = ancestor
+		}
+	}
+	if merged != nil {
+		tree.merge(merged)
+	}
+}
+
+// merge moves the items of the descendants of n into n, and removes them.
+func (tree *Tree) merge(n *node) {
+	var gather func(child *node)
+	gather = func(child *node) {
+		for _, it := range child.items {
+			n.items = append(n.items, it)
+			tree.nodes[it.entityId] = n
+		}
+		for _, grandChild := range child.children {
+			gather(grandChild)
+		}
+	}
+
+	for _, child := range n.children {
+		gather(child)
+	}
+	n.children = nil
+}
+
+func (tree *Tree) Query(box Box, fn func(entityId volt.EntityId, position Vec3) bool) {
+	tree.query(tree.root, box, fn)
+}
+
+// query visits the nodes whose loose bounds intersect the box, and returns false once fn does.
+func (tree *Tree) query(n *node, box Box, fn func(entityId volt.EntityId, position Vec3) bool) bool {
+	if n.count == 0 || (n != tree.root && !n.looseBounds().intersects(box)) {
+		return true
+	}
+
+	if !queryItems(n.items, box, fn) {
+		return false
+	}
+	for _, child := range n.children {
+		if !tree.query(child, box, fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (tree *Tree) Len() int {
+	return len(tree.nodes)
+}
+
+func (tree *Tree) Clear() {
+	tree.root.items = nil
+	tree.root.children = nil
+	tree.root.count = 0
+	clear(tree.nodes)
+}
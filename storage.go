@@ -5,10 +5,9 @@ import (
 )
 
 func getStorage[T ComponentInterface](world *World) *ComponentsStorage[T] {
-	var t T
-	componentId := t.GetComponentId()
+	componentId := ComponentIdOf[T](world)
 
-	if world.componentsRegistry[componentId] == nil {
+	if int(componentId) >= len(world.componentsRegistry) || world.componentsRegistry[componentId] == nil {
 		return nil
 	}
 
@@ -48,18 +47,42 @@ type storage interface {
 	size(archetypeId archetypeId) int
 	moveLastToKey(archetypeId archetypeId, recordKey int)
 	delete(archetypeId archetypeId, key int)
+
+	isSparse() bool
+	isShared() bool
+	linkGroup(archetypeId archetypeId, value int)
+	freeGroup(value int)
+	hasEntity(entityId EntityId) bool
+	getEntity(entityId EntityId) any
+	removeEntity(entityId EntityId)
+
+	compact(remap []int, stats *CompactStats)
+	snapshot(state any) any
+	restore(state any)
+	clone(config ComponentConfigInterface) storage
+	reset()
+	permute(archetypeId archetypeId, permutation []int, visited []bool)
+	moveRow(archetypeId archetypeId, from int, to int)
 }
 
 // ArchetypesComponentsEntities stores, for each archetype, the column of T
 // components (Structure of Arrays). It is indexed directly by archetypeId:
 // archetypeId is dense (0, 1, 2, ...), so a slice avoids the hashing cost of a
 // map on every storage access, in both read (queries) and write paths. A nil
-// column means the archetype does not hold this component.
+// column means the archetype does not hold this component: a column emptied,
+// e.g. by Clear or Compact, stays non-nil.
 type ArchetypesComponentsEntities[T ComponentInterface] [][]T
 
 type ComponentsStorage[T ComponentInterface] struct {
 	componentId                  ComponentId
 	archetypesComponentsEntities ArchetypesComponentsEntities[T]
+
+	// sparse holds the components instead of the archetypes columns,
+	// for the components registered with SPARSE_STORAGE.
+	sparse *sparseSet[T]
+	// shared holds one value per group of entities instead of the archetypes columns,
+	// for the components registered with SHARED_STORAGE.
+	shared *sharedSet[T]
 }
 
 func (c *ComponentsStorage[T]) getType() ComponentId {
@@ -68,6 +91,16 @@ func (c *ComponentsStorage[T]) getType() ComponentId {
 
 func (c *ComponentsStorage[T]) getArchetypes() []archetypeId {
 	var archetypes []archetypeId
+	if c.shared != nil {
+		for id, value := range c.shared.groups {
+			if value != 0 {
+				archetypes = append(archetypes, archetypeId(id))
+			}
+		}
+
+		return archetypes
+	}
+
 	for id, column := range c.archetypesComponentsEntities {
 		if column != nil {
 			archetypes = append(archetypes, archetypeId(id))
@@ -78,6 +111,10 @@ func (c *ComponentsStorage[T]) getArchetypes() []archetypeId {
 }
 
 func (c *ComponentsStorage[T]) hasArchetype(archetypeId archetypeId) bool {
+	if c.shared != nil {
+		return c.shared.get(archetypeId) != nil
+	}
+
 	return int(archetypeId) < len(c.archetypesComponentsEntities) && c.archetypesComponentsEntities[archetypeId] != nil
 }
 
@@ -119,6 +156,16 @@ func (c *ComponentsStorage[T]) add(archetypeId archetypeId, component ComponentI
 	return c.addTyped(archetypeId, component.(T))
 }
 
+// insert adds the component of entityId, either to the archetype column or to the sparse set.
+func (c *ComponentsStorage[T]) insert(archetypeId archetypeId, entityId EntityId, component T) {
+	if c.sparse != nil {
+		c.sparse.set(entityId, component)
+		return
+	}
+
+	c.addTyped(archetypeId, component)
+}
+
 // addTyped appends a component without boxing it into ComponentInterface.
 // The generic add/copy paths hold a concrete *ComponentsStorage[T], so they can
 // call this directly and avoid one heap allocation per component added.
@@ -129,7 +176,13 @@ func (c *ComponentsStorage[T]) addTyped(archetypeId archetypeId, component T) in
 	return len(c.archetypesComponentsEntities[archetypeId]) - 1
 }
 
+// copy appends the component at recordKey to the column of archetypeId.
+// A shared component has no row to copy: the group of archetypeId holds the value.
 func (c *ComponentsStorage[T]) copy(oldArchetypeId archetypeId, archetypeId archetypeId, recordKey int) int {
+	if c.shared != nil {
+		return recordKey
+	}
+
 	return c.addTyped(archetypeId, c.archetypesComponentsEntities[oldArchetypeId][recordKey])
 }
 
@@ -138,10 +191,18 @@ func (c *ComponentsStorage[T]) set(archetypeId archetypeId, key int, component C
 }
 
 func (c *ComponentsStorage[T]) get(archetypeId archetypeId, key int) any {
+	if c.shared != nil {
+		return c.shared.get(archetypeId)
+	}
+
 	return &c.archetypesComponentsEntities[archetypeId][key]
 }
 
 func (c *ComponentsStorage[T]) moveLastToKey(archetypeId archetypeId, recordKey int) {
+	if c.shared != nil {
+		return
+	}
+
 	data := c.archetypesComponentsEntities[archetypeId]
 	lastKey := len(data) - 1
 
@@ -155,3 +216,146 @@ func (c *ComponentsStorage[T]) delete(archetypeId archetypeId, key int) {
 		c.archetypesComponentsEntities[archetypeId] = append(data[:key], data[key+1:]...)
 	}
 }
+
+func (c *ComponentsStorage[T]) isSparse() bool {
+	return c.sparse != nil
+}
+
+// getSparse returns the sparse set of the storage, or nil for a table storage.
+func (c *ComponentsStorage[T]) getSparse() *sparseSet[T] {
+	if c == nil {
+		return nil
+	}
+
+	return c.sparse
+}
+
+func (c *ComponentsStorage[T]) hasEntity(entityId EntityId) bool {
+	return c.sparse != nil && c.sparse.has(entityId)
+}
+
+func (c *ComponentsStorage[T]) getEntity(entityId EntityId) any {
+	if c.sparse == nil {
+		return nil
+	}
+
+	component := c.sparse.get(entityId)
+	if component == nil {
+		return nil
+	}
+
+	return component
+}
+
+func (c *ComponentsStorage[T]) removeEntity(entityId EntityId) {
+	if c.sparse != nil {
+		c.sparse.remove(entityId)
+	}
+}
+
+// sparseSet stores components keyed by EntityId, for the components registered with SPARSE_STORAGE.
+//
+// The components are packed in dense, and indices maps an EntityId to its
+// position in dense, shifted by one so that the zero value means absent.
+// The indices are split in pages of ENTITIES_PAGE_SIZE, allocated on the first
+// component of the page: an entity with a high EntityId, e.g. from a reserved range,
+// does not allocate the indices of all the ids below it.
+// Adding or removing a component is O(1) and never moves the entity across archetypes.
+type sparseSet[T any] struct {
+	indices  [][]int
+	dense    []T
+	entities []EntityId
+}
+
+// index returns the position of the component of entityId in dense, shifted by one, or 0.
+func (set *sparseSet[T]) index(entityId EntityId) int {
+	page := int(entityId / ENTITIES_PAGE_SIZE)
+	if page >= len(set.indices) || len(set.indices[page]) == 0 {
+		return 0
+	}
+
+	return set.indices[page][entityId%ENTITIES_PAGE_SIZE]
+}
+
+// setIndex stores the position of the component of entityId, allocating its page if needed.
+func (set *sparseSet[T]) setIndex(entityId EntityId, index int) {
+	page := int(entityId / ENTITIES_PAGE_SIZE)
+	set.indices = resizeBuffers(set.indices, max(len(set.indices), page+1))
+	if len(set.indices[page]) == 0 {
+		// An empty page keeps the buffer of a page emptied by restore.
+		if cap(set.indices[page]) >= ENTITIES_PAGE_SIZE {
+			set.indices[page] = set.indices[page][:ENTITIES_PAGE_SIZE]
+			clear(set.indices[page])
+		} else {
+			set.indices[page] = make([]int, ENTITIES_PAGE_SIZE)
+		}
+	}
+
+	set.indices[page][entityId%ENTITIES_PAGE_SIZE] = index
+}
+
+func (set *sparseSet[T]) has(entityId EntityId) bool {
+	return set.index(entityId) != 0
+}
+
+// get returns a pointer to the component of entityId, or nil.
+func (set *sparseSet[T]) get(entityId EntityId) *T {
+	index := set.index(entityId)
+	if index == 0 {
+		return nil
+	}
+
+	return &set.dense[index-1]
+}
+
+func (set *sparseSet[T]) set(entityId EntityId, component T) {
+	if index := set.index(entityId); index != 0 {
+		set.dense[index-1] = component
+		return
+	}
+
+	set.dense = append(set.dense, component)
+	set.entities = append(set.entities, entityId)
+	set.setIndex(entityId, len(set.dense))
+}
+
+// remove deletes the component of entityId, by moving the last component to its position.
+func (set *sparseSet[T]) remove(entityId EntityId) {
+	index := set.index(entityId)
+	if index == 0 {
+		return
+	}
+
+	key := index - 1
+	lastKey := len(set.dense) - 1
+	lastEntityId := set.entities[lastKey]
+
+	set.dense[key] = set.dense[lastKey]
+	set.entities[key] = lastEntityId
+	set.setIndex(lastEntityId, key+1)
+
+	var zero T
+	set.dense[lastKey] = zero
+	set.dense = set.dense[:lastKey]
+	set.entities = set.entities[:lastKey]
+	set.setIndex(entityId, 0)
+}
+
+// copyIndices copies the pages of indices into buffer, reusing its pages, and returns it.
+// A page absent from indices is left empty, keeping its buffer.
+func copyIndices(buffer [][]int, indices [][]int) [][]int {
+	buffer = resizeBuffers(buffer, max(len(buffer), len(indices)))
+	for i := range buffer {
+		if i < len(indices) {
+			buffer[i] = append(buffer[i][:0], indices[i]...)
+		} else {
+			buffer[i] = buffer[i][:0]
+		}
+	}
+
+	return buffer
+}
+
+func (set *sparseSet[T]) len() int {
+	return len(set.dense)
+}
@@ -34,3 +34,34 @@ func TestWorld_getStorageForComponentId(t *testing.T) {
 		t.Errorf("getStorageForComponentId() returned a storage for the not registered ComponentId %d", testComponent1Id)
 	}
 }
+
+func TestSparseSet(t *testing.T) {
+	set := sparseSet[testComponent1]{}
+
+	for i := range 10 {
+		set.set(EntityId(i*2), testComponent1{testComponent{x: i}})
+	}
+	if set.len() != 10 || set.has(1) || set.get(1) != nil || set.get(100) != nil {
+		t.Errorf("unexpected content of the sparse set")
+	}
+
+	set.set(4, testComponent1{testComponent{x: 42}})
+	if set.len() != 10 || set.get(4).x != 42 {
+		t.Errorf("set should overwrite the component already stored")
+	}
+
+	// Remove a component in the middle, so that the last one is moved.
+	set.remove(6)
+	set.remove(6)
+	if set.len() != 9 || set.has(6) {
+		t.Errorf("the component should be removed")
+	}
+	for i := range 10 {
+		if i == 3 || i == 2 {
+			continue
+		}
+		if component := set.get(EntityId(i * 2)); component == nil || component.x != i {
+			t.Errorf("the entity %d has lost its component", i*2)
+		}
+	}
+}
@@ -12,11 +12,15 @@ type TagId = ComponentId
 // AddTag adds a TagId to a given EntityId.
 // This function returns an error if:
 // - The id is lower than the valid range (< TAGS_INDICES)
+// - The id is in the range of the shared components groups (>= SHARED_INDICES)
 // - The Tag is already owned
 func (world *World) AddTag(tagId TagId, entityId EntityId) error {
 	if tagId < TAGS_INDICES {
 		return fmt.Errorf("the tagId %d is not allowed, it collides with Components Ids range [%d-%d]", tagId, COMPONENTS_INDICES, TAGS_INDICES)
 	}
+	if tagId >= SHARED_INDICES {
+		return fmt.Errorf("the tagId %d is not allowed, it collides with the shared components groups range [%d-%d]", tagId, SHARED_INDICES, AUTO_COMPONENT_ID)
+	}
 
 	if !world.Exists(entityId) {
 		return fmt.Errorf("the entity %d does not exist", entityId)
@@ -26,7 +30,7 @@ func (world *World) AddTag(tagId TagId, entityId EntityId) error {
 		return fmt.Errorf("the entity %d already owns the tag %d", entityId, tagId)
 	}
 
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 	archetype := world.getNextArchetype(entityRecord, tagId)
 
 	oldArchetype := world.getArchetype(entityRecord)
@@ -43,20 +47,24 @@ func (world *World) HasTag(tagId TagId, entityId EntityId) bool {
 	if !world.Exists(entityId) {
 		return false
 	}
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 
 	return world.hasComponents(entityRecord, tagId)
 }
 
 // RemoveTags removes a Tag for a given EntityId.
 // It returns an error if:
+// - The id is in the range of the shared components groups (>= SHARED_INDICES)
 // - The entity does not exists.
 // - The entity already owns the Tag.
 func (world *World) RemoveTag(tagId TagId, entityId EntityId) error {
+	if tagId >= SHARED_INDICES {
+		return fmt.Errorf("the tagId %d is not allowed, it collides with the shared components groups range [%d-%d]", tagId, SHARED_INDICES, AUTO_COMPONENT_ID)
+	}
 	if !world.Exists(entityId) {
 		return fmt.Errorf("the entity %d does not exist", entityId)
 	}
-	entityRecord := world.entities[entityId]
+	entityRecord := world.entities.get(entityId)
 
 	if !world.HasTag(tagId, entityId) {
 		return fmt.Errorf("the entity %d doesn't own the tag %d", entityId, tagId)
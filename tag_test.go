@@ -61,6 +61,24 @@ func TestAddTag(t *testing.T) {
 	if err == nil {
 		t.Errorf("AddTag should return an error due to the TagId %d being lower than the range allowed", TAGS_INDICES-1)
 	}
+
+	// The ids from SHARED_INDICES are the groups of the shared components.
+	err = world.AddTag(SHARED_INDICES-1, entities[0])
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	err = world.RemoveTag(SHARED_INDICES-1, entities[0])
+	if err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	err = world.AddTag(SHARED_INDICES, entities[0])
+	if err == nil {
+		t.Errorf("AddTag should return an error due to the TagId %d being higher than the range allowed", SHARED_INDICES)
+	}
+	err = world.RemoveTag(SHARED_INDICES, entities[0])
+	if err == nil {
+		t.Errorf("RemoveTag should return an error due to the TagId %d being higher than the range allowed", SHARED_INDICES)
+	}
 }
 
 func TestHasTag(t *testing.T) {
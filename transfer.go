@@ -0,0 +1,209 @@
+package volt
+
+import (
+	"fmt"
+)
+
+// CopyEntity creates in the World dst a copy of the entity entityId of the World src, with all its components and tags,
+// and returns its EntityId in dst.
+//
+// The components are matched by their Go type, or by their name for the dynamic components: their ComponentId may
+// differ between the two Worlds. They are copied with the CloneFn of their ComponentConfig in dst, if set.
+// The callbacks of dst are called for each component added, then the entity is published (see PublishEntity),
+// once all the components are added: a copy which fails leaves dst unchanged, and calls none of them.
+// It returns an error if:
+//   - the entity does not exist in src
+//   - a component of the entity is not registered in dst
+//   - a component cannot be added in dst, e.g. with the key of another entity in a unique Index
+func CopyEntity(src *World, dst *World, entityId EntityId) (EntityId, error) {
+	return transferEntity(src, dst, entityId, make(map[ComponentId]ComponentId), true)
+}
+
+// MoveEntity moves the entity entityId of the World src to the World dst, with all its components and tags,
+// and returns its new EntityId in dst.
+//
+// The entity is copied as with CopyEntity, then removed from src, calling the callbacks of src set in
+// SetComponentRemovedFn for each component, and in SetEntityRemovedFn.
+// It returns an error if:
+//   - src and dst are the same World
+//   - the entity does not exist in src
+//   - a component of the entity is not registered in dst
+//   - a component cannot be added in dst, e.g. with the key of another entity in a unique Index
+func MoveEntity(src *World, dst *World, entityId EntityId) (EntityId, error) {
+	if src == dst {
+		return 0, fmt.Errorf("the entity %d cannot be moved to its own world", entityId)
+	}
+
+	newEntityId, err := transferEntity(src, dst, entityId, make(map[ComponentId]ComponentId), false)
+	if err != nil {
+		return 0, err
+	}
+	removeTransferredEntity(src, entityId)
+
+	return newEntityId, nil
+}
+
+// CopyEntities copies the entities entitiesIds of the World src to the World dst, see CopyEntity.
+//
+// It returns the EntityId in dst, in the order of entitiesIds. On error, the entities copied so far are kept.
+func CopyEntities(src *World, dst *World, entitiesIds []EntityId) ([]EntityId, error) {
+	componentsIds := make(map[ComponentId]ComponentId)
+	newEntitiesIds := make([]EntityId, 0, len(entitiesIds))
+	for _, entityId := range entitiesIds {
+		newEntityId, err := transferEntity(src, dst, entityId, componentsIds, true)
+		if err != nil {
+			return newEntitiesIds, err
+		}
+		newEntitiesIds = append(newEntitiesIds, newEntityId)
+	}
+
+	return newEntitiesIds, nil
+}
+
+// MoveEntities moves the entities entitiesIds of the World src to the World dst, see MoveEntity.
+//
+// It returns the new EntityId in dst, in the order of entitiesIds. On error, the entities moved so far are kept in dst.
+func MoveEntities(src *World, dst *World, entitiesIds []EntityId) ([]EntityId, error) {
+	if src == dst {
+		return nil, fmt.Errorf("the entities cannot be moved to their own world")
+	}
+
+	componentsIds := make(map[ComponentId]ComponentId)
+	newEntitiesIds := make([]EntityId, 0, len(entitiesIds))
+	for _, entityId := range entitiesIds {
+		newEntityId, err := transferEntity(src, dst, entityId, componentsIds, false)
+		if err != nil {
+			return newEntitiesIds, err
+		}
+		removeTransferredEntity(src, entityId)
+		newEntitiesIds = append(newEntitiesIds, newEntityId)
+	}
+
+	return newEntitiesIds, nil
+}
+
+// transferEntity creates in dst an entity with the components and tags of entityId in src.
+//
+// componentsIds caches the ComponentId of dst for each ComponentId of src.
+// If clone is true, the components are copied with the CloneFn of their configuration.
+// The callbacks of dst are called once the entity is complete: a transfer which fails calls none of them.
+func transferEntity(src *World, dst *World, entityId EntityId, componentsIds map[ComponentId]ComponentId, clone bool) (EntityId, error) {
+	if !src.Exists(entityId) {
+		return 0, fmt.Errorf("entity %v does not exist", entityId)
+	}
+	srcIds, tagsIds := transferredComponents(src, entityId)
+
+	// All the components are resolved in dst, before the entity is created.
+	for _, componentId := range srcIds {
+		if err := src.resolveTransferredComponent(dst, componentId, componentsIds); err != nil {
+			return 0, err
+		}
+	}
+
+	// The callbacks of dst are deferred until all the components and tags are added.
+	var addedIds []ComponentId
+	componentAddedFn, entityRemovedFn := dst.componentAddedFn, dst.entityRemovedFn
+	dst.componentAddedFn = func(entityId EntityId, componentId ComponentId) {
+		addedIds = append(addedIds, componentId)
+	}
+
+	newEntityId := dst.CreateEntity()
+	err := addTransferredComponents(src, dst, entityId, newEntityId, srcIds, tagsIds, componentsIds, clone)
+	if err != nil {
+		dst.entityRemovedFn = func(entityId EntityId) {}
+		dst.RemoveEntity(newEntityId)
+	}
+	dst.componentAddedFn, dst.entityRemovedFn = componentAddedFn, entityRemovedFn
+	if err != nil {
+		return 0, err
+	}
+
+	for _, componentId := range addedIds {
+		dst.componentAddedFn(newEntityId, componentId)
+	}
+	dst.PublishEntity(newEntityId)
+
+	return newEntityId, nil
+}
+
+// transferredComponents returns the components and the tags of the entity, to transfer.
+// The groups of the shared components are not returned: they are set in dst along with their value.
+func transferredComponents(src *World, entityId EntityId) ([]ComponentId, []TagId) {
+	var srcIds []ComponentId
+	var tagsIds []TagId
+	for _, componentId := range src.archetypes[src.entities.get(entityId).archetypeId].Type {
+		if componentId >= SHARED_INDICES {
+			continue
+		} else if componentId >= TAGS_INDICES {
+			tagsIds = append(tagsIds, componentId)
+		} else {
+			srcIds = append(srcIds, componentId)
+		}
+	}
+	for _, componentId := range src.sparseComponentsIds {
+		if src.storage[componentId].hasEntity(entityId) {
+			srcIds = append(srcIds, componentId)
+		}
+	}
+
+	return srcIds, tagsIds
+}
+
+// addTransferredComponents adds to newEntityId in dst the components srcIds and the tags of entityId in src.
+func addTransferredComponents(src *World, dst *World, entityId EntityId, newEntityId EntityId, srcIds []ComponentId, tagsIds []TagId, componentsIds map[ComponentId]ComponentId, clone bool) error {
+	for _, componentId := range srcIds {
+		component, err := src.GetComponent(entityId, componentId)
+		if err == nil {
+			err = dst.componentsRegistry[componentsIds[componentId]].addComponentValue(dst, newEntityId, component, clone)
+		}
+		if err != nil {
+			return fmt.Errorf("the component %s of the entity %d cannot be transferred: %w", src.componentName(componentId), entityId, err)
+		}
+	}
+	for _, tagId := range tagsIds {
+		if err := dst.AddTag(tagId, newEntityId); err != nil {
+			return fmt.Errorf("the tag %d of the entity %d cannot be transferred: %w", tagId, entityId, err)
+		}
+	}
+
+	return nil
+}
+
+// removeTransferredEntity removes the entity moved from src, calling the callbacks of src for each component and for the entity.
+func removeTransferredEntity(src *World, entityId EntityId) {
+	srcIds, _ := transferredComponents(src, entityId)
+	for _, componentId := range srcIds {
+		src.componentRemovedFn(entityId, componentId)
+	}
+	src.RemoveEntity(entityId)
+}
+
+// translateComponentId returns the ComponentId in dst of the component componentId of src.
+//
+// The components are matched by their Go type, or by their name and size for the dynamic components.
+func translateComponentId(src *World, dst *World, componentId ComponentId) (ComponentId, error) {
+	config, err := src.getConfigByComponentId(componentId)
+	if err != nil {
+		return 0, err
+	}
+	info := config.getInfo()
+
+	if componentType := config.getType(); componentType != nil {
+		dstId, ok := dst.registeredTypes[componentType]
+		if !ok {
+			return 0, fmt.Errorf("the component %s is not registered in the destination world", src.componentName(componentId))
+		}
+
+		return dstId, nil
+	}
+
+	dstId, ok := dst.componentsByName[info.Name]
+	if !ok {
+		return 0, fmt.Errorf("the component %s is not registered in the destination world", src.componentName(componentId))
+	}
+	if dstConfig := dst.componentsRegistry[dstId]; dstConfig.getType() != nil || dstConfig.getInfo().Size != info.Size {
+		return 0, fmt.Errorf("the component %s does not match the component %s of the destination world", src.componentName(componentId), dst.componentName(dstId))
+	}
+
+	return dstId, nil
+}
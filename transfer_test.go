@@ -0,0 +1,187 @@
+package volt
+
+import (
+	"slices"
+	"testing"
+)
+
+func createTransferEntity(src *World, statsId ComponentId, value int) EntityId {
+	entityId := src.CreateEntity()
+	AddComponent(src, entityId, testAutoComponent1{value: value})
+	AddComponent(src, entityId, testSparseComponent{testComponent{x: value}})
+	AddComponent(src, entityId, testInventoryComponent{items: []string{"sword"}})
+	src.AddDynamicComponent(entityId, statsId, nil)
+	SetDynamicField[float32](src, entityId, statsId, "health", float32(value))
+	src.AddTag(TAG_1, entityId)
+
+	return entityId
+}
+
+func assertTransferred(t *testing.T, dst *World, entityId EntityId, value int) {
+	t.Helper()
+
+	if component := GetComponent[testAutoComponent1](dst, entityId); component == nil || component.value != value {
+		t.Errorf("the component testAutoComponent1 should be transferred, got %v", component)
+	}
+	if component := GetComponent[testSparseComponent](dst, entityId); component == nil || component.x != value {
+		t.Errorf("the component testSparseComponent should be transferred, got %v", component)
+	}
+	if component := GetComponent[testInventoryComponent](dst, entityId); component == nil || !slices.Equal(component.items, []string{"sword"}) {
+		t.Errorf("the component testInventoryComponent should be transferred, got %v", component)
+	}
+	statsId, _ := dst.ComponentIdByName("stats")
+	if health, err := GetDynamicField[float32](dst, entityId, statsId, "health"); err != nil || health != float32(value) {
+		t.Errorf("the dynamic component should be transferred, got %v %v", health, err)
+	}
+	if !dst.HasTag(TAG_1, entityId) {
+		t.Errorf("the tag should be transferred")
+	}
+}
+
+func TestMoveEntity(t *testing.T) {
+	src := CreateWorld(16)
+	statsId := registerTestComponents(t, src)
+	RegisterComponent[testAutoComponent1](src, &ComponentConfig[testAutoComponent1]{})
+	RegisterComponent[testInventoryComponent](src, &ComponentConfig[testInventoryComponent]{})
+	// The components are registered in another order, so that their ComponentId differ.
+	dst := CreateWorld(16)
+	RegisterComponent[testInventoryComponent](dst, &ComponentConfig[testInventoryComponent]{})
+	RegisterComponent[testAutoComponent1](dst, &ComponentConfig[testAutoComponent1]{})
+	registerTestComponents(t, dst)
+	dst.CreateEntity()
+	entityId := createTransferEntity(src, statsId, 5)
+
+	var removed []EntityId
+	src.SetEntityRemovedFn(func(entityId EntityId) { removed = append(removed, entityId) })
+	componentsRemoved := 0
+	src.SetComponentRemovedFn(func(entityId EntityId, componentId ComponentId) {
+		if !src.HasComponents(entityId, componentId) {
+			t.Errorf("the component %d should still be owned in the callback", componentId)
+		}
+		componentsRemoved++
+	})
+	var added []EntityId
+	dst.SetEntityAddedFn(func(entityId EntityId) { added = append(added, entityId) })
+	componentsAdded := 0
+	dst.SetComponentAddedFn(func(entityId EntityId, componentId ComponentId) { componentsAdded++ })
+
+	newEntityId, err := MoveEntity(src, dst, entityId)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if newEntityId != 1 || src.Exists(entityId) {
+		t.Errorf("the entity %d should be moved to the entity 1, got %d", entityId, newEntityId)
+	}
+	assertTransferred(t, dst, newEntityId, 5)
+
+	if !slices.Equal(removed, []EntityId{entityId}) || !slices.Equal(added, []EntityId{newEntityId}) || componentsAdded != 4 || componentsRemoved != 4 {
+		t.Errorf("the callbacks should be called once per entity and component, got %v %v %d %d", removed, added, componentsAdded, componentsRemoved)
+	}
+
+	if _, err = MoveEntity(src, dst, entityId); err == nil {
+		t.Errorf("MoveEntity should reject an entity which does not exist")
+	}
+	if _, err = MoveEntity(dst, dst, newEntityId); err == nil {
+		t.Errorf("MoveEntity should reject a move to the same world")
+	}
+
+	// A component not registered in the destination rejects the move, without change.
+	entityId = createTransferEntity(src, statsId, 6)
+	RegisterComponent[testComponent3](src, &ComponentConfig[testComponent3]{})
+	AddComponent(src, entityId, testComponent3{})
+	count := dst.Count()
+	if _, err = MoveEntity(src, dst, entityId); err == nil {
+		t.Errorf("MoveEntity should reject a component not registered in the destination")
+	}
+	if !src.Exists(entityId) || dst.Count() != count {
+		t.Errorf("a move rejected should not change the worlds")
+	}
+
+	// A component rejected once the entity created in dst calls none of the callbacks of dst.
+	if _, err = CreateUniqueIndex(dst, func(component testAutoComponent1) int { return component.value }); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	entityId = createTransferEntity(src, statsId, 5)
+	added, componentsAdded = nil, 0
+	dstRemoved := 0
+	dst.SetEntityRemovedFn(func(entityId EntityId) { dstRemoved++ })
+	if _, err = MoveEntity(src, dst, entityId); err == nil {
+		t.Errorf("MoveEntity should reject a component with the key of another entity in a unique Index")
+	}
+	if !src.Exists(entityId) || dst.Count() != count || len(added) > 0 || componentsAdded > 0 || dstRemoved > 0 {
+		t.Errorf("a move rejected should call no callback of the destination, got %v %d %d", added, componentsAdded, dstRemoved)
+	}
+}
+
+func TestCopyEntity(t *testing.T) {
+	src := CreateWorld(16)
+	statsId := registerTestComponents(t, src)
+	RegisterComponent[testAutoComponent1](src, &ComponentConfig[testAutoComponent1]{})
+	RegisterComponent[testInventoryComponent](src, &ComponentConfig[testInventoryComponent]{})
+	// The components are registered in another order, so that their ComponentId differ.
+	dst := CreateWorld(16)
+	RegisterComponent[testInventoryComponent](dst, &ComponentConfig[testInventoryComponent]{
+		CloneFn: func(component testInventoryComponent) testInventoryComponent {
+			component.items = slices.Clone(component.items)
+			return component
+		},
+	})
+	RegisterComponent[testAutoComponent1](dst, &ComponentConfig[testAutoComponent1]{})
+	registerTestComponents(t, dst)
+	entityId := createTransferEntity(src, statsId, 7)
+
+	newEntityId, err := CopyEntity(src, dst, entityId)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	assertTransferred(t, dst, newEntityId, 7)
+	assertTransferred(t, src, entityId, 7)
+
+	// The CloneFn of the destination copies the slice.
+	GetComponent[testInventoryComponent](dst, newEntityId).items[0] = "shield"
+	if items := GetComponent[testInventoryComponent](src, entityId).items; items[0] != "sword" {
+		t.Errorf("the copy should not share the slice of the component, got %v", items)
+	}
+
+	// An entity can be copied within its own world.
+	copyId, err := CopyEntity(src, src, entityId)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	assertTransferred(t, src, copyId, 7)
+}
+
+func TestMoveEntities(t *testing.T) {
+	src := CreateWorld(16)
+	statsId := registerTestComponents(t, src)
+	RegisterComponent[testAutoComponent1](src, &ComponentConfig[testAutoComponent1]{})
+	RegisterComponent[testInventoryComponent](src, &ComponentConfig[testInventoryComponent]{})
+	// The components are registered in another order, so that their ComponentId differ.
+	dst := CreateWorld(16)
+	RegisterComponent[testInventoryComponent](dst, &ComponentConfig[testInventoryComponent]{})
+	RegisterComponent[testAutoComponent1](dst, &ComponentConfig[testAutoComponent1]{})
+	registerTestComponents(t, dst)
+	var entitiesIds []EntityId
+	for i := range 10 {
+		entitiesIds = append(entitiesIds, createTransferEntity(src, statsId, i))
+	}
+
+	copiesIds, err := CopyEntities(src, dst, entitiesIds[:5])
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	newEntitiesIds, err := MoveEntities(src, dst, entitiesIds)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	if len(copiesIds) != 5 || len(newEntitiesIds) != 10 || src.Count() != 0 || dst.Count() != 15 {
+		t.Errorf("expected 15 entities transferred, got %d in the source and %d in the destination", src.Count(), dst.Count())
+	}
+	for i, entityId := range newEntitiesIds {
+		assertTransferred(t, dst, entityId, i)
+	}
+	for i, entityId := range copiesIds {
+		assertTransferred(t, dst, entityId, i)
+	}
+}
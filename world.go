@@ -1,6 +1,15 @@
 // Package volt is an ECS for game development, based on the Archetype paradigm.
 package volt
 
+import (
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+)
+
+//go:generate go run ./cmd/voltgen
+
 // uint16 identifier, for small scoped data.
 type smallId uint16
 
@@ -21,14 +30,15 @@ type componentsIds []ComponentId
 
 // Implementation of an archetype with its identifier, componentsIds, and entitiesIds
 type archetype struct {
-	Id       archetypeId
-	Type     componentsIds
-	entities []EntityId
+	Id        archetypeId
+	Type      componentsIds
+	signature signature
+	entities  []EntityId
 
 	// Archetype graph: cached transitions to neighbour archetypes.
 	// addEdges[c] is the archetype reached by adding component c to this one;
-	// removeEdges[c] the one reached by removing c. Archetypes are never
-	// destroyed, so these edges never go stale. They turn the per-operation
+	// removeEdges[c] the one reached by removing c. Archetypes are only
+	// destroyed by World.Compact, which remaps these edges. They turn the per-operation
 	// archetype lookup from a linear scan into an O(1) hop after the first time.
 	addEdges    map[ComponentId]archetypeId
 	removeEdges map[ComponentId]archetypeId
@@ -41,16 +51,145 @@ type entityRecord struct {
 	key         int
 }
 
-type entities []entityRecord
+// ENTITIES_PAGE_SIZE is the number of entityRecord allocated at once by the World.
+const ENTITIES_PAGE_SIZE = 4096
+
+// entities stores the entityRecord indexed by their EntityId, in pages allocated when a first id of the page is created:
+// the ids far apart, e.g. within the ranges reserved with ReserveEntityRange, do not allocate the records in between.
+// The records of the ids not created are tombstoned, with a negative key.
+type entities struct {
+	pages [][]entityRecord
+	// length is one past the highest EntityId stored.
+	length int
+}
+
+func (e *entities) len() int {
+	return e.length
+}
+
+// has reports whether the record of entityId is allocated and not tombstoned.
+func (e *entities) has(entityId EntityId) bool {
+	page := entityId / ENTITIES_PAGE_SIZE
+	return page < EntityId(len(e.pages)) && e.pages[page] != nil && e.pages[page][entityId%ENTITIES_PAGE_SIZE].key >= 0
+}
+
+// get returns the record of entityId, which must exist.
+func (e *entities) get(entityId EntityId) entityRecord {
+	return e.pages[entityId/ENTITIES_PAGE_SIZE][entityId%ENTITIES_PAGE_SIZE]
+}
+
+// at returns a pointer to the record of entityId, which must exist.
+func (e *entities) at(entityId EntityId) *entityRecord {
+	return &e.pages[entityId/ENTITIES_PAGE_SIZE][entityId%ENTITIES_PAGE_SIZE]
+}
+
+// set stores the record at its EntityId, allocating its page if needed.
+func (e *entities) set(record entityRecord) {
+	page := int(record.Id / ENTITIES_PAGE_SIZE)
+	for len(e.pages) <= page {
+		e.pages = append(e.pages, nil)
+	}
+	if e.pages[page] == nil {
+		e.pages[page] = newEntitiesPage(EntityId(page) * ENTITIES_PAGE_SIZE)
+	}
+
+	e.pages[page][record.Id%ENTITIES_PAGE_SIZE] = record
+	e.length = max(e.length, int(record.Id)+1)
+}
+
+// all returns an iterator of the records allocated, tombstoned or not, in ascending order of EntityId.
+func (e *entities) all() iter.Seq[*entityRecord] {
+	return func(yield func(*entityRecord) bool) {
+		for _, page := range e.pages {
+			for i := range page {
+				if !yield(&page[i]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// reset tombstones all the records, keeping their pages.
+func (e *entities) reset() {
+	for _, page := range e.pages {
+		for i := range page {
+			page[i].key = -1
+		}
+	}
+	e.length = 0
+}
+
+// copyFrom copies the records of other, reusing the pages already allocated.
+func (e *entities) copyFrom(other *entities) {
+	e.pages = resizeBuffers(e.pages, len(other.pages))
+	for i, page := range other.pages {
+		if page == nil {
+			e.pages[i] = nil
+			continue
+		}
+		if e.pages[i] == nil {
+			e.pages[i] = newEntitiesPage(EntityId(i) * ENTITIES_PAGE_SIZE)
+		}
+		copy(e.pages[i], page)
+	}
+	e.length = other.length
+}
+
+func (e *entities) clone() entities {
+	var clone entities
+	clone.copyFrom(e)
+
+	return clone
+}
+
+// newEntitiesPage returns a page of tombstoned records, from the EntityId first.
+func newEntitiesPage(first EntityId) []entityRecord {
+	page := make([]entityRecord, ENTITIES_PAGE_SIZE)
+	for i := range page {
+		page[i] = entityRecord{Id: first + EntityId(i), key: -1}
+	}
+
+	return page
+}
 
 // World representation, container of all the data related to entities and their Components.
 type World struct {
 	componentsRegistry ComponentsRegister
+	registeredTypes    map[reflect.Type]ComponentId
+	componentsByName   map[string]ComponentId
 	pool               pool
 	entities           entities
+	entitiesCount      int
 	archetypes         []archetype
 	storage            []storage
 
+	// archetypesByComponent is the inverted index of the archetypes containing each ComponentId or TagId.
+	archetypesByComponent map[ComponentId][]archetypeId
+
+	// archetypesGeneration is incremented each time Compact renumbers the archetypes.
+	archetypesGeneration int
+
+	// guard reports the accesses overlapping an iteration, with the build tag voltdebug.
+	guard accessGuard
+
+	// snapshots is the ring of the states saved by Snapshot.
+	snapshots snapshotRing
+
+	// indexes are the Index of each component, see CreateIndex.
+	indexes map[ComponentId][]componentIndex
+
+	// sparseComponentsIds lists the components registered with SPARSE_STORAGE.
+	sparseComponentsIds []ComponentId
+
+	// sharedGroups are the values of the shared components, identified by their id from SHARED_INDICES.
+	sharedGroups []sharedGroup
+	// freeSharedGroups are the ids of the groups released by Compact and Clear, reused first.
+	freeSharedGroups []ComponentId
+
+	// deterministic keeps the rows of the archetypes in the order of their EntityId, see SetDeterministic.
+	deterministic bool
+
 	entityAddedFn      func(entityId EntityId)
 	entityRemovedFn    func(entityId EntityId)
 	componentAddedFn   func(entityId EntityId, componentId ComponentId)
@@ -62,14 +201,17 @@ type World struct {
 // It preallocates initialCapacity in memory.
 func CreateWorld(initialCapacity int) *World {
 	world := &World{
-		pool:               pool{},
-		entities:           make(entities, 0, initialCapacity),
-		archetypes:         make([]archetype, 0, 1024),
-		storage:            make([]storage, TAGS_INDICES),
-		entityAddedFn:      func(entityId EntityId) {},
-		entityRemovedFn:    func(entityId EntityId) {},
-		componentAddedFn:   func(entityId EntityId, componentId ComponentId) {},
-		componentRemovedFn: func(entityId EntityId, componentId ComponentId) {},
+		registeredTypes:       make(map[reflect.Type]ComponentId),
+		componentsByName:      make(map[string]ComponentId),
+		pool:                  pool{},
+		entities:              entities{pages: make([][]entityRecord, 0, initialCapacity/ENTITIES_PAGE_SIZE+1)},
+		archetypes:            make([]archetype, 0, 1024),
+		archetypesByComponent: make(map[ComponentId][]archetypeId),
+		storage:               make([]storage, TAGS_INDICES),
+		entityAddedFn:         func(entityId EntityId) {},
+		entityRemovedFn:       func(entityId EntityId) {},
+		componentAddedFn:      func(entityId EntityId, componentId ComponentId) {},
+		componentRemovedFn:    func(entityId EntityId, componentId ComponentId) {},
 	}
 
 	world.createArchetype()
@@ -100,140 +242,109 @@ func (world *World) SetComponentRemovedFn(componentRemovedFn func(entityId Entit
 // CreateEntity creates a new Entity in World;
 // It is linked to no Component.
 func (world *World) CreateEntity() EntityId {
-	entityId := world.pool.Get()
-	archetype := world.getArchetypeForComponentsIds()
-
-	entityRecord := entityRecord{Id: entityId}
-	world.addEntity(entityRecord)
-	world.setArchetype(entityRecord, archetype)
+	entityId := world.newEntityId()
+	world.createEntity(entityId)
 
 	return entityId
 }
 
-func (world *World) addEntity(entityRecord entityRecord) {
-	if int(entityRecord.Id) < len(world.entities) {
-		world.entities[entityRecord.Id] = entityRecord
-	} else {
-		world.entities = append(world.entities, entityRecord)
+// CreateEntityWithId creates a new Entity in World, with the given EntityId;
+// It is linked to no Component.
+//
+// It is meant for the ids assigned by a remote authority, usually within a range
+// reserved with ReserveEntityRange so that CreateEntity never hands them out.
+// The entities are stored by pages of ENTITIES_PAGE_SIZE ids, allocated on the first id created within them:
+// the ids should be grouped, rather than spread across the range.
+// It returns an error if the entity already exists.
+func (world *World) CreateEntityWithId(entityId EntityId) error {
+	if world.Exists(entityId) {
+		return fmt.Errorf("the entity %d already exists", entityId)
 	}
-}
 
-// CreateEntityWithComponents2 creates an entity in World;
-// It sets the components A, B to the entity, for faster performances than the atomic version.
-func CreateEntityWithComponents2[A, B ComponentInterface](world *World, a A, b B) (EntityId, error) {
-	entityId := world.pool.Get()
-
-	entityRecord := entityRecord{Id: entityId}
-	world.addEntity(entityRecord)
+	world.createEntity(entityId)
 
-	err := addComponents2(world, entityRecord, a, b)
-	if err != nil {
-		return 0, err
-	}
-
-	return entityId, nil
+	return nil
 }
 
-// CreateEntityWithComponents3 creates an entity in World;
+// ReserveEntityRange reserves the EntityId [first;last], so that CreateEntity never hands them out.
 //
-// It sets the components A, B, C to the entity, for faster performances than the atomic version.
-func CreateEntityWithComponents3[A, B, C ComponentInterface](world *World, a A, b B, c C) (EntityId, error) {
-	entityId := world.pool.Get()
-
-	entityRecord := entityRecord{Id: entityId}
-	world.addEntity(entityRecord)
-
-	err := addComponents3(world, entityRecord, a, b, c)
-	if err != nil {
-		return 0, err
+// The ids of the range can then be created with CreateEntityWithId or CreateEntityInRange.
+// The memory of the World grows with the highest id created: its table of pages holds a slice header
+// for each ENTITIES_PAGE_SIZE ids below it, e.g. about 24 MB for an id near the maximum EntityId,
+// copied by Clone and Snapshot. The ranges should be reserved low, and used from their start.
+// It returns an error if:
+//   - first is greater than last
+//   - the range overlaps a range already reserved
+//   - an entity of the range already exists
+func (world *World) ReserveEntityRange(first EntityId, last EntityId) (*EntityRange, error) {
+	if first > last {
+		return nil, fmt.Errorf("the range [%d;%d] is empty", first, last)
 	}
 
-	return entityId, nil
-}
-
-// CreateEntityWithComponents4 creates an entity in World;
-//
-// It sets the components A, B, C, D to the entity, for faster performances than the atomic version.
-func CreateEntityWithComponents4[A, B, C, D ComponentInterface](world *World, a A, b B, c C, d D) (EntityId, error) {
-	entityId := world.pool.Get()
-
-	entityRecord := entityRecord{Id: entityId}
-	world.addEntity(entityRecord)
-
-	err := addComponents4(world, entityRecord, a, b, c, d)
-	if err != nil {
-		return 0, err
+	for _, reserved := range world.pool.ranges {
+		if first <= reserved.Last && last >= reserved.First {
+			return nil, fmt.Errorf("the range [%d;%d] overlaps the range [%d;%d]", first, last, reserved.First, reserved.Last)
+		}
 	}
 
-	return entityId, nil
-}
-
-// CreateEntityWithComponents5 creates an entity in World;
-//
-// It sets the components A, B, C, D, E to the entity, for faster performances than the atomic version.
-func CreateEntityWithComponents5[A, B, C, D, E ComponentInterface](world *World, a A, b B, c C, d D, e E) (EntityId, error) {
-	entityId := world.pool.Get()
-
-	entityRecord := entityRecord{Id: entityId}
-	world.addEntity(entityRecord)
-
-	err := addComponents5(world, entityRecord, a, b, c, d, e)
-	if err != nil {
-		return 0, err
+	for entityId := first; int(entityId) < world.entities.len() && entityId <= last; entityId++ {
+		if world.Exists(entityId) {
+			return nil, fmt.Errorf("the entity %d of the range [%d;%d] already exists", entityId, first, last)
+		}
 	}
 
-	return entityId, nil
+	entityRange := &EntityRange{First: first, Last: last}
+	world.pool.reserve(entityRange)
+
+	return entityRange, nil
 }
 
-// CreateEntityWithComponents6 creates an entity in World;
+// CreateEntityInRange creates a new Entity in World, with an EntityId of the range reserved;
+// It is linked to no Component.
 //
-// It sets the components A, B, C, D, E, F to the entity, for faster performances than the atomic version.
-func CreateEntityWithComponents6[A, B, C, D, E, F ComponentInterface](world *World, a A, b B, c C, d D, e E, f F) (EntityId, error) {
-	entityId := world.pool.Get()
+// It returns an error if the range is not reserved in this World, or if all its ids are used.
+func (world *World) CreateEntityInRange(entityRange *EntityRange) (EntityId, error) {
+	if !slices.Contains(world.pool.ranges, entityRange) {
+		return 0, fmt.Errorf("the range [%d;%d] is not reserved in the world", entityRange.First, entityRange.Last)
+	}
 
-	entityRecord := entityRecord{Id: entityId}
-	world.addEntity(entityRecord)
+	for {
+		entityId, ok := entityRange.get()
+		if !ok {
+			return 0, fmt.Errorf("no EntityId left in the range [%d;%d]", entityRange.First, entityRange.Last)
+		}
 
-	err := addComponents6(world, entityRecord, a, b, c, d, e, f)
-	if err != nil {
-		return 0, err
+		// The id may have been created meanwhile with CreateEntityWithId.
+		if !world.Exists(entityId) {
+			world.createEntity(entityId)
+			return entityId, nil
+		}
 	}
-
-	return entityId, nil
 }
 
-// CreateEntityWithComponents7 creates an entity in World;
-//
-// It sets the components A, B, C, D, E, F, G to the entity, for faster performances than the atomic version.
-func CreateEntityWithComponents7[A, B, C, D, E, F, G ComponentInterface](world *World, a A, b B, c C, d D, e E, f F, g G) (EntityId, error) {
-	entityId := world.pool.Get()
+// newEntityId returns a free EntityId from the pool.
+func (world *World) newEntityId() EntityId {
+	for {
+		entityId := world.pool.Get()
 
-	entityRecord := entityRecord{Id: entityId}
-	world.addEntity(entityRecord)
-
-	err := addComponents7(world, entityRecord, a, b, c, d, e, f, g)
-	if err != nil {
-		return 0, err
+		// The id may have been created meanwhile with CreateEntityWithId.
+		if !world.Exists(entityId) {
+			return entityId
+		}
 	}
-
-	return entityId, nil
 }
 
-// CreateEntityWithComponents8 creates an entity in World;
-//
-// It sets the components A, B, C, D, E, F, G, H to the entity, for faster performances than the atomic version.
-func CreateEntityWithComponents8[A, B, C, D, E, F, G, H ComponentInterface](world *World, a A, b B, c C, d D, e E, f F, g G, h H) (EntityId, error) {
-	entityId := world.pool.Get()
+func (world *World) createEntity(entityId EntityId) {
+	archetype := world.getArchetypeForComponentsIds()
 
-	entityRecord := entityRecord{Id: entityId}
+	// The negative key marks the entity as not yet added to an archetype.
+	entityRecord := entityRecord{Id: entityId, key: -1}
 	world.addEntity(entityRecord)
+	world.setArchetype(entityRecord, archetype)
+}
 
-	err := addComponents8(world, entityRecord, a, b, c, d, e, f, g, h)
-	if err != nil {
-		return 0, err
-	}
-
-	return entityId, nil
+func (world *World) addEntity(record entityRecord) {
+	world.entities.set(record)
 }
 
 // PublishEntity calls the callback setted in SetEntityAddedFn.
@@ -251,10 +362,17 @@ func (world *World) RemoveEntity(entityId EntityId) {
 		return
 	}
 
+	world.guardArchetype(entityId, world.entities.get(entityId).archetypeId)
+	for _, componentId := range world.sparseComponentsIds {
+		if world.storage[componentId].hasEntity(entityId) {
+			world.guardSparse(entityId, world.storage[componentId])
+		}
+	}
 	world.entityRemovedFn(entityId)
+	world.unindexEntity(entityId)
 
-	entityRecord := world.entities[entityId]
-	archetype := world.archetypes[entityRecord.archetypeId]
+	entityRecord := world.detachRow(world.entities.get(entityId))
+	archetype := &world.archetypes[entityRecord.archetypeId]
 
 	lastEntityKey := len(archetype.entities) - 1
 	for _, componentId := range archetype.Type {
@@ -270,21 +388,25 @@ func (world *World) RemoveEntity(entityId EntityId) {
 	}
 
 	if lastEntityKey >= 0 {
-		lastEntityId := world.archetypes[archetype.Id].entities[lastEntityKey]
-		lastEntity := world.entities[lastEntityId]
+		lastEntityId := archetype.entities[lastEntityKey]
+		lastEntity := world.entities.get(lastEntityId)
 		if lastEntity.key > entityRecord.key {
 			lastEntity.key = entityRecord.key
-			world.entities[lastEntityId] = lastEntity
+			world.entities.set(lastEntity)
 			archetype.entities[entityRecord.key] = lastEntityId
 		}
 
 		archetype.entities = archetype.entities[:lastEntityKey]
-		world.archetypes[archetype.Id] = archetype
+	}
+
+	for _, componentId := range world.sparseComponentsIds {
+		world.storage[componentId].removeEntity(entityId)
 	}
 
 	// Tombstone the slot: a negative key marks the id as free until it is
 	// recycled, so Has/Get/Exists no longer report stale data for it.
-	world.entities[entityId].key = -1
+	world.entities.at(entityId).key = -1
+	world.entitiesCount--
 	world.pool.Recycle(entityId)
 }
 
@@ -294,10 +416,27 @@ func (world *World) RemoveEntity(entityId EntityId) {
 // and not yet recycled into a new entity. A negative key is the tombstone left
 // behind by RemoveEntity.
 func (world *World) Exists(entityId EntityId) bool {
-	return int(entityId) < len(world.entities) && world.entities[entityId].key >= 0
+	return world.entities.has(entityId)
+}
+
+// Entities returns an iterator of the EntityId of all the entities in World, in ascending order.
+//
+// The World must not be modified during the iteration.
+func (world *World) Entities() iter.Seq[EntityId] {
+	return func(yield func(EntityId) bool) {
+		for entityRecord := range world.entities.all() {
+			if entityRecord.key < 0 {
+				continue
+			}
+
+			if !yield(entityRecord.Id) {
+				return
+			}
+		}
+	}
 }
 
 // Count returns the number of entities in World.
 func (world *World) Count() int {
-	return len(world.entities) - world.pool.Count()
+	return world.entitiesCount
 }
@@ -0,0 +1,143 @@
+// Code generated by voltgen. DO NOT EDIT.
+
+package volt
+
+// CreateEntityWithComponents2 creates an entity in World;
+//
+// It sets the components A, B to the entity, for faster performances than the atomic version.
+// On error, no entity is created and its EntityId is recycled.
+func CreateEntityWithComponents2[A, B ComponentInterface](world *World, a A, b B) (EntityId, error) {
+	entityId := world.newEntityId()
+
+	// The negative key marks the entity as not yet added to an archetype.
+	entityRecord := entityRecord{Id: entityId, key: -1}
+	world.addEntity(entityRecord)
+
+	err := addComponents2(world, entityRecord, a, b)
+	if err != nil {
+		world.pool.Recycle(entityId)
+		return 0, err
+	}
+
+	return entityId, nil
+}
+
+// CreateEntityWithComponents3 creates an entity in World;
+//
+// It sets the components A, B, C to the entity, for faster performances than the atomic version.
+// On error, no entity is created and its EntityId is recycled.
+func CreateEntityWithComponents3[A, B, C ComponentInterface](world *World, a A, b B, c C) (EntityId, error) {
+	entityId := world.newEntityId()
+
+	// The negative key marks the entity as not yet added to an archetype.
+	entityRecord := entityRecord{Id: entityId, key: -1}
+	world.addEntity(entityRecord)
+
+	err := addComponents3(world, entityRecord, a, b, c)
+	if err != nil {
+		world.pool.Recycle(entityId)
+		return 0, err
+	}
+
+	return entityId, nil
+}
+
+// CreateEntityWithComponents4 creates an entity in World;
+//
+// It sets the components A, B, C, D to the entity, for faster performances than the atomic version.
+// On error, no entity is created and its EntityId is recycled.
+func CreateEntityWithComponents4[A, B, C, D ComponentInterface](world *World, a A, b B, c C, d D) (EntityId, error) {
+	entityId := world.newEntityId()
+
+	// The negative key marks the entity as not yet added to an archetype.
+	entityRecord := entityRecord{Id: entityId, key: -1}
+	world.addEntity(entityRecord)
+
+	err := addComponents4(world, entityRecord, a, b, c, d)
+	if err != nil {
+		world.pool.Recycle(entityId)
+		return 0, err
+	}
+
+	return entityId, nil
+}
+
+// CreateEntityWithComponents5 creates an entity in World;
+//
+// It sets the components A, B, C, D, E to the entity, for faster performances than the atomic version.
+// On error, no entity is created and its EntityId is recycled.
+func CreateEntityWithComponents5[A, B, C, D, E ComponentInterface](world *World, a A, b B, c C, d D, e E) (EntityId, error) {
+	entityId := world.newEntityId()
+
+	// The negative key marks the entity as not yet added to an archetype.
+	entityRecord := entityRecord{Id: entityId, key: -1}
+	world.addEntity(entityRecord)
+
+	err := addComponents5(world, entityRecord, a, b, c, d, e)
+	if err != nil {
+		world.pool.Recycle(entityId)
+		return 0, err
+	}
+
+	return entityId, nil
+}
+
+// CreateEntityWithComponents6 creates an entity in World;
+//
+// It sets the components A, B, C, D, E, F to the entity, for faster performances than the atomic version.
+// On error, no entity is created and its EntityId is recycled.
+func CreateEntityWithComponents6[A, B, C, D, E, F ComponentInterface](world *World, a A, b B, c C, d D, e E, f F) (EntityId, error) {
+	entityId := world.newEntityId()
+
+	// The negative key marks the entity as not yet added to an archetype.
+	entityRecord := entityRecord{Id: entityId, key: -1}
+	world.addEntity(entityRecord)
+
+	err := addComponents6(world, entityRecord, a, b, c, d, e, f)
+	if err != nil {
+		world.pool.Recycle(entityId)
+		return 0, err
+	}
+
+	return entityId, nil
+}
+
+// CreateEntityWithComponents7 creates an entity in World;
+//
+// It sets the components A, B, C, D, E, F, G to the entity, for faster performances than the atomic version.
+// On error, no entity is created and its EntityId is recycled.
+func CreateEntityWithComponents7[A, B, C, D, E, F, G ComponentInterface](world *World, a A, b B, c C, d D, e E, f F, g G) (EntityId, error) {
+	entityId := world.newEntityId()
+
+	// The negative key marks the entity as not yet added to an archetype.
+	entityRecord := entityRecord{Id: entityId, key: -1}
+	world.addEntity(entityRecord)
+
+	err := addComponents7(world, entityRecord, a, b, c, d, e, f, g)
+	if err != nil {
+		world.pool.Recycle(entityId)
+		return 0, err
+	}
+
+	return entityId, nil
+}
+
+// CreateEntityWithComponents8 creates an entity in World;
+//
+// It sets the components A, B, C, D, E, F, G, H to the entity, for faster performances than the atomic version.
+// On error, no entity is created and its EntityId is recycled.
+func CreateEntityWithComponents8[A, B, C, D, E, F, G, H ComponentInterface](world *World, a A, b B, c C, d D, e E, f F, g G, h H) (EntityId, error) {
+	entityId := world.newEntityId()
+
+	// The negative key marks the entity as not yet added to an archetype.
+	entityRecord := entityRecord{Id: entityId, key: -1}
+	world.addEntity(entityRecord)
+
+	err := addComponents8(world, entityRecord, a, b, c, d, e, f, g, h)
+	if err != nil {
+		world.pool.Recycle(entityId)
+		return 0, err
+	}
+
+	return entityId, nil
+}
@@ -29,7 +29,7 @@ func TestWorld_CreateEntity(t *testing.T) {
 	}
 
 	// Check if the entities all exist in the world
-	if len(world.entities) != TEST_ENTITY_NUMBER {
+	if world.entities.len() != TEST_ENTITY_NUMBER {
 		t.Errorf("Number of entities created invalid")
 	}
 }
@@ -52,6 +52,30 @@ func TestCreateEntityWithComponents2(t *testing.T) {
 	}
 }
 
+func TestCreateEntityWithComponents_Error(t *testing.T) {
+	world := CreateWorld(1024)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+	RegisterComponent[testNameComponent](world, &ComponentConfig[testNameComponent]{})
+	if _, err := CreateUniqueIndex(world, nameKey); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	player := world.CreateEntity()
+	AddComponent(world, player, testNameComponent{name: "player"})
+
+	if _, err := CreateEntityWithComponents2(world, testComponent1{}, testNameComponent{name: "player"}); err == nil {
+		t.Errorf("CreateEntityWithComponents2 should reject a duplicate key")
+	}
+	if world.Count() != 1 {
+		t.Errorf("a rejected CreateEntityWithComponents2 should not create any entity, got %d entities", world.Count())
+	}
+
+	// The EntityId of the rejected entity is recycled.
+	if entityId := world.CreateEntity(); entityId != player+1 {
+		t.Errorf("CreateEntity should reuse the EntityId %d of the rejected entity, got %d", player+1, entityId)
+	}
+}
+
 func TestCreateEntityWithComponents3(t *testing.T) {
 	world := CreateWorld(1024)
 	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
@@ -328,3 +352,174 @@ func TestEntityLiveness(t *testing.T) {
 		t.Fatal("recycled entity should exist")
 	}
 }
+
+func TestWorld_CreateEntity_leavesEmptyArchetype(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+
+	entityId := world.CreateEntity()
+	AddComponent(world, entityId, testComponent1{})
+	if len(world.archetypes[0].entities) != 0 {
+		t.Errorf("the entity should leave the empty archetype once it owns a component")
+	}
+
+	// testComponent2 is not registered: the entity is not created.
+	if _, err := CreateEntityWithComponents2(world, testComponent1{}, testComponent2{}); err == nil {
+		t.Errorf("CreateEntityWithComponents2 should reject a component not registered")
+	}
+	if world.Count() != 1 {
+		t.Errorf("the entity not created should not be counted, got %d entities", world.Count())
+	}
+}
+
+func TestWorld_CreateEntityWithId(t *testing.T) {
+	world := CreateWorld(16)
+	RegisterComponent[testComponent1](world, &ComponentConfig[testComponent1]{})
+
+	if err := world.CreateEntityWithId(10); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err := world.CreateEntityWithId(10); err == nil {
+		t.Errorf("CreateEntityWithId should reject an entity already live")
+	}
+	if !world.Exists(10) || world.Exists(9) || world.Count() != 1 {
+		t.Errorf("only the entity 10 should exist, got %d entities", world.Count())
+	}
+	if err := AddComponent(world, 10, testComponent1{testComponent{x: 10}}); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+
+	// CreateEntity fills the ids skipped, then skips the id 10.
+	for _, expected := range []EntityId{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 11, 12} {
+		if entityId := world.CreateEntity(); entityId != expected {
+			t.Errorf("CreateEntity returned %d instead of %d", entityId, expected)
+		}
+	}
+	if world.Count() != 13 || GetComponent[testComponent1](world, 10).x != 10 {
+		t.Errorf("the entity 10 should be kept, got %d entities", world.Count())
+	}
+
+	// A recycled id created explicitly is not handed out again.
+	world.RemoveEntity(3)
+	world.RemoveEntity(4)
+	if err := world.CreateEntityWithId(4); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	if entityId := world.CreateEntity(); entityId != 3 {
+		t.Errorf("CreateEntity returned %d instead of the id recycled 3", entityId)
+	}
+	if entityId := world.CreateEntity(); entityId != 13 {
+		t.Errorf("CreateEntity returned %d instead of 13", entityId)
+	}
+	if world.Count() != 14 {
+		t.Errorf("expected 14 entities, got %d", world.Count())
+	}
+}
+
+func TestWorld_ReserveEntityRange(t *testing.T) {
+	world := CreateWorld(16)
+	for range 4 {
+		world.CreateEntity()
+	}
+	world.RemoveEntity(3)
+
+	if _, err := world.ReserveEntityRange(2, 10); err == nil {
+		t.Errorf("ReserveEntityRange should reject a range with a live entity")
+	}
+	if _, err := world.ReserveEntityRange(10, 2); err == nil {
+		t.Errorf("ReserveEntityRange should reject an empty range")
+	}
+	remote, err := world.ReserveEntityRange(3, 5)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if _, err = world.ReserveEntityRange(5, 8); err == nil {
+		t.Errorf("ReserveEntityRange should reject a range overlapping another one")
+	}
+	predicted, err := world.ReserveEntityRange(100, 101)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	// CreateEntity skips the reserved ids, including the recycled id 3.
+	for _, expected := range []EntityId{6, 7} {
+		if entityId := world.CreateEntity(); entityId != expected {
+			t.Errorf("CreateEntity returned %d instead of %d", entityId, expected)
+		}
+	}
+
+	// The remote authority assigns its own ids.
+	if err = world.CreateEntityWithId(4); err != nil {
+		t.Errorf("%s", err.Error())
+	}
+	for _, expected := range []EntityId{3, 5} {
+		if entityId, err := world.CreateEntityInRange(remote); err != nil || entityId != expected {
+			t.Errorf("CreateEntityInRange returned %d, %v instead of %d", entityId, err, expected)
+		}
+	}
+	if _, err = world.CreateEntityInRange(remote); err == nil {
+		t.Errorf("CreateEntityInRange should reject a range exhausted")
+	}
+
+	// A removed id goes back to its range.
+	world.RemoveEntity(4)
+	if entityId := world.CreateEntity(); entityId == 4 {
+		t.Errorf("CreateEntity should not hand out an id of a reserved range")
+	}
+	if entityId, err := world.CreateEntityInRange(remote); err != nil || entityId != 4 {
+		t.Errorf("CreateEntityInRange returned %d, %v instead of 4", entityId, err)
+	}
+
+	if entityId, err := world.CreateEntityInRange(predicted); err != nil || entityId != 100 || !world.Exists(100) || world.Exists(99) {
+		t.Errorf("CreateEntityInRange returned %d, %v instead of 100", entityId, err)
+	}
+	if _, err = CreateWorld(16).CreateEntityInRange(predicted); err == nil {
+		t.Errorf("CreateEntityInRange should reject a range of another world")
+	}
+	if world.Count() != 10 {
+		t.Errorf("expected 10 entities, got %d", world.Count())
+	}
+}
+
+func TestWorld_ReserveEntityRange_Pages(t *testing.T) {
+	world := CreateWorld(16)
+	if _, err := world.ReserveEntityRange(0, 999999); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	predicted, err := world.ReserveEntityRange(1000000, 1009999)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	entityId := world.CreateEntity()
+	if entityId != 1010000 {
+		t.Errorf("CreateEntity returned %d instead of 1010000", entityId)
+	}
+	if _, err = world.CreateEntityInRange(predicted); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if err = world.CreateEntityWithId(42); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+
+	// Only the pages of the ids created are allocated.
+	pages := 0
+	for _, page := range world.entities.pages {
+		if page != nil {
+			pages++
+		}
+	}
+	if pages != 3 || world.Exists(41) || world.Exists(1009999) || !slices.Equal(slices.Collect(world.Entities()), []EntityId{42, 1000000, 1010000}) {
+		t.Errorf("expected 3 pages for the entities [42 1000000 1010000], got %d pages for %v", pages, slices.Collect(world.Entities()))
+	}
+
+	snapshot := world.Snapshot()
+	world.RemoveEntity(42)
+	clone := world.Clone()
+	if err = world.Restore(snapshot); err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	if !world.Exists(42) || clone.Exists(42) || !clone.Exists(1010000) {
+		t.Errorf("the pages should be restored and cloned")
+	}
+}